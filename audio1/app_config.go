@@ -0,0 +1,210 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package audio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	dbus "github.com/godbus/dbus/v5"
+	"github.com/linuxdeepin/go-lib/dbusutil"
+	"github.com/linuxdeepin/go-lib/pulse"
+)
+
+// getSinkInputAppName 返回保存应用音量配置时使用的名称，优先使用修正后的图标名，其次是进程名
+func getSinkInputAppName(sinkInputInfo *pulse.SinkInput, correctedIcon string) string {
+	if correctedIcon != "" {
+		return correctedIcon
+	}
+	if bin := sinkInputInfo.PropList[PropAppProcessBinary]; bin != "" {
+		return bin
+	}
+	return sinkInputInfo.PropList[PropAppName]
+}
+
+func (s *SinkInput) getAppName() string {
+	s.PropsMu.RLock()
+	defer s.PropsMu.RUnlock()
+	return s.appName
+}
+
+// newAppConfig 根据 sink-input 当前的状态创建应用音量配置
+func (s *SinkInput) newAppConfig() *AppConfig {
+	s.PropsMu.RLock()
+	defer s.PropsMu.RUnlock()
+	return &AppConfig{
+		Name:    s.appName,
+		Volume:  s.Volume,
+		Mute:    s.Mute,
+		Balance: s.Balance,
+	}
+}
+
+// updateAppConfig 用户修改 sink-input 的音量后，记住应用的音量配置
+func (s *SinkInput) updateAppConfig(fn func(cfg *AppConfig)) {
+	name := s.getAppName()
+	if name == "" {
+		return
+	}
+	ck := GetConfigKeeper()
+	cfg := ck.GetAppConfig(name)
+	if cfg == nil {
+		cfg = s.newAppConfig()
+	}
+	fn(cfg)
+	ck.SetAppConfig(cfg)
+}
+
+// applyAppConfig 恢复 sink-input 的音量、静音和左右声道平衡
+func (s *SinkInput) applyAppConfig(cfg *AppConfig) {
+	logger.Debugf("apply app config %+v to sink-input #%d", *cfg, s.index)
+	volume := cfg.Volume
+	if volume == 0 {
+		volume = 0.001
+	}
+	s.PropsMu.Lock()
+	cv := s.cVolume.SetAvg(volume)
+	cv = cv.SetBalance(s.channelMap, cfg.Balance)
+	s.cVolume = cv
+	s.PropsMu.Unlock()
+
+	ctx := s.audio.context()
+	ctx.SetSinkInputVolume(s.index, cv)
+	ctx.SetSinkInputMute(s.index, cfg.Mute || cfg.Volume == 0)
+}
+
+// getAppPreferSink 返回应用期望的输出，期望的端口不是声卡当前使用的端口时返回 nil
+func (a *Audio) getAppPreferSink(cfg *AppConfig) *Sink {
+	if cfg.PreferCard == "" || cfg.PreferPort == "" {
+		return nil
+	}
+	for _, sink := range a.sinks {
+		if sink.ActivePort.Name == cfg.PreferPort &&
+			a.getCardNameById(sink.Card) == cfg.PreferCard {
+			return sink
+		}
+	}
+	return nil
+}
+
+// moveSinkInputToAppPreferSink 把 sink-input 移动到应用期望的输出，没有期望的输出时返回 false
+func (a *Audio) moveSinkInputToAppPreferSink(idx uint32) bool {
+	sinkInput, ok := a.sinkInputs[idx]
+	if !ok {
+		return false
+	}
+	name := sinkInput.getAppName()
+	if name == "" {
+		return false
+	}
+	cfg := GetConfigKeeper().GetAppConfig(name)
+	if cfg == nil {
+		return false
+	}
+	sink := a.getAppPreferSink(cfg)
+	if sink == nil {
+		return false
+	}
+	if sinkInput.getPropSinkIndex() != sink.index {
+		logger.Infof("move sink-input %d of %s to prefer sink %s", idx, name, sink.Name)
+		a.context().MoveSinkInputsByName([]uint32{idx}, sink.Name)
+	}
+	return true
+}
+
+// moveSinkInputsToAppPreferSinks 把所有 sink-input 移动到应用期望的输出
+func (a *Audio) moveSinkInputsToAppPreferSinks() {
+	for idx, sinkInput := range a.sinkInputs {
+		if sinkInput.visible {
+			a.moveSinkInputToAppPreferSink(idx)
+		}
+	}
+}
+
+// applyAppConfigToSinkInput 新的 sink-input 出现时，恢复应用的音量配置
+func (a *Audio) applyAppConfigToSinkInput(sinkInput *SinkInput) {
+	name := sinkInput.getAppName()
+	if name == "" {
+		return
+	}
+	cfg := GetConfigKeeper().GetAppConfig(name)
+	if cfg == nil {
+		return
+	}
+	sinkInput.applyAppConfig(cfg)
+}
+
+func checkAppConfig(cfg *AppConfig) error {
+	if cfg.Name == "" {
+		return errors.New("app name is empty")
+	}
+	if !isVolumeValid(cfg.Volume) {
+		return fmt.Errorf("invalid volume value: %v", cfg.Volume)
+	}
+	if cfg.Balance < -1.00 || cfg.Balance > 1.00 {
+		return fmt.Errorf("invalid balance value: %v", cfg.Balance)
+	}
+	if (cfg.PreferCard == "") != (cfg.PreferPort == "") {
+		return errors.New("prefer card and prefer port must be set together")
+	}
+	if (cfg.PreferSourceCard == "") != (cfg.PreferSourcePort == "") {
+		return errors.New("prefer source card and prefer source port must be set together")
+	}
+	return nil
+}
+
+// ListAppConfigs 返回 JSON 格式的应用音量配置列表
+func (a *Audio) ListAppConfigs() (configs string, busErr *dbus.Error) {
+	data, err := json.Marshal(GetConfigKeeper().ListAppConfigs())
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	return string(data), nil
+}
+
+// SetAppConfig 添加或修改应用音量配置，config 为 JSON 格式，修改后立即应用到该应用正在播放的声音
+func (a *Audio) SetAppConfig(config string) *dbus.Error {
+	logger.Info("dbus call SetAppConfig:", config)
+	var cfg AppConfig
+	err := json.Unmarshal([]byte(config), &cfg)
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+	err = checkAppConfig(&cfg)
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+	GetConfigKeeper().SetAppConfig(&cfg)
+
+	var moveToDefault []uint32
+	for idx, sinkInput := range a.sinkInputs {
+		if !sinkInput.visible || sinkInput.getAppName() != cfg.Name {
+			continue
+		}
+		sinkInput.applyAppConfig(&cfg)
+		if !a.moveSinkInputToAppPreferSink(idx) {
+			moveToDefault = append(moveToDefault, idx)
+		}
+	}
+	if len(moveToDefault) > 0 && a.defaultSink != nil {
+		a.moveSinkInputsToSink(moveToDefault)
+	}
+	for _, sourceOutput := range a.sourceOutputs {
+		if sourceOutput.visible && sourceOutput.getAppName() == cfg.Name {
+			a.moveSourceOutputToAppPreferSource(sourceOutput)
+		}
+	}
+	return nil
+}
+
+// ForgetAppConfig 删除应用音量配置
+func (a *Audio) ForgetAppConfig(name string) *dbus.Error {
+	logger.Info("dbus call ForgetAppConfig:", name)
+	if !GetConfigKeeper().RemoveAppConfig(name) {
+		return dbusutil.ToError(fmt.Errorf("app config %q not found", name))
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package audio
+
+import (
+	"testing"
+
+	"github.com/linuxdeepin/go-lib/pulse"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSinkInputAppName(t *testing.T) {
+	info := &pulse.SinkInput{
+		PropList: map[string]string{
+			PropAppProcessBinary: "mpv",
+			PropAppName:          "SMPlayer",
+		},
+	}
+	assert.Equal(t, "smplayer", getSinkInputAppName(info, "smplayer"))
+	assert.Equal(t, "mpv", getSinkInputAppName(info, ""))
+
+	delete(info.PropList, PropAppProcessBinary)
+	assert.Equal(t, "SMPlayer", getSinkInputAppName(info, ""))
+}
+
+func TestCheckAppConfig(t *testing.T) {
+	assert.NoError(t, checkAppConfig(&AppConfig{Name: "vlc", Volume: 0.5}))
+	assert.NoError(t, checkAppConfig(&AppConfig{Name: "vlc", Volume: 0.5, PreferCard: "card0", PreferPort: "port0"}))
+
+	assert.Error(t, checkAppConfig(&AppConfig{Volume: 0.5}))
+	assert.Error(t, checkAppConfig(&AppConfig{Name: "vlc", Volume: -1}))
+	assert.Error(t, checkAppConfig(&AppConfig{Name: "vlc", Volume: 0.5, Balance: 2}))
+	assert.Error(t, checkAppConfig(&AppConfig{Name: "vlc", Volume: 0.5, PreferPort: "port0"}))
+	assert.NoError(t, checkAppConfig(&AppConfig{Name: "obs", Volume: 1, PreferSourceCard: "card0", PreferSourcePort: "port0"}))
+	assert.Error(t, checkAppConfig(&AppConfig{Name: "obs", Volume: 1, PreferSourceCard: "card0"}))
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/linuxdeepin/dde-daemon/common/dconfig"
 	"github.com/linuxdeepin/dde-daemon/common/dsync"
 	notifications "github.com/linuxdeepin/go-dbus-factory/session/org.freedesktop.notifications"
+	ofdbus "github.com/linuxdeepin/go-dbus-factory/system/org.freedesktop.dbus"
 	systemd1 "github.com/linuxdeepin/go-dbus-factory/system/org.freedesktop.systemd1"
 	"github.com/linuxdeepin/go-lib/dbusutil"
 	. "github.com/linuxdeepin/go-lib/gettext"
@@ -97,8 +98,8 @@ const (
 	AudioStateChanging = false
 )
 
-//go:generate dbusutil-gen -type Audio,Sink,SinkInput,Source,Meter -import github.com/godbus/dbus audio.go sink.go sinkinput.go source.go meter.go
-//go:generate dbusutil-gen em -type Audio,Sink,SinkInput,Source,Meter
+//go:generate dbusutil-gen -type Audio,Sink,SinkInput,Source,SourceOutput,Meter -import github.com/godbus/dbus audio.go sink.go sinkinput.go source.go sourceoutput.go meter.go
+//go:generate dbusutil-gen em -type Audio,Sink,SinkInput,Source,SourceOutput,Meter
 
 func objectPathSliceEqual(v1, v2 []dbus.ObjectPath) bool {
 	if len(v1) != len(v2) {
@@ -133,6 +134,8 @@ type Audio struct {
 	// dbusutil-gen: equal=objectPathSliceEqual
 	SinkInputs []dbus.ObjectPath
 	// dbusutil-gen: equal=objectPathSliceEqual
+	SourceOutputs []dbus.ObjectPath
+	// dbusutil-gen: equal=objectPathSliceEqual
 	Sinks []dbus.ObjectPath
 	// dbusutil-gen: equal=objectPathSliceEqual
 	configManagerPath       dbus.ObjectPath
@@ -173,6 +176,7 @@ type Audio struct {
 
 	// 正常输出声音的程序列表
 	sinkInputs        map[uint32]*SinkInput
+	sourceOutputs     map[uint32]*SourceOutput // 正在录音的程序列表
 	defaultSink       *Sink
 	defaultSource     *Source
 	sinks             map[uint32]*Sink
@@ -180,6 +184,7 @@ type Audio struct {
 	defaultSinkName   string
 	defaultSourceName string
 	meters            map[string]*Meter
+	loopbacks         map[uint32]*loopback // source index => loopback
 	mu                sync.Mutex
 	quit              chan struct{}
 
@@ -193,8 +198,14 @@ type Audio struct {
 
 	portLocker sync.Mutex
 
+	// 已加载的均衡器所在的输出设备和预设
+	equalizerMu     sync.Mutex
+	equalizerMaster string
+	equalizerPreset *EqualizerPreset
+
 	syncConfig     *dsync.Config
 	sessionSigLoop *dbusutil.SignalLoop
+	dbusDaemon     ofdbus.DBus
 
 	noRestartPulseAudio bool
 
@@ -231,6 +242,7 @@ func newAudio(service *dbusutil.Service) *Audio {
 	a := &Audio{
 		service:          service,
 		meters:           make(map[string]*Meter),
+		loopbacks:        make(map[uint32]*loopback),
 		MaxUIVolume:      pulse.VolumeUIMax,
 		enableSource:     true,
 		AudioServerState: AudioStateChanged,
@@ -280,6 +292,13 @@ func newAudio(service *dbusutil.Service) *Audio {
 		a.sessionSigLoop, dbusPath, logger)
 	a.sessionSigLoop.Start()
 
+	a.dbusDaemon = ofdbus.NewDBus(service.Conn())
+	a.dbusDaemon.InitSignalExt(a.sessionSigLoop, true)
+	_, err = a.dbusDaemon.ConnectNameOwnerChanged(a.handleDBusNameOwnerChanged)
+	if err != nil {
+		logger.Warning(err)
+	}
+
 	return a
 }
 
@@ -1023,6 +1042,8 @@ func (a *Audio) refresh() {
 	a.refreshSources()
 	logger.Debug("refresh sinkinputs")
 	a.refershSinkInputs()
+	logger.Debug("refresh sourceoutputs")
+	a.refreshSourceOutputs()
 	logger.Debug("refresh default")
 	a.refreshDefaultSinkSource()
 	logger.Debug("refresh bluetooth mode opts")
@@ -1193,6 +1214,8 @@ func (a *Audio) destroyCtxRelated() {
 }
 
 func (a *Audio) destroy() {
+	a.dbusDaemon.RemoveAllHandlers()
+	a.stopLoopbacks("")
 	a.sessionSigLoop.Stop()
 	a.syncConfig.Destroy()
 	a.destroyCtxRelated()
@@ -1538,6 +1561,12 @@ func (a *Audio) moveSinkInputsToSink(inputs []uint32) {
 		}
 		if strings.Contains(s.Name, "Echo-Cancel") || strings.Contains(s.Name, "echo-cancel") {
 			continue
+		} else if a.isEqualizerModule(s.OwnerModule) || a.isLoopbackModule(s.OwnerModule) {
+			// 均衡器串联的和回环的 sink-input 不能移动
+			continue
+		} else if a.moveSinkInputToAppPreferSink(index) {
+			// 应用有期望的输出时不跟随默认输出
+			continue
 		} else if strings.Contains(s.Name, "remap-sink-mono") {
 			vrList = append(vrList, index)
 		} else {
@@ -1612,6 +1641,8 @@ func (a *Audio) resumeSinkConfig(s *Sink) {
 		// 意外原因切换到被禁用的端口上，例如没有可用端口
 		s.setMute(true)
 	}
+
+	a.resumeEqualizer(s)
 }
 
 func (a *Audio) resumeSourceConfig(s *Source, isPhyDev bool) {
@@ -1907,6 +1938,16 @@ func (a *Audio) getMasterNameFromVirtualDevice(device string) string {
 			if len(match) > 1 {
 				return match[1]
 			}
+		} else if module.Name == equalizerModuleName && strings.Contains(module.Argument, "sink_name="+device+" ") {
+			re := regexp.MustCompile(`sink_master=([^\s]+)`)
+			match := re.FindStringSubmatch(module.Argument)
+			if len(match) > 1 {
+				// 旧版本的均衡器按频段依次串联，需要找到最终的物理设备
+				if !isPhysicalDevice(match[1]) {
+					return a.getMasterNameFromVirtualDevice(match[1])
+				}
+				return match[1]
+			}
 		}
 	}
 	return ""
@@ -1921,6 +1962,28 @@ func (a *Audio) getSinkInfoByName(sinkName string) *pulse.Sink {
 	return nil
 }
 
+func (a *Audio) getSourceByPath(path dbus.ObjectPath) *Source {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, source := range a.sources {
+		if source.getPath() == path {
+			return source
+		}
+	}
+	return nil
+}
+
+func (a *Audio) getSinkByPath(path dbus.ObjectPath) *Sink {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, sink := range a.sinks {
+		if sink.getPath() == path {
+			return sink
+		}
+	}
+	return nil
+}
+
 func (a *Audio) getSourceInfoByName(sourceName string) *pulse.Source {
 	for _, sourceInfo := range a.ctx.GetSourceList() {
 		if sourceInfo.Name == sourceName {
@@ -39,6 +39,7 @@ FOR:
 // 事件分发
 func (a *Audio) dispatchEvents(events []*pulse.Event) {
 	logger.Debugf("dispatch %d events", len(events))
+	sourceOutputsChanged := false
 	for i, event := range events {
 		logger.Debugf("dispatch %dth event:facility<%d> type<%d> index<%d>", i, event.Facility, event.Type, event.Index)
 		switch event.Facility {
@@ -56,8 +57,14 @@ func (a *Audio) dispatchEvents(events []*pulse.Event) {
 		case pulse.FacilitySinkInput:
 			a.handleSinkInputEvent(event.Type, event.Index)
 			a.saveConfig()
+		case pulse.FacilitySourceOutput:
+			sourceOutputsChanged = true
 		}
 	}
+	// source-output 的信息需要通过 pactl 获取，多个事件只刷新一次
+	if sourceOutputsChanged {
+		a.handleSourceOutputsChanged()
+	}
 	logger.Debug("dispatch events done")
 }
 
@@ -490,6 +497,8 @@ func (a *Audio) handleSinkAdded(idx uint32) {
 	} else {
 		a.addSink(sink)
 	}
+	// 设备重新连接后，恢复应用选择的输出
+	a.moveSinkInputsToAppPreferSinks()
 
 	if err := a.autoSwitchOutputPort(); err != nil {
 		logger.Warning(err)
@@ -521,8 +530,12 @@ func (a *Audio) handleSinkChanged(idx uint32) {
 		logger.Warning(err)
 		return
 	}
-	if _, ok := a.sinks[idx]; ok {
-		a.sinks[idx].update(sink)
+	if s, ok := a.sinks[idx]; ok {
+		oldPort := s.ActivePort.Name
+		s.update(sink)
+		if s.ActivePort.Name != oldPort {
+			a.moveSinkInputsToAppPreferSinks()
+		}
 	}
 
 }
@@ -558,6 +571,8 @@ func (a *Audio) handleSourceAdded(idx uint32) {
 		a.addSource(source)
 	}
 	a.updatePropSources()
+	// 设备重新连接后，恢复应用选择的输入
+	a.moveSourceOutputsToAppPreferSources()
 
 	if err := a.autoSwitchInputPort(); err != nil {
 		logger.Warning(err)
@@ -568,6 +583,8 @@ func (a *Audio) handleSourceRemoved(idx uint32) {
 	// 数据更新在refreshSources中统一处理，这里只做业务逻辑上的响应
 	// 注意，此时idx已经失效了，无法获取已经失去的数据，如果业务需要，应当在refresh前进行数据备份
 	logger.Debugf("source %d removed", idx)
+	// 输入设备移除时回环模块已经自动卸载
+	a.takeLoopback(idx)
 	if _, exist := a.sources[idx]; exist {
 		a.service.StopExport(a.sources[idx])
 		delete(a.sources, idx)
@@ -590,8 +607,12 @@ func (a *Audio) handleSourceChanged(idx uint32) {
 		return
 	}
 
-	if _, ok := a.sources[idx]; ok {
-		a.sources[idx].update(source)
+	if s, ok := a.sources[idx]; ok {
+		oldPort := s.ActivePort.Name
+		s.update(source)
+		if s.ActivePort.Name != oldPort {
+			a.moveSourceOutputsToAppPreferSources()
+		}
 	}
 }
 
@@ -622,6 +643,9 @@ func (a *Audio) handleSinkInputAdded(idx uint32) {
 		a.sinkInputs[idx].update(sinkInput)
 	} else {
 		a.addSinkInput(sinkInput)
+		if obj, ok := a.sinkInputs[idx]; ok && obj.visible {
+			a.applyAppConfigToSinkInput(obj)
+		}
 	}
 	if a.defaultSink != nil {
 		list := []uint32{idx}
@@ -713,6 +737,10 @@ func (a *Audio) updatePropSinks() {
 	var ids []int
 	a.mu.Lock()
 	for _, sink := range a.sinks {
+		// 均衡器的 sink 是内部使用的，不出现在列表中
+		if isEqualizerSink(sink.Name) {
+			continue
+		}
 		ids = append(ids, int(sink.index))
 	}
 	a.mu.Unlock()
@@ -743,7 +771,7 @@ func (a *Audio) updatePropSinkInputs() {
 
 func isPhysicalDevice(deviceName string) bool {
 	for _, virtualDeviceKey := range []string{
-		"echoCancelSource", "echo-cancel", "Echo-Cancel", "remap-sink-mono", // virtual key
+		"echoCancelSource", "echo-cancel", "Echo-Cancel", "remap-sink-mono", equalizerSinkName, // virtual key
 	} {
 		if strings.Contains(deviceName, virtualDeviceKey) {
 			return false
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 
@@ -23,6 +24,9 @@ type PortConfig struct {
 	ReduceNoise    bool
 	Mute           bool   // 静音改为全局，此配置废弃
 	PreferProfile  string //优先设置的配置文件
+	// 启用的均衡器预设名，为空时不启用均衡器
+	Equalizer        string                      `json:",omitempty"`
+	EqualizerPresets map[string]*EqualizerPreset `json:",omitempty"` // Name => EqualizerPreset
 }
 
 type CardConfig struct {
@@ -37,20 +41,35 @@ type MuteConfig struct {
 	MuteInput  bool
 }
 
+// AppConfig 应用的音量配置，在应用的 sink-input 出现时恢复
+type AppConfig struct {
+	Name       string // 应用的进程名，或者修正后的图标名
+	Volume     float64
+	Mute       bool
+	Balance    float64
+	PreferCard string // 期望的输出声卡和端口，为空或不可用时使用默认输出
+	PreferPort string
+	// 期望的输入声卡和端口，为空或不可用时使用默认输入
+	PreferSourceCard string `json:",omitempty"`
+	PreferSourcePort string `json:",omitempty"`
+}
+
 type ConfigKeeper struct {
 	Cards    map[string]*CardConfig // Name => CardConfig
 	Mute     *MuteConfig            // 全局静音
+	Apps     map[string]*AppConfig  // Name => AppConfig
 	file     string                 // 配置文件路径
 	muteFile string                 // 静音配置文件路径
+	appFile  string                 // 应用音量配置文件路径
 	mu       sync.Mutex             //
 }
 
 // 创建单例
-func createConfigKeeperSingleton(path string, mutePath string) func() *ConfigKeeper {
+func createConfigKeeperSingleton(path string, mutePath string, appPath string) func() *ConfigKeeper {
 	var ck *ConfigKeeper = nil
 	return func() *ConfigKeeper {
 		if ck == nil {
-			ck = NewConfigKeeper(path, mutePath)
+			ck = NewConfigKeeper(path, mutePath, appPath)
 		}
 		return ck
 	}
@@ -60,14 +79,17 @@ func createConfigKeeperSingleton(path string, mutePath string) func() *ConfigKee
 // 由于优先级管理需要在很多个对象中使用，放在Audio对象中需要添加额外参数传递到各个模块很不方便，因此在此创建一个全局的单例
 var globalConfigKeeperFile = filepath.Join(basedir.GetUserConfigDir(), "deepin/dde-daemon/audio-config-keeper.json")
 var globalConfigKeeperMuteFile = filepath.Join(basedir.GetUserConfigDir(), "deepin/dde-daemon/audio-config-keeper-mute.json")
-var GetConfigKeeper = createConfigKeeperSingleton(globalConfigKeeperFile, globalConfigKeeperMuteFile)
+var globalConfigKeeperAppFile = filepath.Join(basedir.GetUserConfigDir(), "deepin/dde-daemon/audio-config-keeper-app.json")
+var GetConfigKeeper = createConfigKeeperSingleton(globalConfigKeeperFile, globalConfigKeeperMuteFile, globalConfigKeeperAppFile)
 
-func NewConfigKeeper(path string, mutePath string) *ConfigKeeper {
+func NewConfigKeeper(path string, mutePath string, appPath string) *ConfigKeeper {
 	return &ConfigKeeper{
 		Cards:    make(map[string]*CardConfig),
 		Mute:     NewMuteConfig(),
+		Apps:     make(map[string]*AppConfig),
 		file:     path,
 		muteFile: mutePath,
+		appFile:  appPath,
 	}
 }
 
@@ -134,10 +156,46 @@ func (ck *ConfigKeeper) Save() error {
 		return err
 	}
 
+	return ck.saveApps()
+}
+
+func (ck *ConfigKeeper) saveApps() error {
+	data, err := json.MarshalIndent(ck.Apps, "", "  ")
+	if err != nil {
+		logger.Warning(err)
+		return err
+	}
+
+	err = os.WriteFile(ck.appFile, data, 0644)
+	if err != nil {
+		logger.Warning(err)
+		return err
+	}
 	return nil
 }
 
+func (ck *ConfigKeeper) loadApps() {
+	data, err := os.ReadFile(ck.appFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warning(err)
+		}
+		return
+	}
+
+	err = json.Unmarshal(data, &ck.Apps)
+	if err != nil {
+		logger.Warning(err)
+	}
+	if ck.Apps == nil {
+		ck.Apps = make(map[string]*AppConfig)
+	}
+}
+
 func (ck *ConfigKeeper) Load() error {
+	// 应用音量配置单独保存，不受声卡配置是否存在的影响
+	ck.loadApps()
+
 	data, err := os.ReadFile(ck.file)
 	if err != nil {
 		logger.Warning(err)
@@ -354,3 +412,128 @@ func (card *CardConfig) UpdatePortConfig(portConfig *PortConfig) {
 func (card *CardConfig) RemovePortConfig(portName string) {
 	delete(card.Ports, portName)
 }
+
+// GetAppConfig 返回应用音量配置的副本，没有配置时返回 nil
+func (ck *ConfigKeeper) GetAppConfig(name string) *AppConfig {
+	ck.mu.Lock()
+	defer ck.mu.Unlock()
+
+	app, ok := ck.Apps[name]
+	if !ok {
+		return nil
+	}
+	cfg := *app
+	return &cfg
+}
+
+func (ck *ConfigKeeper) SetAppConfig(app *AppConfig) {
+	ck.mu.Lock()
+	defer ck.mu.Unlock()
+
+	cfg := *app
+	ck.Apps[app.Name] = &cfg
+	ck.saveApps()
+}
+
+func (ck *ConfigKeeper) RemoveAppConfig(name string) bool {
+	ck.mu.Lock()
+	defer ck.mu.Unlock()
+
+	if _, ok := ck.Apps[name]; !ok {
+		return false
+	}
+	delete(ck.Apps, name)
+	ck.saveApps()
+	return true
+}
+
+// ListAppConfigs 返回按名称排序的应用音量配置
+func (ck *ConfigKeeper) ListAppConfigs() []*AppConfig {
+	ck.mu.Lock()
+	defer ck.mu.Unlock()
+
+	list := make([]*AppConfig, 0, len(ck.Apps))
+	for _, app := range ck.Apps {
+		cfg := *app
+		list = append(list, &cfg)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// SetEqualizerPreset 添加或修改端口的均衡器预设
+func (ck *ConfigKeeper) SetEqualizerPreset(cardName string, portName string, preset *EqualizerPreset) {
+	ck.mu.Lock()
+	defer ck.mu.Unlock()
+
+	_, port := ck.GetCardAndPortConfig(cardName, portName)
+	if port.EqualizerPresets == nil {
+		port.EqualizerPresets = make(map[string]*EqualizerPreset)
+	}
+	port.EqualizerPresets[preset.Name] = preset.clone()
+	ck.Save()
+}
+
+// RemoveEqualizerPreset 删除端口的均衡器预设，删除的是启用的预设时同时关闭均衡器
+func (ck *ConfigKeeper) RemoveEqualizerPreset(cardName string, portName string, name string) bool {
+	ck.mu.Lock()
+	defer ck.mu.Unlock()
+
+	_, port := ck.GetCardAndPortConfig(cardName, portName)
+	if _, ok := port.EqualizerPresets[name]; !ok {
+		return false
+	}
+	delete(port.EqualizerPresets, name)
+	if port.Equalizer == name {
+		port.Equalizer = ""
+	}
+	ck.Save()
+	return true
+}
+
+// ListEqualizerPresets 返回端口按名称排序的均衡器预设
+func (ck *ConfigKeeper) ListEqualizerPresets(cardName string, portName string) []*EqualizerPreset {
+	ck.mu.Lock()
+	defer ck.mu.Unlock()
+
+	_, port := ck.GetCardAndPortConfig(cardName, portName)
+	list := make([]*EqualizerPreset, 0, len(port.EqualizerPresets))
+	for _, preset := range port.EqualizerPresets {
+		list = append(list, preset.clone())
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// SetEqualizer 启用端口的均衡器预设，name 为空时关闭均衡器
+func (ck *ConfigKeeper) SetEqualizer(cardName string, portName string, name string) bool {
+	ck.mu.Lock()
+	defer ck.mu.Unlock()
+
+	_, port := ck.GetCardAndPortConfig(cardName, portName)
+	if name != "" {
+		if _, ok := port.EqualizerPresets[name]; !ok {
+			return false
+		}
+	}
+	port.Equalizer = name
+	ck.Save()
+	return true
+}
+
+// GetEqualizer 返回端口启用的均衡器预设的副本，没有启用时返回 nil
+func (ck *ConfigKeeper) GetEqualizer(cardName string, portName string) *EqualizerPreset {
+	ck.mu.Lock()
+	defer ck.mu.Unlock()
+
+	_, port := ck.GetCardAndPortConfig(cardName, portName)
+	preset, ok := port.EqualizerPresets[port.Equalizer]
+	if !ok {
+		return nil
+	}
+	return preset.clone()
+}
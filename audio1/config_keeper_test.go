@@ -15,6 +15,7 @@ func TestConfigKeeper_Save(t *testing.T) {
 	type fields struct {
 		file     string
 		muteFile string
+		appFile  string
 		Cards    map[string]*CardConfig
 	}
 	tests := []struct {
@@ -28,6 +29,7 @@ func TestConfigKeeper_Save(t *testing.T) {
 			fields: fields{
 				file:     "./testdata/ConfigKeeper_Save",
 				muteFile: "./testdata/ConfigKeeperMute_Save",
+				appFile:  "./testdata/ConfigKeeperApp_Save",
 				Cards: map[string]*CardConfig{
 					"one": {
 						Name:          "xxx",
@@ -52,6 +54,7 @@ func TestConfigKeeper_Save(t *testing.T) {
 			fields: fields{
 				file:     "./testdata/ConfigKeeper_Save",
 				muteFile: "./testdata/ConfigKeeperMute_Save",
+				appFile:  "./testdata/ConfigKeeperApp_Save",
 				Cards:    map[string]*CardConfig{},
 			},
 			wantErr:     false,
@@ -63,6 +66,7 @@ func TestConfigKeeper_Save(t *testing.T) {
 			ck := &ConfigKeeper{
 				file:     tt.fields.file,
 				muteFile: tt.fields.muteFile,
+				appFile:  tt.fields.appFile,
 				Cards:    tt.fields.Cards,
 			}
 			err := ck.Save()
@@ -82,6 +86,74 @@ func TestConfigKeeper_Save(t *testing.T) {
 			assert.Equal(t, tt.fileContent, string(content))
 
 			os.Remove(tt.fields.file)
+			os.Remove(tt.fields.muteFile)
+			os.Remove(tt.fields.appFile)
 		})
 	}
 }
+
+func TestConfigKeeper_AppConfig(t *testing.T) {
+	appFile := "./testdata/ConfigKeeperApp_AppConfig"
+	ck := NewConfigKeeper("", "", appFile)
+	defer os.Remove(appFile)
+
+	assert.Nil(t, ck.GetAppConfig("vlc"))
+	ck.SetAppConfig(&AppConfig{Name: "vlc", Volume: 0.5, PreferCard: "card0", PreferPort: "analog-output"})
+	ck.SetAppConfig(&AppConfig{Name: "firefox", Volume: 0.8, Mute: true})
+
+	cfg := ck.GetAppConfig("vlc")
+	require.NotNil(t, cfg)
+	cfg.Volume = 1
+	assert.Equal(t, 0.5, ck.GetAppConfig("vlc").Volume)
+
+	list := ck.ListAppConfigs()
+	require.Len(t, list, 2)
+	assert.Equal(t, "firefox", list[0].Name)
+
+	ck2 := NewConfigKeeper("", "", appFile)
+	ck2.loadApps()
+	assert.Equal(t, ck.Apps, ck2.Apps)
+
+	assert.True(t, ck.RemoveAppConfig("vlc"))
+	assert.False(t, ck.RemoveAppConfig("vlc"))
+	assert.Nil(t, ck.GetAppConfig("vlc"))
+}
+
+func TestConfigKeeper_Equalizer(t *testing.T) {
+	file := "./testdata/ConfigKeeper_Equalizer"
+	muteFile := "./testdata/ConfigKeeperMute_Equalizer"
+	appFile := "./testdata/ConfigKeeperApp_Equalizer"
+	ck := NewConfigKeeper(file, muteFile, appFile)
+	defer func() {
+		os.Remove(file)
+		os.Remove(muteFile)
+		os.Remove(appFile)
+	}()
+
+	const card, port = "card0", "analog-output-speaker"
+	assert.Nil(t, ck.GetEqualizer(card, port))
+	assert.False(t, ck.SetEqualizer(card, port, "rock"))
+
+	ck.SetEqualizerPreset(card, port, &EqualizerPreset{Name: "rock", Type: equalizerTypeParametric,
+		Bands: []EqualizerBand{{Freq: 100, Gain: 3, Q: 1}}})
+	ck.SetEqualizerPreset(card, port, &EqualizerPreset{Name: "bass", Type: equalizerTypeParametric,
+		Bands: []EqualizerBand{{Freq: 60, Gain: 6, Q: 0.7}}})
+	list := ck.ListEqualizerPresets(card, port)
+	require.Len(t, list, 2)
+	assert.Equal(t, "bass", list[0].Name)
+
+	assert.True(t, ck.SetEqualizer(card, port, "rock"))
+	preset := ck.GetEqualizer(card, port)
+	require.NotNil(t, preset)
+	preset.Bands[0].Gain = 10
+	assert.Equal(t, 3.0, ck.GetEqualizer(card, port).Bands[0].Gain)
+
+	ck2 := NewConfigKeeper(file, muteFile, appFile)
+	require.NoError(t, ck2.Load())
+	assert.Equal(t, ck.Cards, ck2.Cards)
+
+	assert.True(t, ck.RemoveEqualizerPreset(card, port, "rock"))
+	assert.False(t, ck.RemoveEqualizerPreset(card, port, "rock"))
+	assert.Nil(t, ck.GetEqualizer(card, port))
+	assert.True(t, ck.SetEqualizer(card, port, ""))
+}
@@ -0,0 +1,369 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package audio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	dbus "github.com/godbus/dbus/v5"
+	"github.com/linuxdeepin/go-lib/dbusutil"
+	"github.com/linuxdeepin/go-lib/pulse"
+)
+
+// 均衡器类型
+const (
+	equalizerType10Band     = "10band"
+	equalizerTypeParametric = "parametric"
+)
+
+const (
+	// 均衡器加载一个 module-ladspa-sink 在输出设备上，并作为默认输出
+	equalizerSinkName   = "equalizer-sink"
+	equalizerModuleName = "module-ladspa-sink"
+	// swh-plugins 的多频段均衡器，控制参数依次为各个固定频段的增益(dB)
+	equalizerPlugin      = "mbeq_1197"
+	equalizerPluginLabel = "mbeq"
+
+	equalizerMinGain           = -20.0
+	equalizerMaxGain           = 20.0
+	equalizerMinFreq           = 20.0
+	equalizerMaxFreq           = 20000.0
+	equalizerMinQ              = 0.1
+	equalizerMaxQ              = 10.0
+	equalizerDefaultQ          = 1.41 // 约为一个八度的带宽
+	equalizerMaxParametricBand = 10
+)
+
+// 10 段均衡器的中心频率
+var equalizer10BandFreqs = []float64{31, 62, 125, 250, 500, 1000, 2000, 4000, 8000, 16000}
+
+// mbeq 插件各个频段的中心频率
+var equalizerPluginFreqs = []float64{50, 100, 156, 220, 311, 440, 622, 880, 1250, 1750, 2500, 3500, 5000, 10000, 20000}
+
+type EqualizerBand struct {
+	Freq float64 // 中心频率，单位 Hz
+	Gain float64 // 增益，单位 dB
+	Q    float64
+}
+
+// EqualizerPreset 均衡器预设，保存在端口配置中
+type EqualizerPreset struct {
+	Name  string
+	Type  string // 10band 或 parametric
+	Bands []EqualizerBand
+}
+
+func (p *EqualizerPreset) clone() *EqualizerPreset {
+	preset := *p
+	preset.Bands = append([]EqualizerBand(nil), p.Bands...)
+	return &preset
+}
+
+func (p *EqualizerPreset) equal(other *EqualizerPreset) bool {
+	if p == nil || other == nil {
+		return p == other
+	}
+	if p.Name != other.Name || p.Type != other.Type || len(p.Bands) != len(other.Bands) {
+		return false
+	}
+	for i := range p.Bands {
+		if p.Bands[i] != other.Bands[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// check 检查预设是否合法，并补全 10 段均衡器的中心频率和 Q 值
+func (p *EqualizerPreset) check() error {
+	if p.Name == "" {
+		return errors.New("preset name is empty")
+	}
+	switch p.Type {
+	case equalizerType10Band:
+		if len(p.Bands) != len(equalizer10BandFreqs) {
+			return fmt.Errorf("10-band equalizer needs %d bands, got %d", len(equalizer10BandFreqs), len(p.Bands))
+		}
+		for i := range p.Bands {
+			band := &p.Bands[i]
+			if band.Freq == 0 {
+				band.Freq = equalizer10BandFreqs[i]
+			} else if band.Freq != equalizer10BandFreqs[i] {
+				return fmt.Errorf("invalid frequency of band %d: %v", i, band.Freq)
+			}
+			if band.Q == 0 {
+				band.Q = equalizerDefaultQ
+			}
+		}
+	case equalizerTypeParametric:
+		if len(p.Bands) == 0 || len(p.Bands) > equalizerMaxParametricBand {
+			return fmt.Errorf("parametric equalizer needs 1 to %d bands, got %d", equalizerMaxParametricBand, len(p.Bands))
+		}
+	default:
+		return fmt.Errorf("invalid equalizer type %q", p.Type)
+	}
+
+	for i, band := range p.Bands {
+		if band.Freq < equalizerMinFreq || band.Freq > equalizerMaxFreq {
+			return fmt.Errorf("invalid frequency of band %d: %v", i, band.Freq)
+		}
+		if band.Gain < equalizerMinGain || band.Gain > equalizerMaxGain {
+			return fmt.Errorf("invalid gain of band %d: %v", i, band.Gain)
+		}
+		if band.Q < equalizerMinQ || band.Q > equalizerMaxQ {
+			return fmt.Errorf("invalid Q of band %d: %v", i, band.Q)
+		}
+	}
+	return nil
+}
+
+// qToBandwidth 把 Q 值换算为以八度为单位的带宽
+func qToBandwidth(q float64) float64 {
+	return 2 * math.Asinh(1/(2*q)) / math.Ln2
+}
+
+// gainAt 估算预设在 freq 处的增益，每个频段近似为对数频率上的钟形曲线，距中心频率半个带宽处增益减半
+func (p *EqualizerPreset) gainAt(freq float64) float64 {
+	var gain float64
+	for _, band := range p.Bands {
+		x := 2 * math.Log2(freq/band.Freq) / qToBandwidth(band.Q)
+		gain += band.Gain * math.Exp(-math.Ln2*x*x)
+	}
+	gain = math.Max(equalizerMinGain, math.Min(equalizerMaxGain, gain))
+	gain = math.Round(gain*100) / 100
+	if gain == 0 {
+		// 避免出现 -0
+		return 0
+	}
+	return gain
+}
+
+// moduleArgs 返回加载在 master 上的均衡器模块的参数，所有频段增益为 0 时返回 nil
+func (p *EqualizerPreset) moduleArgs(master string) []string {
+	gains := make([]string, len(equalizerPluginFreqs))
+	flat := true
+	for i, freq := range equalizerPluginFreqs {
+		gain := p.gainAt(freq)
+		if gain != 0 {
+			flat = false
+		}
+		gains[i] = strconv.FormatFloat(gain, 'g', -1, 64)
+	}
+	if flat {
+		return nil
+	}
+	return []string{
+		"sink_name=" + equalizerSinkName,
+		"sink_master=" + master,
+		"plugin=" + equalizerPlugin,
+		"label=" + equalizerPluginLabel,
+		"control=" + strings.Join(gains, ","),
+		"sink_properties=device.description=Equalizer",
+		"sink_input_properties=media.role=filter",
+	}
+}
+
+var equalizerSinkNameRegexp = regexp.MustCompile(`sink_name=([^\s]+)`)
+
+// getEqualizerModules 返回已加载的均衡器模块，包括上次运行时遗留的和旧版本按频段串联的
+func (a *Audio) getEqualizerModules() []*pulse.Module {
+	var modules []*pulse.Module
+	for _, module := range a.ctx.GetModuleList() {
+		if module.Name != equalizerModuleName {
+			continue
+		}
+		match := equalizerSinkNameRegexp.FindStringSubmatch(module.Argument)
+		if len(match) > 1 && isEqualizerSink(match[1]) {
+			modules = append(modules, module)
+		}
+	}
+	return modules
+}
+
+// isEqualizerSink 判断 sink 是否为均衡器加载的，这类 sink 不对外导出
+func isEqualizerSink(name string) bool {
+	return strings.HasPrefix(name, equalizerSinkName)
+}
+
+func (a *Audio) isEqualizerModule(index uint32) bool {
+	for _, module := range a.getEqualizerModules() {
+		if module.Index == index {
+			return true
+		}
+	}
+	return false
+}
+
+// unloadEqualizer 卸载所有均衡器模块，需要持有 equalizerMu
+func (a *Audio) unloadEqualizer() {
+	modules := a.getEqualizerModules()
+	if len(modules) == 0 {
+		return
+	}
+	defaultSinkIsEqualizer := isEqualizerSink(a.context().GetDefaultSink())
+	// 从最后加载的模块开始卸载
+	for i := len(modules) - 1; i >= 0; i-- {
+		out, err := exec.Command("pactl", "unload-module", strconv.FormatUint(uint64(modules[i].Index), 10)).CombinedOutput()
+		if err != nil {
+			logger.Warningf("failed to unload equalizer module %d: %v %s", modules[i].Index, err, out)
+		}
+	}
+	if defaultSinkIsEqualizer && a.equalizerMaster != "" {
+		a.context().SetDefaultSink(a.equalizerMaster)
+	}
+	a.equalizerMaster = ""
+	a.equalizerPreset = nil
+}
+
+// applyEqualizer 在输出设备 master 上加载均衡器，preset 为 nil 时卸载均衡器
+func (a *Audio) applyEqualizer(master string, preset *EqualizerPreset) error {
+	a.equalizerMu.Lock()
+	defer a.equalizerMu.Unlock()
+
+	if preset == nil {
+		a.unloadEqualizer()
+		return nil
+	}
+	if a.equalizerMaster == master && a.equalizerPreset.equal(preset) {
+		return nil
+	}
+	a.unloadEqualizer()
+
+	args := preset.moduleArgs(master)
+	if len(args) == 0 {
+		// 所有频段增益为 0，不需要加载模块
+		return nil
+	}
+	logger.Infof("load equalizer %s on sink %s", preset.Name, master)
+	out, err := exec.Command("pactl", append([]string{"load-module", equalizerModuleName}, args...)...).CombinedOutput()
+	if err != nil {
+		a.unloadEqualizer()
+		return fmt.Errorf("failed to load equalizer module: %v %s", err, out)
+	}
+	a.equalizerMaster = master
+	a.equalizerPreset = preset
+	a.context().SetDefaultSink(equalizerSinkName)
+	return nil
+}
+
+// resumeEqualizer 输出端口激活时恢复端口启用的均衡器
+func (a *Audio) resumeEqualizer(s *Sink) {
+	preset := GetConfigKeeper().GetEqualizer(a.getCardNameById(s.Card), s.ActivePort.Name)
+	err := a.applyEqualizer(s.Name, preset)
+	if err != nil {
+		logger.Warning(err)
+	}
+}
+
+// checkEqualizerPort 检查端口是否为声卡上的输出端口
+func (a *Audio) checkEqualizerPort(cardId uint32, portName string) (cardName string, err error) {
+	card, err := a.cards.get(cardId)
+	if err != nil {
+		return "", err
+	}
+	port, err := card.getPortByName(portName)
+	if err != nil {
+		return "", err
+	}
+	if port.Direction != pulse.DirectionSink {
+		return "", fmt.Errorf("port %s is not an output port", portName)
+	}
+	return card.Name, nil
+}
+
+// isActiveOutputPort 判断端口是否为默认输出当前使用的端口
+func (a *Audio) isActiveOutputPort(cardId uint32, portName string) bool {
+	return a.defaultSink != nil && a.defaultSink.Card == cardId &&
+		a.defaultSink.ActivePort.Name == portName
+}
+
+// ListEqualizerPresets 返回输出端口 JSON 格式的均衡器预设列表
+func (a *Audio) ListEqualizerPresets(cardId uint32, portName string) (presets string, busErr *dbus.Error) {
+	cardName, err := a.checkEqualizerPort(cardId, portName)
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	data, err := json.Marshal(GetConfigKeeper().ListEqualizerPresets(cardName, portName))
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	return string(data), nil
+}
+
+// SetEqualizerPreset 添加或修改输出端口的均衡器预设，preset 为 JSON 格式，修改的是启用的预设时立即生效
+func (a *Audio) SetEqualizerPreset(cardId uint32, portName string, preset string) *dbus.Error {
+	logger.Infof("dbus call SetEqualizerPreset with cardId %d, portName %s and preset %s", cardId, portName, preset)
+	cardName, err := a.checkEqualizerPort(cardId, portName)
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+	var p EqualizerPreset
+	err = json.Unmarshal([]byte(preset), &p)
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+	err = p.check()
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+	ck := GetConfigKeeper()
+	ck.SetEqualizerPreset(cardName, portName, &p)
+	if a.isActiveOutputPort(cardId, portName) {
+		a.resumeEqualizer(a.defaultSink)
+	}
+	return nil
+}
+
+// DeleteEqualizerPreset 删除输出端口的均衡器预设，删除的是启用的预设时关闭均衡器
+func (a *Audio) DeleteEqualizerPreset(cardId uint32, portName string, name string) *dbus.Error {
+	logger.Infof("dbus call DeleteEqualizerPreset with cardId %d, portName %s and name %s", cardId, portName, name)
+	cardName, err := a.checkEqualizerPort(cardId, portName)
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+	if !GetConfigKeeper().RemoveEqualizerPreset(cardName, portName, name) {
+		return dbusutil.ToError(fmt.Errorf("equalizer preset %q not found", name))
+	}
+	if a.isActiveOutputPort(cardId, portName) {
+		a.resumeEqualizer(a.defaultSink)
+	}
+	return nil
+}
+
+// SetEqualizer 启用输出端口的均衡器预设，name 为空时关闭均衡器
+func (a *Audio) SetEqualizer(cardId uint32, portName string, name string) *dbus.Error {
+	logger.Infof("dbus call SetEqualizer with cardId %d, portName %s and name %s", cardId, portName, name)
+	cardName, err := a.checkEqualizerPort(cardId, portName)
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+	if !GetConfigKeeper().SetEqualizer(cardName, portName, name) {
+		return dbusutil.ToError(fmt.Errorf("equalizer preset %q not found", name))
+	}
+	if a.isActiveOutputPort(cardId, portName) {
+		a.resumeEqualizer(a.defaultSink)
+	}
+	return nil
+}
+
+// GetEqualizer 返回输出端口启用的均衡器预设名，没有启用时返回空字符串
+func (a *Audio) GetEqualizer(cardId uint32, portName string) (name string, busErr *dbus.Error) {
+	cardName, err := a.checkEqualizerPort(cardId, portName)
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	preset := GetConfigKeeper().GetEqualizer(cardName, portName)
+	if preset == nil {
+		return "", nil
+	}
+	return preset.Name, nil
+}
@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package audio
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEqualizerPresetCheck(t *testing.T) {
+	preset := &EqualizerPreset{Name: "rock", Type: equalizerType10Band, Bands: make([]EqualizerBand, 10)}
+	require.NoError(t, preset.check())
+	for i, band := range preset.Bands {
+		assert.Equal(t, equalizer10BandFreqs[i], band.Freq)
+		assert.Equal(t, equalizerDefaultQ, band.Q)
+	}
+
+	parametric := &EqualizerPreset{Name: "voice", Type: equalizerTypeParametric,
+		Bands: []EqualizerBand{{Freq: 3000, Gain: 4, Q: 2}}}
+	assert.NoError(t, parametric.check())
+
+	invalid := []*EqualizerPreset{
+		{Type: equalizerTypeParametric, Bands: []EqualizerBand{{Freq: 3000, Q: 1}}},
+		{Name: "a", Type: "graphic", Bands: []EqualizerBand{{Freq: 3000, Q: 1}}},
+		{Name: "a", Type: equalizerType10Band, Bands: make([]EqualizerBand, 9)},
+		{Name: "a", Type: equalizerType10Band, Bands: []EqualizerBand{{Freq: 30}, {}, {}, {}, {}, {}, {}, {}, {}, {}}},
+		{Name: "a", Type: equalizerTypeParametric},
+		{Name: "a", Type: equalizerTypeParametric, Bands: make([]EqualizerBand, 11)},
+		{Name: "a", Type: equalizerTypeParametric, Bands: []EqualizerBand{{Freq: 10, Q: 1}}},
+		{Name: "a", Type: equalizerTypeParametric, Bands: []EqualizerBand{{Freq: 3000, Gain: 30, Q: 1}}},
+		{Name: "a", Type: equalizerTypeParametric, Bands: []EqualizerBand{{Freq: 3000}}},
+	}
+	for _, p := range invalid {
+		assert.Error(t, p.check(), "%+v", p)
+	}
+}
+
+func TestQToBandwidth(t *testing.T) {
+	assert.InDelta(t, 1.0, qToBandwidth(1.41), 0.01)
+	assert.InDelta(t, 2.0, qToBandwidth(0.667), 0.01)
+}
+
+func TestEqualizerModuleArgs(t *testing.T) {
+	preset := &EqualizerPreset{Name: "a", Type: equalizerTypeParametric, Bands: []EqualizerBand{
+		{Freq: 100, Gain: 3, Q: 1.41},
+		{Freq: 1000, Gain: 0, Q: 1.41},
+		{Freq: 10000, Gain: -2.5, Q: 1.41},
+	}}
+	args := preset.moduleArgs("alsa_output.pci")
+	require.Len(t, args, 7)
+	assert.Equal(t, "sink_name=equalizer-sink", args[0])
+	assert.Equal(t, "sink_master=alsa_output.pci", args[1])
+	assert.Equal(t, "plugin=mbeq_1197", args[2])
+	controls := strings.Split(strings.TrimPrefix(args[4], "control="), ",")
+	require.Len(t, controls, len(equalizerPluginFreqs))
+	assert.Equal(t, "3", controls[1])
+	assert.Equal(t, "-2.5", controls[13])
+	assert.Equal(t, "0", controls[9])
+	assert.InDelta(t, 1.5, preset.gainAt(141), 0.05)
+	assert.False(t, isPhysicalDevice("equalizer-sink"))
+	assert.True(t, isEqualizerSink("equalizer-sink.1"))
+
+	loud := &EqualizerPreset{Name: "loud", Type: equalizerTypeParametric, Bands: []EqualizerBand{
+		{Freq: 1000, Gain: 20, Q: 0.5}, {Freq: 1250, Gain: 20, Q: 0.5},
+	}}
+	assert.Equal(t, equalizerMaxGain, loud.gainAt(1100))
+
+	flat := &EqualizerPreset{Name: "flat", Type: equalizerType10Band, Bands: make([]EqualizerBand, 10)}
+	require.NoError(t, flat.check())
+	assert.Nil(t, flat.moduleArgs("alsa_output.pci"))
+}
+
+func TestEqualizerPresetEqual(t *testing.T) {
+	a := &EqualizerPreset{Name: "a", Type: equalizerTypeParametric, Bands: []EqualizerBand{{Freq: 100, Gain: 3, Q: 1}}}
+	b := a.clone()
+	assert.True(t, a.equal(b))
+	b.Bands[0].Gain = 2
+	assert.False(t, a.equal(b))
+	assert.False(t, a.equal(nil))
+	var nilPreset *EqualizerPreset
+	assert.True(t, nilPreset.equal(nil))
+}
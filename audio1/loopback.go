@@ -0,0 +1,159 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	dbus "github.com/godbus/dbus/v5"
+	"github.com/linuxdeepin/go-lib/dbusutil"
+)
+
+const (
+	loopbackModuleName     = "module-loopback"
+	defaultLoopbackLatency = 200  // ms
+	maxLoopbackLatency     = 5000 // ms
+)
+
+// loopback 把输入设备的声音直接回放到输出设备，用于测试麦克风
+type loopback struct {
+	sender string // 调用 StartLoopback 的 D-Bus 连接，连接断开时自动停止
+	module uint32
+}
+
+func getLoopbackModuleArgs(source, sink string, latencyMs uint32) ([]string, error) {
+	if latencyMs == 0 {
+		latencyMs = defaultLoopbackLatency
+	}
+	if latencyMs > maxLoopbackLatency {
+		return nil, fmt.Errorf("invalid latency: %d ms", latencyMs)
+	}
+	return []string{
+		"source=" + source,
+		"sink=" + sink,
+		fmt.Sprintf("latency_msec=%d", latencyMs),
+		// 设备移除时模块自动卸载，也不跟随默认设备移动
+		"source_dont_move=true",
+		"sink_dont_move=true",
+		"sink_input_properties=media.role=filter",
+	}, nil
+}
+
+func loadLoopbackModule(args []string) (uint32, error) {
+	out, err := exec.Command("pactl", append([]string{"load-module", loopbackModuleName}, args...)...).CombinedOutput()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load loopback module: %v %s", err, out)
+	}
+	module, err := strconv.ParseUint(strings.TrimSpace(string(out)), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse loopback module index %q: %v", out, err)
+	}
+	return uint32(module), nil
+}
+
+func unloadLoopbackModule(module uint32) {
+	out, err := exec.Command("pactl", "unload-module", strconv.FormatUint(uint64(module), 10)).CombinedOutput()
+	if err != nil {
+		logger.Warningf("failed to unload loopback module %d: %v %s", module, err, out)
+	}
+}
+
+// takeLoopback 从列表中移除 source 的回环并返回，没有时返回 nil
+func (a *Audio) takeLoopback(sourceIdx uint32) *loopback {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	lb, ok := a.loopbacks[sourceIdx]
+	if !ok {
+		return nil
+	}
+	delete(a.loopbacks, sourceIdx)
+	return lb
+}
+
+func (a *Audio) isLoopbackModule(module uint32) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, lb := range a.loopbacks {
+		if lb.module == module {
+			return true
+		}
+	}
+	return false
+}
+
+// stopLoopbacks 停止 sender 启动的回环，sender 为空时停止所有回环
+func (a *Audio) stopLoopbacks(sender string) {
+	var modules []uint32
+	a.mu.Lock()
+	for idx, lb := range a.loopbacks {
+		if sender == "" || lb.sender == sender {
+			modules = append(modules, lb.module)
+			delete(a.loopbacks, idx)
+		}
+	}
+	a.mu.Unlock()
+
+	for _, module := range modules {
+		logger.Infof("stop loopback module %d of %q", module, sender)
+		unloadLoopbackModule(module)
+	}
+}
+
+func (a *Audio) handleDBusNameOwnerChanged(name, oldOwner, newOwner string) {
+	// 调用者的唯一连接名消失
+	if strings.HasPrefix(name, ":") && oldOwner != "" && newOwner == "" {
+		a.stopLoopbacks(name)
+	}
+}
+
+// StartLoopback 把输入设备的声音回放到 sinkPath 对应的输出设备，latencyMs 为 0 时使用默认延迟。
+// 调用者断开 D-Bus 连接后自动停止
+func (s *Source) StartLoopback(sender dbus.Sender, sinkPath dbus.ObjectPath, latencyMs uint32) *dbus.Error {
+	logger.Infof("dbus call StartLoopback with sinkPath %s and latencyMs %d, the source name is %s",
+		sinkPath, latencyMs, s.Name)
+
+	sink := s.audio.getSinkByPath(sinkPath)
+	if sink == nil {
+		return dbusutil.ToError(fmt.Errorf("invalid sink path %q", sinkPath))
+	}
+	args, err := getLoopbackModuleArgs(s.Name, sink.Name, latencyMs)
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+
+	// 同一个输入设备只保留一个回环
+	if lb := s.audio.takeLoopback(s.index); lb != nil {
+		unloadLoopbackModule(lb.module)
+	}
+	module, err := loadLoopbackModule(args)
+	if err != nil {
+		logger.Warning(err)
+		return dbusutil.ToError(err)
+	}
+
+	s.audio.mu.Lock()
+	s.audio.loopbacks[s.index] = &loopback{
+		sender: string(sender),
+		module: module,
+	}
+	s.audio.mu.Unlock()
+	return nil
+}
+
+// StopLoopback 停止输入设备的回环
+func (s *Source) StopLoopback() *dbus.Error {
+	logger.Infof("dbus call StopLoopback, the source name is %s", s.Name)
+
+	lb := s.audio.takeLoopback(s.index)
+	if lb == nil {
+		return dbusutil.ToError(errors.New("loopback is not started"))
+	}
+	unloadLoopbackModule(lb.module)
+	return nil
+}
@@ -0,0 +1,27 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package audio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetLoopbackModuleArgs(t *testing.T) {
+	args, err := getLoopbackModuleArgs("alsa_input.pci", "alsa_output.pci", 0)
+	require.NoError(t, err)
+	assert.Contains(t, args, "source=alsa_input.pci")
+	assert.Contains(t, args, "sink=alsa_output.pci")
+	assert.Contains(t, args, "latency_msec=200")
+
+	args, err = getLoopbackModuleArgs("alsa_input.pci", "alsa_output.pci", 50)
+	require.NoError(t, err)
+	assert.Contains(t, args, "latency_msec=50")
+
+	_, err = getLoopbackModuleArgs("alsa_input.pci", "alsa_output.pci", maxLoopbackLatency+1)
+	assert.Error(t, err)
+}
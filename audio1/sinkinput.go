@@ -30,6 +30,7 @@ type SinkInput struct {
 	index             uint32
 	correctIconCalled bool
 	correctedIcon     string
+	appName           string // 保存应用音量配置时使用的名称
 	visible           bool
 	cVolume           pulse.CVolume
 	channelMap        pulse.ChannelMap
@@ -105,6 +106,10 @@ func (s *SinkInput) SetVolume(value float64, isPlay bool) *dbus.Error {
 	s.PropsMu.RUnlock()
 	s.audio.context().SetSinkInputVolume(s.index, cv)
 
+	s.updateAppConfig(func(cfg *AppConfig) {
+		cfg.Volume = value
+	})
+
 	if isPlay {
 		playFeedback()
 	}
@@ -125,6 +130,9 @@ func (s *SinkInput) SetBalance(value float64, isPlay bool) *dbus.Error {
 	cv := s.cVolume.SetBalance(s.channelMap, value)
 	s.PropsMu.RUnlock()
 	s.audio.context().SetSinkInputVolume(s.index, cv)
+	s.updateAppConfig(func(cfg *AppConfig) {
+		cfg.Balance = value
+	})
 
 	if isPlay {
 		playFeedback()
@@ -154,12 +162,45 @@ func (s *SinkInput) SetMute(value bool) *dbus.Error {
 	logger.Infof("dbus call SetMute with value %t, the sink input name is %s", value, s.Name)
 
 	s.audio.context().SetSinkInputMute(s.index, value)
+	s.updateAppConfig(func(cfg *AppConfig) {
+		cfg.Mute = value
+	})
 	if !value {
 		playFeedback()
 	}
 	return nil
 }
 
+// MoveToSink 把应用的声音移动到 sinkPath 对应的输出，并记住应用的选择，输出设备重新连接后恢复
+func (s *SinkInput) MoveToSink(sinkPath dbus.ObjectPath) *dbus.Error {
+	logger.Infof("dbus call MoveToSink with sink %s, the sink input name is %s", sinkPath, s.Name)
+
+	sink := s.audio.getSinkByPath(sinkPath)
+	if sink == nil {
+		err := fmt.Errorf("invalid sink path: %s", sinkPath)
+		logger.Warning(err)
+		return dbusutil.ToError(err)
+	}
+	sink.PropsMu.RLock()
+	sinkName := sink.Name
+	cardId := sink.Card
+	portName := sink.ActivePort.Name
+	sink.PropsMu.RUnlock()
+
+	s.audio.context().MoveSinkInputsByName([]uint32{s.index}, sinkName)
+
+	cardName := s.audio.getCardNameById(cardId)
+	if cardName == "" || portName == "" {
+		logger.Warningf("can not remember sink %s for %s", sinkName, s.Name)
+		return nil
+	}
+	s.updateAppConfig(func(cfg *AppConfig) {
+		cfg.PreferCard = cardName
+		cfg.PreferPort = portName
+	})
+	return nil
+}
+
 func (s *SinkInput) getPath() dbus.ObjectPath {
 	return dbus.ObjectPath(dbusPath + "/SinkInput" + strconv.Itoa(int(s.index)))
 }
@@ -305,6 +346,7 @@ func (s *SinkInput) update(sinkInputInfo *pulse.SinkInput) {
 	if err != nil {
 		logger.Warning(err)
 	}
+	s.appName = getSinkInputAppName(sinkInputInfo, correctedIcon)
 	if correctedIcon != "" {
 		icon = correctedIcon
 	}
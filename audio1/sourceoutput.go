@@ -0,0 +1,274 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package audio
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"sync"
+
+	dbus "github.com/godbus/dbus/v5"
+	"github.com/linuxdeepin/go-lib/dbusutil"
+)
+
+// pactlSourceOutput 是 pactl -f json list source-outputs 输出中使用到的字段，
+// go-lib 的 pulse.SourceOutput 没有任何字段，只能通过 pactl 获取 source-output 的信息
+type pactlSourceOutput struct {
+	Index          uint32            `json:"index"`
+	Source         uint32            `json:"source"`
+	ResampleMethod string            `json:"resample_method"`
+	Properties     map[string]string `json:"properties"`
+}
+
+// parseSourceOutputs 解析 pactl 以 JSON 格式输出的 source-output 列表
+func parseSourceOutputs(data []byte) ([]*pactlSourceOutput, error) {
+	var list []*pactlSourceOutput
+	err := json.Unmarshal(data, &list)
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func getSourceOutputList() ([]*pactlSourceOutput, error) {
+	out, err := exec.Command("pactl", "-f", "json", "list", "source-outputs").Output()
+	if err != nil {
+		return nil, fmt.Errorf("pactl list source-outputs failed: %v", err)
+	}
+	return parseSourceOutputs(out)
+}
+
+func moveSourceOutput(idx uint32, sourceName string) error {
+	out, err := exec.Command("pactl", "move-source-output",
+		strconv.FormatUint(uint64(idx), 10), sourceName).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("move source-output %d to %s failed: %v, %s", idx, sourceName, err, out)
+	}
+	return nil
+}
+
+// getSourceOutputVisible 音量计等峰值检测的录音流以及没有应用名的模块内部录音流不显示
+func getSourceOutputVisible(info *pactlSourceOutput) bool {
+	if info.ResampleMethod == "peaks" {
+		return false
+	}
+	return info.Properties[PropAppName] != ""
+}
+
+// getSourceOutputAppName 返回保存应用配置时使用的名称，优先使用进程名
+func getSourceOutputAppName(info *pactlSourceOutput) string {
+	if bin := info.Properties[PropAppProcessBinary]; bin != "" {
+		return bin
+	}
+	return info.Properties[PropAppName]
+}
+
+type SourceOutput struct {
+	audio   *Audio
+	service *dbusutil.Service
+	PropsMu sync.RWMutex
+	index   uint32
+	appName string // 保存应用配置时使用的名称
+	visible bool
+	// Name process name
+	Name        string
+	Icon        string
+	SourceIndex uint32
+}
+
+func newSourceOutput(info *pactlSourceOutput, audio *Audio) *SourceOutput {
+	sourceOutput := &SourceOutput{
+		audio:   audio,
+		service: audio.service,
+		index:   info.Index,
+		visible: getSourceOutputVisible(info),
+	}
+	sourceOutput.update(info)
+	return sourceOutput
+}
+
+func (s *SourceOutput) update(info *pactlSourceOutput) {
+	s.PropsMu.Lock()
+	defer s.PropsMu.Unlock()
+
+	s.appName = getSourceOutputAppName(info)
+	s.setPropSourceIndex(info.Source)
+	s.setPropName(info.Properties[PropAppName])
+	icon := info.Properties[PropAppIconName]
+	if icon == "" {
+		icon = "audio-input-microphone"
+	}
+	s.setPropIcon(icon)
+}
+
+func (s *SourceOutput) getAppName() string {
+	s.PropsMu.RLock()
+	defer s.PropsMu.RUnlock()
+	return s.appName
+}
+
+func (s *SourceOutput) getPropSourceIndex() uint32 {
+	s.PropsMu.RLock()
+	defer s.PropsMu.RUnlock()
+	return s.SourceIndex
+}
+
+// MoveToSource 把应用的录音移动到 sourcePath 对应的输入，并记住应用的选择，输入设备重新连接后恢复
+func (s *SourceOutput) MoveToSource(sourcePath dbus.ObjectPath) *dbus.Error {
+	logger.Infof("dbus call MoveToSource with source %s, the source output name is %s", sourcePath, s.Name)
+
+	source := s.audio.getSourceByPath(sourcePath)
+	if source == nil {
+		err := fmt.Errorf("invalid source path: %s", sourcePath)
+		logger.Warning(err)
+		return dbusutil.ToError(err)
+	}
+	source.PropsMu.RLock()
+	sourceName := source.Name
+	cardId := source.Card
+	portName := source.ActivePort.Name
+	source.PropsMu.RUnlock()
+
+	err := moveSourceOutput(s.index, sourceName)
+	if err != nil {
+		logger.Warning(err)
+		return dbusutil.ToError(err)
+	}
+
+	cardName := s.audio.getCardNameById(cardId)
+	name := s.getAppName()
+	if cardName == "" || portName == "" || name == "" {
+		logger.Warningf("can not remember source %s for %s", sourceName, s.Name)
+		return nil
+	}
+	ck := GetConfigKeeper()
+	cfg := ck.GetAppConfig(name)
+	if cfg == nil {
+		// 只选择过输入的应用不调整播放音量
+		cfg = &AppConfig{Name: name, Volume: 1}
+	}
+	cfg.PreferSourceCard = cardName
+	cfg.PreferSourcePort = portName
+	ck.SetAppConfig(cfg)
+	return nil
+}
+
+func (s *SourceOutput) getPath() dbus.ObjectPath {
+	return dbus.ObjectPath(dbusPath + "/SourceOutput" + strconv.Itoa(int(s.index)))
+}
+
+func (*SourceOutput) GetInterfaceName() string {
+	return dbusInterface + ".SourceOutput"
+}
+
+// refreshSourceOutputs 重新获取所有 source-output，返回新出现的 source-output
+func (a *Audio) refreshSourceOutputs() []*SourceOutput {
+	list, err := getSourceOutputList()
+	if err != nil {
+		logger.Warning(err)
+		return nil
+	}
+
+	if a.sourceOutputs == nil {
+		a.sourceOutputs = make(map[uint32]*SourceOutput)
+	}
+	var added []*SourceOutput
+	infoMap := make(map[uint32]*pactlSourceOutput, len(list))
+	for _, info := range list {
+		infoMap[info.Index] = info
+		sourceOutput, exist := a.sourceOutputs[info.Index]
+		if exist {
+			sourceOutput.update(info)
+			continue
+		}
+		logger.Debugf("add source-output #%d", info.Index)
+		sourceOutput = newSourceOutput(info, a)
+		a.sourceOutputs[info.Index] = sourceOutput
+		if sourceOutput.visible {
+			err := a.service.Export(sourceOutput.getPath(), sourceOutput)
+			if err != nil {
+				logger.Warning(err)
+			}
+			added = append(added, sourceOutput)
+		}
+	}
+
+	for key, sourceOutput := range a.sourceOutputs {
+		if _, exist := infoMap[key]; !exist {
+			logger.Debugf("delete source-output #%d", key)
+			if sourceOutput.visible {
+				a.service.StopExport(sourceOutput)
+			}
+			delete(a.sourceOutputs, key)
+		}
+	}
+	a.updatePropSourceOutputs()
+	return added
+}
+
+func (a *Audio) updatePropSourceOutputs() {
+	var ids []int
+	a.mu.Lock()
+	for _, sourceOutput := range a.sourceOutputs {
+		if sourceOutput.visible {
+			ids = append(ids, int(sourceOutput.index))
+		}
+	}
+	a.mu.Unlock()
+	a.updateObjPathsProp("SourceOutput", ids, a.setPropSourceOutputs)
+}
+
+// handleSourceOutputsChanged 在 source-output 变化后刷新，新的录音流移动到应用期望的输入
+func (a *Audio) handleSourceOutputsChanged() {
+	for _, sourceOutput := range a.refreshSourceOutputs() {
+		a.moveSourceOutputToAppPreferSource(sourceOutput)
+	}
+}
+
+// getAppPreferSource 返回应用期望的输入，期望的端口不是声卡当前使用的端口时返回 nil
+func (a *Audio) getAppPreferSource(cfg *AppConfig) *Source {
+	if cfg.PreferSourceCard == "" || cfg.PreferSourcePort == "" {
+		return nil
+	}
+	for _, source := range a.sources {
+		if source.ActivePort.Name == cfg.PreferSourcePort &&
+			a.getCardNameById(source.Card) == cfg.PreferSourceCard {
+			return source
+		}
+	}
+	return nil
+}
+
+// moveSourceOutputToAppPreferSource 把 source-output 移动到应用期望的输入
+func (a *Audio) moveSourceOutputToAppPreferSource(sourceOutput *SourceOutput) {
+	name := sourceOutput.getAppName()
+	if name == "" {
+		return
+	}
+	cfg := GetConfigKeeper().GetAppConfig(name)
+	if cfg == nil {
+		return
+	}
+	source := a.getAppPreferSource(cfg)
+	if source == nil || sourceOutput.getPropSourceIndex() == source.index {
+		return
+	}
+	logger.Infof("move source-output %d of %s to prefer source %s", sourceOutput.index, name, source.Name)
+	err := moveSourceOutput(sourceOutput.index, source.Name)
+	if err != nil {
+		logger.Warning(err)
+	}
+}
+
+// moveSourceOutputsToAppPreferSources 把所有 source-output 移动到应用期望的输入
+func (a *Audio) moveSourceOutputsToAppPreferSources() {
+	for _, sourceOutput := range a.sourceOutputs {
+		if sourceOutput.visible {
+			a.moveSourceOutputToAppPreferSource(sourceOutput)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package audio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSourceOutputs(t *testing.T) {
+	data := []byte(`[
+		{"index": 12, "driver": "protocol-native.c", "source": 3, "resample_method": "n/a",
+			"properties": {"application.name": "OBS", "application.process.binary": "obs", "media.name": "record"}},
+		{"index": 13, "source": 3, "resample_method": "peaks",
+			"properties": {"application.name": "dde-daemon", "media.name": "Peak detect"}},
+		{"index": 14, "source": 5, "properties": {"media.name": "Loopback to Speaker"}}
+	]`)
+	list, err := parseSourceOutputs(data)
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+
+	assert.Equal(t, uint32(12), list[0].Index)
+	assert.Equal(t, uint32(3), list[0].Source)
+	assert.Equal(t, "obs", getSourceOutputAppName(list[0]))
+	assert.True(t, getSourceOutputVisible(list[0]))
+	// 音量计和模块内部的录音流不显示
+	assert.False(t, getSourceOutputVisible(list[1]))
+	assert.False(t, getSourceOutputVisible(list[2]))
+
+	delete(list[0].Properties, PropAppProcessBinary)
+	assert.Equal(t, "OBS", getSourceOutputAppName(list[0]))
+
+	_, err = parseSourceOutputs([]byte("Failure: Connection refused"))
+	assert.Error(t, err)
+}
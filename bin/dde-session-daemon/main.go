@@ -215,6 +215,10 @@ func main() {
 	}
 
 	loader.SetService(service)
+	err = loader.ExportManager()
+	if err != nil {
+		logger.Warning("failed to export loader manager:", err)
+	}
 
 	if _options.logLevel == "" &&
 		(utils.IsEnvExists(log.DebugLevelEnv) || utils.IsEnvExists(log.DebugMatchEnv)) {
@@ -130,6 +130,10 @@ func main() {
 
 	startBacklightHelperAsync(service.Conn())
 	loader.SetService(service)
+	err = loader.ExportManager()
+	if err != nil {
+		logger.Warning("failed to export loader manager:", err)
+	}
 	loader.StartAll()
 	defer loader.StopAll()
 
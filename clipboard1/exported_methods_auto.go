@@ -12,11 +12,36 @@ func (v *Manager) GetExportedMethods() dbusutil.ExportedMethods {
 			Name: "BecomeClipboardOwner",
 			Fn:   v.BecomeClipboardOwner,
 		},
+		{
+			Name: "ClearHistory",
+			Fn:   v.ClearHistory,
+		},
+		{
+			Name:   "DeleteHistoryEntry",
+			Fn:     v.DeleteHistoryEntry,
+			InArgs: []string{"id"},
+		},
+		{
+			Name:    "GetHistory",
+			Fn:      v.GetHistory,
+			OutArgs: []string{"history"},
+		},
+		{
+			Name:    "GetHistoryEntry",
+			Fn:      v.GetHistoryEntry,
+			InArgs:  []string{"id"},
+			OutArgs: []string{"targets"},
+		},
 		{
 			Name:   "RemoveTarget",
 			Fn:     v.RemoveTarget,
 			InArgs: []string{"target"},
 		},
+		{
+			Name:   "RestoreHistoryEntry",
+			Fn:     v.RestoreHistoryEntry,
+			InArgs: []string{"id"},
+		},
 		{
 			Name: "SaveClipboard",
 			Fn:   v.SaveClipboard,
@@ -0,0 +1,516 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package clipboard
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/linuxdeepin/go-lib/xdg/basedir"
+)
+
+const (
+	defaultHistoryMaxEntries = 50
+	// 超过该大小的剪贴板内容不记录到历史中
+	historyEntryMaxSize = 16 * 1024 * 1024
+	// 所有记录的总大小，超过时删除最旧的记录
+	historyTotalMaxSize = 64 * 1024 * 1024
+	historyPreviewLen   = 100
+	// 连续复制时合并多次保存
+	historySaveDelay = 2 * time.Second
+
+	// 密码管理器通过该 target 标记剪贴板中的内容为密码
+	passwordManagerHintTarget = "x-kde-passwordManagerHint"
+)
+
+var (
+	historyFile = filepath.Join(basedir.GetUserCacheDir(), "deepin/dde-daemon/clipboard/history")
+	// 旧版本保存密钥的文件，现在密钥保存在 keyring 中
+	legacyHistoryKeyFile = filepath.Join(basedir.GetUserConfigDir(), "deepin/dde-daemon/clipboard/history.key")
+)
+
+const (
+	historyKindText    = "text"
+	historyKindImage   = "image"
+	historyKindUriList = "uri-list"
+)
+
+// getHistoryTargetKind 返回 target 在历史记录中的类别，不需要记录的 target 返回空字符串
+func getHistoryTargetKind(targetName string) string {
+	switch strings.ToLower(targetName) {
+	case "utf8_string", "string", "text", "text/plain", "text/plain;charset=utf-8":
+		return historyKindText
+	case "image/png", "image/jpeg", "image/bmp":
+		return historyKindImage
+	case "text/uri-list", "x-special/gnome-copied-files":
+		return historyKindUriList
+	}
+	return ""
+}
+
+type historyTarget struct {
+	Target string
+	Type   string
+	Format uint8
+	Data   []byte
+}
+
+type historyEntry struct {
+	Id   uint64
+	Time int64
+	// 复制内容的应用窗口的 WM_CLASS
+	WindowClass string
+	Targets     []*historyTarget
+}
+
+func (e *historyEntry) size() int {
+	var size int
+	for _, t := range e.Targets {
+		size += len(t.Data)
+	}
+	return size
+}
+
+func (e *historyEntry) getTarget(name string) *historyTarget {
+	for _, t := range e.Targets {
+		if t.Target == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// sameContent 判断两条记录的内容是否相同，用于合并重复复制的内容
+func (e *historyEntry) sameContent(other *historyEntry) bool {
+	if len(e.Targets) != len(other.Targets) {
+		return false
+	}
+	for _, t := range e.Targets {
+		o := other.getTarget(t.Target)
+		if o == nil || !bytes.Equal(t.Data, o.Data) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *historyEntry) kinds() []string {
+	var result []string
+	for _, t := range e.Targets {
+		kind := getHistoryTargetKind(t.Target)
+		found := false
+		for _, k := range result {
+			if k == kind {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, kind)
+		}
+	}
+	return result
+}
+
+func (e *historyEntry) preview() string {
+	var text *historyTarget
+	for _, name := range []string{"UTF8_STRING", "text/plain;charset=utf-8", "text/plain",
+		"text/uri-list", "STRING", "TEXT"} {
+		text = e.getTarget(name)
+		if text != nil {
+			break
+		}
+	}
+	if text == nil || !utf8.Valid(text.Data) {
+		return ""
+	}
+	s := strings.TrimSpace(string(text.Data))
+	if utf8.RuneCountInString(s) > historyPreviewLen {
+		s = string([]rune(s)[:historyPreviewLen])
+	}
+	return s
+}
+
+// HistoryEntryInfo 是 GetHistory 返回的历史记录摘要，不包含具体数据
+type HistoryEntryInfo struct {
+	Id uint64
+	// unix 时间戳（秒）
+	Time        int64
+	WindowClass string
+	// 包含的内容类别：text, image, uri-list
+	Kinds   []string
+	Targets []string
+	Size    int
+	// 文本内容的前若干个字符
+	Preview string
+}
+
+func (e *historyEntry) info() HistoryEntryInfo {
+	targets := make([]string, 0, len(e.Targets))
+	for _, t := range e.Targets {
+		targets = append(targets, t.Target)
+	}
+	return HistoryEntryInfo{
+		Id:          e.Id,
+		Time:        e.Time,
+		WindowClass: e.WindowClass,
+		Kinds:       e.kinds(),
+		Targets:     targets,
+		Size:        e.size(),
+		Preview:     e.preview(),
+	}
+}
+
+// clipboardHistory 保存最近的剪贴板内容，最新的记录在前，
+// 开启持久化时加密保存到 filename，密钥由 keyStore 保存在 keyring 中。
+// 记录变化后延迟 saveDelay 在单独的 goroutine 中保存，不阻塞剪贴板事件的处理。
+type clipboardHistory struct {
+	// 保证写文件的顺序，需要在 mu 之前获取
+	saveMu sync.Mutex
+
+	mu            sync.Mutex
+	enabled       bool
+	maxEntries    int
+	maxTotalSize  int
+	excludedApps  []string
+	persistent    bool
+	filename      string
+	keyStore      historyKeyStore
+	entries       []*historyEntry
+	lastId        uint64
+	persistFailed bool
+	saveDelay     time.Duration
+	saveTimer     *time.Timer
+}
+
+func newClipboardHistory(filename string, keyStore historyKeyStore) *clipboardHistory {
+	return &clipboardHistory{
+		enabled:      true,
+		maxEntries:   defaultHistoryMaxEntries,
+		maxTotalSize: historyTotalMaxSize,
+		filename:     filename,
+		keyStore:     keyStore,
+		saveDelay:    historySaveDelay,
+	}
+}
+
+func (h *clipboardHistory) isEnabled() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.enabled
+}
+
+func (h *clipboardHistory) setEnabled(enabled bool) {
+	h.mu.Lock()
+	h.enabled = enabled
+	h.mu.Unlock()
+}
+
+func (h *clipboardHistory) setExcludedApps(apps []string) {
+	h.mu.Lock()
+	h.excludedApps = apps
+	h.mu.Unlock()
+}
+
+// isExcluded 判断 WM_CLASS 的 instance 或者 class 是否在排除列表中，不区分大小写
+func (h *clipboardHistory) isExcluded(wmClass ...string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, app := range h.excludedApps {
+		for _, name := range wmClass {
+			if name != "" && strings.EqualFold(app, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (h *clipboardHistory) setMaxEntries(n int) {
+	if n <= 0 {
+		n = defaultHistoryMaxEntries
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.maxEntries = n
+	if h.trimLocked() {
+		h.saveLocked()
+	}
+}
+
+// trimLocked 删除超过数量或者总大小限制的最旧的记录
+func (h *clipboardHistory) trimLocked() bool {
+	n := len(h.entries)
+	if n > h.maxEntries {
+		n = h.maxEntries
+	}
+	var total int
+	for i := 0; i < n; i++ {
+		total += h.entries[i].size()
+		if total > h.maxTotalSize {
+			n = i
+			break
+		}
+	}
+	if n == len(h.entries) {
+		return false
+	}
+	h.entries = h.entries[:n]
+	return true
+}
+
+// setPersistent 开启时从磁盘加载历史记录，关闭时删除磁盘上的历史记录
+func (h *clipboardHistory) setPersistent(persistent bool) {
+	h.saveMu.Lock()
+	defer h.saveMu.Unlock()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.persistent == persistent {
+		return
+	}
+	h.persistent = persistent
+	if !persistent {
+		if h.saveTimer != nil {
+			h.saveTimer.Stop()
+		}
+		err := os.Remove(h.filename)
+		if err != nil && !os.IsNotExist(err) {
+			logger.Warning(err)
+		}
+		return
+	}
+
+	entries, err := loadHistory(h.filename, h.keyStore)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warning("load clipboard history failed:", err)
+		}
+	} else {
+		// 当前会话中的记录在前
+		h.entries = append(h.entries, entries...)
+		h.trimLocked()
+		for _, e := range h.entries {
+			if e.Id > h.lastId {
+				h.lastId = e.Id
+			}
+		}
+	}
+	h.handleSaveResultLocked(saveHistory(h.filename, h.keyStore, h.entries))
+}
+
+// add 添加一条记录，内容与最新的记录相同时只更新时间，返回新记录的 id
+func (h *clipboardHistory) add(entry *historyEntry) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.entries) > 0 && h.entries[0].sameContent(entry) {
+		h.entries[0].Time = entry.Time
+		h.entries[0].WindowClass = entry.WindowClass
+		h.saveLocked()
+		return h.entries[0].Id
+	}
+
+	h.lastId++
+	entry.Id = h.lastId
+	h.entries = append([]*historyEntry{entry}, h.entries...)
+	h.trimLocked()
+	h.saveLocked()
+	return entry.Id
+}
+
+func (h *clipboardHistory) list() []HistoryEntryInfo {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	result := make([]HistoryEntryInfo, 0, len(h.entries))
+	for _, e := range h.entries {
+		result = append(result, e.info())
+	}
+	return result
+}
+
+func (h *clipboardHistory) get(id uint64) *historyEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, e := range h.entries {
+		if e.Id == id {
+			return e
+		}
+	}
+	return nil
+}
+
+// moveToFront 将记录移到最前并更新时间
+func (h *clipboardHistory) moveToFront(id uint64) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, e := range h.entries {
+		if e.Id == id {
+			copy(h.entries[1:i+1], h.entries[:i])
+			h.entries[0] = e
+			e.Time = time.Now().Unix()
+			h.saveLocked()
+			return true
+		}
+	}
+	return false
+}
+
+func (h *clipboardHistory) remove(id uint64) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, e := range h.entries {
+		if e.Id == id {
+			h.entries = append(h.entries[:i], h.entries[i+1:]...)
+			h.saveLocked()
+			return true
+		}
+	}
+	return false
+}
+
+func (h *clipboardHistory) clear() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = nil
+	h.saveLocked()
+}
+
+// saveLocked 延迟保存历史记录，短时间内的多次修改只保存一次
+func (h *clipboardHistory) saveLocked() {
+	if !h.persistent {
+		return
+	}
+	if h.saveTimer == nil {
+		h.saveTimer = time.AfterFunc(h.saveDelay, h.flush)
+	} else {
+		h.saveTimer.Reset(h.saveDelay)
+	}
+}
+
+// flush 立即保存历史记录，加密和写文件时不持有 mu
+func (h *clipboardHistory) flush() {
+	h.saveMu.Lock()
+	defer h.saveMu.Unlock()
+
+	h.mu.Lock()
+	if !h.persistent {
+		h.mu.Unlock()
+		return
+	}
+	// 记录的数据不会被修改，只需要复制记录本身
+	entries := make([]*historyEntry, 0, len(h.entries))
+	for _, e := range h.entries {
+		entry := *e
+		entries = append(entries, &entry)
+	}
+	h.mu.Unlock()
+
+	err := saveHistory(h.filename, h.keyStore, entries)
+	h.mu.Lock()
+	h.handleSaveResultLocked(err)
+	h.mu.Unlock()
+}
+
+// sync 取消延迟保存并立即保存，在模块停止时调用
+func (h *clipboardHistory) sync() {
+	h.mu.Lock()
+	pending := h.saveTimer != nil && h.saveTimer.Stop()
+	h.mu.Unlock()
+	if pending {
+		h.flush()
+	}
+}
+
+func (h *clipboardHistory) handleSaveResultLocked(err error) {
+	if err != nil {
+		// 避免每次复制都打印相同的错误
+		if !h.persistFailed {
+			logger.Warning("save clipboard history failed:", err)
+		}
+		h.persistFailed = true
+		return
+	}
+	h.persistFailed = false
+}
+
+func newHistoryCipher(keyStore historyKeyStore) (cipher.AEAD, error) {
+	key, err := keyStore.getKey()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// saveHistory 使用 AES-GCM 加密保存历史记录，文件内容为 nonce 加上密文
+func saveHistory(filename string, keyStore historyKeyStore, entries []*historyEntry) error {
+	if entries == nil {
+		entries = []*historyEntry{}
+	}
+	content, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	aead, err := newHistoryCipher(keyStore)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return err
+	}
+	data := aead.Seal(nonce, nonce, content, nil)
+
+	err = os.MkdirAll(filepath.Dir(filename), 0700)
+	if err != nil {
+		return err
+	}
+	tmpFile := filename + ".tmp"
+	err = os.WriteFile(tmpFile, data, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, filename)
+}
+
+func loadHistory(filename string, keyStore historyKeyStore) ([]*historyEntry, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newHistoryCipher(keyStore)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("invalid history file")
+	}
+	nonce := data[:aead.NonceSize()]
+	content, err := aead.Open(nil, nonce, data[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, err
+	}
+	var entries []*historyEntry
+	err = json.Unmarshal(content, &entries)
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,154 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package clipboard
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/godbus/dbus/v5"
+	secrets "github.com/linuxdeepin/go-dbus-factory/session/org.freedesktop.secrets"
+)
+
+const historyKeySize = 32
+
+// historyKeyStore 提供加密历史记录的密钥
+type historyKeyStore interface {
+	// getKey 返回密钥，不存在时生成新的密钥
+	getKey() ([]byte, error)
+}
+
+// keyringKeyStore 将密钥保存在 Secret Service 的默认 keyring 中，与磁盘上的密文分开存放
+type keyringKeyStore struct {
+	// 旧版本保存在磁盘上的密钥文件，存在时迁移到 keyring 后删除
+	legacyKeyFile string
+
+	mu  sync.Mutex
+	key []byte
+}
+
+var historyKeyAttributes = map[string]string{
+	"application": "dde-daemon",
+	"type":        "clipboard-history-key",
+}
+
+func newKeyringKeyStore(legacyKeyFile string) *keyringKeyStore {
+	return &keyringKeyStore{
+		legacyKeyFile: legacyKeyFile,
+	}
+}
+
+func (s *keyringKeyStore) getKey() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.key != nil {
+		return s.key, nil
+	}
+
+	sessionBus, err := dbus.SessionBus()
+	if err != nil {
+		return nil, err
+	}
+	service := secrets.NewService(sessionBus)
+	_, sessionPath, err := service.OpenSession(0, "plain", dbus.MakeVariant(""))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		session, err := secrets.NewSession(sessionBus, sessionPath)
+		if err == nil {
+			err = session.Close(0)
+		}
+		if err != nil {
+			logger.Debug("close secret session failed:", err)
+		}
+	}()
+
+	key, err := readKeyringKey(service, sessionPath)
+	if err == nil && key == nil {
+		key, err = createKeyringKey(sessionBus, service, sessionPath, s.legacyKeyFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.key = key
+	return key, nil
+}
+
+// readKeyringKey 从 keyring 读取密钥，不存在时返回 nil
+func readKeyringKey(service secrets.Service, sessionPath dbus.ObjectPath) ([]byte, error) {
+	unlocked, locked, err := service.SearchItems(0, historyKeyAttributes)
+	if err != nil {
+		return nil, err
+	}
+	if len(unlocked) == 0 {
+		if len(locked) > 0 {
+			return nil, errors.New("the keyring is locked")
+		}
+		return nil, nil
+	}
+
+	secretData, err := service.GetSecrets(0, unlocked[:1], sessionPath)
+	if err != nil {
+		return nil, err
+	}
+	secret, ok := secretData[unlocked[0]]
+	if !ok || len(secret.Value) != historyKeySize {
+		return nil, fmt.Errorf("invalid key in keyring item %s", unlocked[0])
+	}
+	return secret.Value, nil
+}
+
+// createKeyringKey 在默认 keyring 中保存新的密钥，旧版本的密钥文件有效时使用其中的密钥以便读取已有的历史记录
+func createKeyringKey(sessionBus *dbus.Conn, service secrets.Service, sessionPath dbus.ObjectPath,
+	legacyKeyFile string) ([]byte, error) {
+	key, err := os.ReadFile(legacyKeyFile)
+	if err != nil || len(key) != historyKeySize {
+		key = make([]byte, historyKeySize)
+		_, err = io.ReadFull(rand.Reader, key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	collectionPath, err := service.ReadAlias(0, "default")
+	if err != nil {
+		return nil, err
+	}
+	if collectionPath == "/" {
+		return nil, errors.New("no default keyring")
+	}
+	collection, err := secrets.NewCollection(sessionBus, collectionPath)
+	if err != nil {
+		return nil, err
+	}
+	properties := map[string]dbus.Variant{
+		"org.freedesktop.Secret.Item.Label":      dbus.MakeVariant("Clipboard history encryption key"),
+		"org.freedesktop.Secret.Item.Attributes": dbus.MakeVariant(historyKeyAttributes),
+	}
+	itemSecret := secrets.Secret{
+		Session:     sessionPath,
+		Value:       key,
+		ContentType: "application/octet-stream",
+	}
+	itemPath, _, err := collection.CreateItem(0, properties, itemSecret, true)
+	if err != nil {
+		return nil, err
+	}
+	// 需要用户确认时不会立即创建，keyring 被锁定
+	if itemPath == "/" {
+		return nil, errors.New("the keyring is locked")
+	}
+
+	err = os.Remove(legacyKeyFile)
+	if err != nil && !os.IsNotExist(err) {
+		logger.Warning("remove legacy clipboard history key failed:", err)
+	}
+	return key, nil
+}
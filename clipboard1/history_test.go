@@ -0,0 +1,191 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package clipboard
+
+import (
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/linuxdeepin/dde-daemon/clipboard1/mocks"
+	x "github.com/linuxdeepin/go-x11-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTextHistoryEntry(text string) *historyEntry {
+	return &historyEntry{
+		Time: 1,
+		Targets: []*historyTarget{
+			{Target: "UTF8_STRING", Type: "UTF8_STRING", Format: 8, Data: []byte(text)},
+		},
+	}
+}
+
+func Test_clipboardHistory(t *testing.T) {
+	h := newClipboardHistory("", nil)
+	h.setMaxEntries(2)
+
+	id1 := h.add(newTextHistoryEntry("a"))
+	id2 := h.add(newTextHistoryEntry("b"))
+	// 与最新记录内容相同时合并
+	assert.Equal(t, id2, h.add(newTextHistoryEntry("b")))
+	id3 := h.add(newTextHistoryEntry("c"))
+
+	list := h.list()
+	require.Len(t, list, 2)
+	assert.Equal(t, id3, list[0].Id)
+	assert.Equal(t, id2, list[1].Id)
+	assert.Equal(t, "c", list[0].Preview)
+	assert.Equal(t, []string{historyKindText}, list[0].Kinds)
+	assert.Nil(t, h.get(id1))
+
+	assert.True(t, h.moveToFront(id2))
+	assert.Equal(t, id2, h.list()[0].Id)
+
+	assert.True(t, h.remove(id2))
+	assert.False(t, h.remove(id2))
+	assert.Len(t, h.list(), 1)
+
+	h.clear()
+	assert.Empty(t, h.list())
+}
+
+func Test_clipboardHistoryExcluded(t *testing.T) {
+	h := newClipboardHistory("", nil)
+	h.setExcludedApps([]string{"keepassxc"})
+	assert.True(t, h.isExcluded("keepassxc", "KeePassXC"))
+	assert.True(t, h.isExcluded("", "KeePassXC"))
+	assert.False(t, h.isExcluded("deepin-editor", "deepin-editor"))
+	assert.False(t, h.isExcluded("", ""))
+}
+
+// testKeyStore 在内存中保存密钥，代替测试环境中没有的 keyring
+type testKeyStore struct {
+	key []byte
+}
+
+func newTestKeyStore() *testKeyStore {
+	key := make([]byte, historyKeySize)
+	_, _ = rand.Read(key)
+	return &testKeyStore{key: key}
+}
+
+func (s *testKeyStore) getKey() ([]byte, error) {
+	return s.key, nil
+}
+
+func Test_clipboardHistoryPersistent(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "history")
+	keyStore := newTestKeyStore()
+
+	h := newClipboardHistory(filename, keyStore)
+	h.add(newTextHistoryEntry("secret text"))
+	_, err := os.Stat(filename)
+	assert.True(t, os.IsNotExist(err))
+
+	h.setPersistent(true)
+	content, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "secret text")
+
+	h2 := newClipboardHistory(filename, keyStore)
+	h2.setPersistent(true)
+	list := h2.list()
+	require.Len(t, list, 1)
+	assert.Equal(t, "secret text", list[0].Preview)
+	// 新的记录 id 不能与加载的记录重复
+	assert.NotEqual(t, list[0].Id, h2.add(newTextHistoryEntry("other")))
+
+	// 密钥不同时无法解密
+	_, err = loadHistory(filename, newTestKeyStore())
+	assert.Error(t, err)
+
+	h2.setPersistent(false)
+	_, err = os.Stat(filename)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func Test_clipboardHistoryTotalSize(t *testing.T) {
+	h := newClipboardHistory("", nil)
+	h.maxTotalSize = 10
+
+	id1 := h.add(newTextHistoryEntry("aaaa"))
+	id2 := h.add(newTextHistoryEntry("bbbb"))
+	id3 := h.add(newTextHistoryEntry("cccc"))
+	// 超过总大小时删除最旧的记录
+	list := h.list()
+	require.Len(t, list, 2)
+	assert.Equal(t, id3, list[0].Id)
+	assert.Equal(t, id2, list[1].Id)
+	assert.Nil(t, h.get(id1))
+}
+
+func Test_clipboardHistorySaveDelay(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "history")
+	h := newClipboardHistory(filename, newTestKeyStore())
+	h.saveDelay = time.Hour
+	h.setPersistent(true)
+
+	h.add(newTextHistoryEntry("a"))
+	h.add(newTextHistoryEntry("b"))
+	entries, err := loadHistory(filename, h.keyStore)
+	require.NoError(t, err)
+	// 延迟保存，文件中还是开启持久化时的内容
+	assert.Empty(t, entries)
+
+	h.sync()
+	entries, err = loadHistory(filename, h.keyStore)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	h.saveDelay = 10 * time.Millisecond
+	h.add(newTextHistoryEntry("c"))
+	assert.Eventually(t, func() bool {
+		entries, err := loadHistory(filename, h.keyStore)
+		return err == nil && len(entries) == 3
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestManager_newHistoryEntry(t *testing.T) {
+	xc := &mocks.XClient{}
+	m := &Manager{xc: xc}
+	atoms := map[x.Atom]string{
+		1: "UTF8_STRING",
+		2: "text/html",
+		3: "image/png",
+		4: passwordManagerHintTarget,
+		5: "STRING",
+	}
+	for atom, name := range atoms {
+		xc.On("GetAtomName", atom).Return(name, nil)
+	}
+
+	entry := m.newHistoryEntry(map[x.Atom]*TargetData{
+		1: {Target: 1, Type: 1, Format: 8, Data: []byte("hello")},
+		2: {Target: 2, Type: 5, Format: 8, Data: []byte("<b>hello</b>")},
+		3: {Target: 3, Type: 3, Format: 8, Data: []byte{0x89, 'P', 'N', 'G'}},
+	})
+	require.NotNil(t, entry)
+	assert.Len(t, entry.Targets, 2)
+	assert.Nil(t, entry.getTarget("text/html"))
+	assert.ElementsMatch(t, []string{historyKindText, historyKindImage}, entry.kinds())
+
+	// 密码管理器标记的内容不记录
+	entry = m.newHistoryEntry(map[x.Atom]*TargetData{
+		1: {Target: 1, Type: 1, Format: 8, Data: []byte("password")},
+		4: {Target: 4, Type: 5, Format: 8, Data: []byte("secret")},
+	})
+	assert.Nil(t, entry)
+
+	entry = m.newHistoryEntry(map[x.Atom]*TargetData{
+		2: {Target: 2, Type: 5, Format: 8, Data: []byte("<b>hello</b>")},
+	})
+	assert.Nil(t, entry)
+}
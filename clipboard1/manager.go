@@ -46,6 +46,10 @@ const (
 	dSettingsAppID                      = "org.deepin.dde.daemon"
 	dSettingsClipboardName              = "org.deepin.dde.daemon.clipboard"
 	dSettingsKeySaveAtomIncrDataEnabled = "saveAtomIncrDataEnabled"
+	dSettingsKeyHistoryEnabled          = "historyEnabled"
+	dSettingsKeyHistoryMaxEntries       = "historyMaxEntries"
+	dSettingsKeyHistoryPersistent       = "historyPersistent"
+	dSettingsKeyHistoryExcludedApps     = "historyExcludedApps"
 )
 
 func initAtoms(xConn *x.Conn) {
@@ -95,6 +99,14 @@ type Manager struct {
 	saveTargetsRequestor    x.Window
 	dsClipboardManager      ConfigManager.Manager
 	saveAtomIncrDataEnabled bool
+
+	service *dbusutil.Service
+	history *clipboardHistory
+
+	//nolint
+	signals *struct {
+		HistoryChanged struct{}
+	}
 }
 
 func (m *Manager) getTargetData(target x.Atom) *TargetData {
@@ -169,10 +181,48 @@ func (m *Manager) getConfigFromDSettings() error {
 		logger.Warning(err)
 	}
 
+	getHistoryConfig := func(key string) {
+		v, err := m.dsClipboardManager.Value(0, key)
+		if err != nil {
+			logger.Warning(err)
+			return
+		}
+		switch key {
+		case dSettingsKeyHistoryEnabled:
+			if enabled, ok := v.Value().(bool); ok {
+				m.history.setEnabled(enabled)
+			}
+		case dSettingsKeyHistoryMaxEntries:
+			switch n := v.Value().(type) {
+			case int64:
+				m.history.setMaxEntries(int(n))
+			case float64:
+				m.history.setMaxEntries(int(n))
+			}
+		case dSettingsKeyHistoryPersistent:
+			if persistent, ok := v.Value().(bool); ok {
+				m.history.setPersistent(persistent)
+			}
+		case dSettingsKeyHistoryExcludedApps:
+			if items, ok := v.Value().([]dbus.Variant); ok {
+				apps := make([]string, 0, len(items))
+				for _, item := range items {
+					if app, ok := item.Value().(string); ok {
+						apps = append(apps, app)
+					}
+				}
+				m.history.setExcludedApps(apps)
+			}
+		}
+	}
+
 	_, err = m.dsClipboardManager.ConnectValueChanged(func(key string) {
 		switch key {
 		case dSettingsKeySaveAtomIncrDataEnabled:
 			getSaveAtomIncrDataEnabled()
+		case dSettingsKeyHistoryEnabled, dSettingsKeyHistoryMaxEntries,
+			dSettingsKeyHistoryPersistent, dSettingsKeyHistoryExcludedApps:
+			getHistoryConfig(key)
 		}
 	})
 
@@ -181,11 +231,17 @@ func (m *Manager) getConfigFromDSettings() error {
 	}
 
 	getSaveAtomIncrDataEnabled()
+	// 先读取最大记录数，加载持久化的记录时需要
+	for _, key := range []string{dSettingsKeyHistoryEnabled, dSettingsKeyHistoryMaxEntries,
+		dSettingsKeyHistoryExcludedApps, dSettingsKeyHistoryPersistent} {
+		getHistoryConfig(key)
+	}
 
 	return nil
 }
 
 func (m *Manager) start() error {
+	m.history = newClipboardHistory(historyFile, newKeyringKeyStore(legacyHistoryKeyFile))
 	// 初始化配置
 	err := m.getConfigFromDSettings()
 	if err != nil {
@@ -321,7 +377,7 @@ func (m *Manager) handleEvent(ev x.GenericEvent) {
 							logger.Debug("do not call handleClipboardUpdated")
 							return
 						}
-						err := m.handleClipboardUpdated(event.SelectionTimestamp)
+						err := m.handleClipboardUpdated(event.Owner, event.SelectionTimestamp)
 						if err != nil {
 							logger.Warning("handle clipboard updated err:", err)
 						}
@@ -350,8 +406,8 @@ func (m *Manager) handleEvent(ev x.GenericEvent) {
 	}
 }
 
-// 处理剪贴板数据更新
-func (m *Manager) handleClipboardUpdated(ts x.Timestamp) error {
+// 处理剪贴板数据更新，owner 是新的 CLIPBOARD selection 的 owner
+func (m *Manager) handleClipboardUpdated(owner x.Window, ts x.Timestamp) error {
 	logger.Debug("handleClipboardUpdated", ts)
 
 	targets, err := m.getClipboardTargets(ts)
@@ -399,6 +455,7 @@ func (m *Manager) handleClipboardUpdated(ts x.Timestamp) error {
 
 	targetDataMap := m.saveTargets(targets, ts)
 	m.setContent(targetDataMap)
+	m.recordHistory(owner, targetDataMap)
 
 	logger.Debug("handleClipboardUpdated all format finish", ts)
 	return nil
@@ -608,6 +665,7 @@ func (m *Manager) covertClipboardManagerSaveTargets(ev *x.SelectionRequestEvent)
 
 	targetDataMap := m.saveTargets(targets, ev.Time)
 	m.setContent(targetDataMap)
+	m.recordHistory(ev.Requestor, targetDataMap)
 
 	m.saveTargetsRequestor = ev.Requestor
 	m.saveTargetsSuccessTime = time.Now()
@@ -0,0 +1,201 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package clipboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/linuxdeepin/go-lib/dbusutil"
+	x "github.com/linuxdeepin/go-x11-client"
+	"github.com/linuxdeepin/go-x11-client/util/wm/ewmh"
+	"github.com/linuxdeepin/go-x11-client/util/wm/icccm"
+)
+
+// getWindowClass 返回窗口的 WM_CLASS，selection owner 通常是应用的隐藏窗口，
+// 没有设置 WM_CLASS 时使用进程名作为 instance。
+func getWindowClass(xConn *x.Conn, win x.Window) (instance, class string) {
+	wmClass, err := icccm.GetWMClass(xConn, win).Reply(xConn)
+	if err == nil && (wmClass.Class != "" || wmClass.Instance != "") {
+		return wmClass.Instance, wmClass.Class
+	}
+	pid, err := ewmh.GetWMPid(xConn, win).Reply(xConn)
+	if err != nil {
+		return "", ""
+	}
+	comm, err := os.ReadFile(fmt.Sprintf("/proc/%d/comm", pid))
+	if err != nil {
+		return "", ""
+	}
+	return strings.TrimSpace(string(comm)), ""
+}
+
+// newHistoryEntry 从剪贴板数据中取出需要记录的 target，没有可记录的内容时返回 nil
+func (m *Manager) newHistoryEntry(targetDataMap map[x.Atom]*TargetData) *historyEntry {
+	entry := &historyEntry{
+		Time: time.Now().Unix(),
+	}
+	for _, td := range targetDataMap {
+		targetName, err := m.xc.GetAtomName(td.Target)
+		if err != nil {
+			continue
+		}
+		if targetName == passwordManagerHintTarget && string(td.Data) == "secret" {
+			logger.Debug("ignore clipboard content marked as secret")
+			return nil
+		}
+		if getHistoryTargetKind(targetName) == "" || len(td.Data) == 0 {
+			continue
+		}
+		typeName, err := m.xc.GetAtomName(td.Type)
+		if err != nil {
+			continue
+		}
+		entry.Targets = append(entry.Targets, &historyTarget{
+			Target: targetName,
+			Type:   typeName,
+			Format: td.Format,
+			Data:   td.Data,
+		})
+	}
+	if len(entry.Targets) == 0 || entry.size() > historyEntryMaxSize {
+		return nil
+	}
+	return entry
+}
+
+// recordHistory 将 owner 复制的内容加入剪贴板历史
+func (m *Manager) recordHistory(owner x.Window, targetDataMap map[x.Atom]*TargetData) {
+	if m.history == nil || !m.history.isEnabled() {
+		return
+	}
+	instance, class := getWindowClass(m.xc.Conn(), owner)
+	if m.history.isExcluded(instance, class) {
+		logger.Debugf("ignore clipboard content from excluded app %s|%s", instance, class)
+		return
+	}
+	entry := m.newHistoryEntry(targetDataMap)
+	if entry == nil {
+		return
+	}
+	entry.WindowClass = class
+	if entry.WindowClass == "" {
+		entry.WindowClass = instance
+	}
+	id := m.history.add(entry)
+	logger.Debugf("add clipboard history %d from %q", id, entry.WindowClass)
+	m.emitHistoryChanged()
+}
+
+func (m *Manager) emitHistoryChanged() {
+	if m.service == nil {
+		return
+	}
+	err := m.service.Emit(m, "HistoryChanged")
+	if err != nil {
+		logger.Warning(err)
+	}
+}
+
+// restoreHistoryEntry 用历史记录的内容替换当前剪贴板内容，并成为 CLIPBOARD selection 的 owner
+func (m *Manager) restoreHistoryEntry(id uint64) error {
+	entry := m.history.get(id)
+	if entry == nil {
+		return fmt.Errorf("history entry %d not found", id)
+	}
+
+	targetDataMap := make(map[x.Atom]*TargetData, len(entry.Targets))
+	for _, t := range entry.Targets {
+		target, err := m.xc.GetAtom(t.Target)
+		if err != nil {
+			return err
+		}
+		typ, err := m.xc.GetAtom(t.Type)
+		if err != nil {
+			return err
+		}
+		targetDataMap[target] = &TargetData{
+			Target: target,
+			Type:   typ,
+			Format: t.Format,
+			Data:   t.Data,
+		}
+	}
+	m.setContent(targetDataMap)
+
+	ts, err := m.getTimestamp()
+	if err != nil {
+		return err
+	}
+	err = m.becomeClipboardOwner(ts)
+	if err != nil {
+		return err
+	}
+	m.history.moveToFront(id)
+	m.emitHistoryChanged()
+	return nil
+}
+
+func (m *Manager) GetHistory() (history string, busErr *dbus.Error) {
+	if m.history == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(m.history.list())
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	return string(data), nil
+}
+
+func (m *Manager) GetHistoryEntry(id uint64) (targets map[string][]byte, busErr *dbus.Error) {
+	if m.history == nil {
+		return nil, dbusutil.ToError(fmt.Errorf("history entry %d not found", id))
+	}
+	entry := m.history.get(id)
+	if entry == nil {
+		return nil, dbusutil.ToError(fmt.Errorf("history entry %d not found", id))
+	}
+	targets = make(map[string][]byte, len(entry.Targets))
+	for _, t := range entry.Targets {
+		targets[t.Target] = t.Data
+	}
+	return targets, nil
+}
+
+func (m *Manager) RestoreHistoryEntry(id uint64) *dbus.Error {
+	logger.Infof("dbus call RestoreHistoryEntry with id %d", id)
+	if m.history == nil {
+		return dbusutil.ToError(fmt.Errorf("history entry %d not found", id))
+	}
+
+	err := m.restoreHistoryEntry(id)
+	if err != nil {
+		logger.Warning(err)
+		return dbusutil.ToError(err)
+	}
+	return nil
+}
+
+func (m *Manager) DeleteHistoryEntry(id uint64) *dbus.Error {
+	logger.Infof("dbus call DeleteHistoryEntry with id %d", id)
+	if m.history == nil || !m.history.remove(id) {
+		return dbusutil.ToError(fmt.Errorf("history entry %d not found", id))
+	}
+	m.emitHistoryChanged()
+	return nil
+}
+
+func (m *Manager) ClearHistory() *dbus.Error {
+	logger.Info("dbus call ClearHistory")
+	if m.history != nil {
+		m.history.clear()
+		m.emitHistoryChanged()
+	}
+	return nil
+}
@@ -33,6 +33,7 @@ func newModule() *Module {
 
 type Module struct {
 	*loader.ModuleBase
+	manager *Manager
 }
 
 func (*Module) GetDependencies() []string {
@@ -66,8 +67,10 @@ func (mo *Module) Start() error {
 	if err != nil {
 		return err
 	}
+	mo.manager = m
 
 	service := loader.GetService()
+	m.service = service
 	err = service.Export(dbusPath, m)
 	if err != nil {
 		return err
@@ -81,6 +84,10 @@ func (mo *Module) Start() error {
 	return nil
 }
 
-func (*Module) Stop() error {
+func (mo *Module) Stop() error {
+	// 保存尚未写入磁盘的历史记录
+	if mo.manager != nil && mo.manager.history != nil {
+		mo.manager.history.sync()
+	}
 	return nil
 }
@@ -0,0 +1,44 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+// Package gestureevent 定义可以绑定动作的手势事件，由 system/gesture1 和 gesture1 共用
+package gestureevent
+
+import "fmt"
+
+const (
+	DeviceTypeTouchpad    = "touchpad"
+	DeviceTypeTouchscreen = "touchscreen"
+)
+
+// SupportedEvent 是可以绑定动作的手势事件，Name 和 Direction 与 Event、TouchEdgeEvent 信号中的一致
+type SupportedEvent struct {
+	Name       string
+	Directions []string
+	MinFingers int32
+	MaxFingers int32
+}
+
+var supportedEvents = map[string][]SupportedEvent{
+	DeviceTypeTouchpad: {
+		{Name: "swipe", Directions: []string{"up", "down", "left", "right"}, MinFingers: 3, MaxFingers: 5},
+		{Name: "pinch", Directions: []string{"in", "out"}, MinFingers: 3, MaxFingers: 5},
+		{Name: "rotate", Directions: []string{"clockwise", "counterclockwise"}, MinFingers: 3, MaxFingers: 5},
+		{Name: "tap", Directions: []string{"none"}, MinFingers: 3, MaxFingers: 5},
+		{Name: "hold", Directions: []string{"none"}, MinFingers: 3, MaxFingers: 5},
+	},
+	DeviceTypeTouchscreen: {
+		// 边缘滑动只支持单指
+		{Name: "edge", Directions: []string{"top", "right", "bot", "left"}, MinFingers: 1, MaxFingers: 1},
+	},
+}
+
+// List 返回 deviceType 设备支持的手势事件，deviceType 为 touchpad 或 touchscreen
+func List(deviceType string) ([]SupportedEvent, error) {
+	list, ok := supportedEvents[deviceType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported device type %q", deviceType)
+	}
+	return list, nil
+}
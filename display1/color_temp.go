@@ -5,11 +5,9 @@
 package display1
 
 import (
-	"bufio"
-	"bytes"
 	"errors"
+	"fmt"
 	"math"
-	"os"
 	"os/exec"
 	"regexp"
 	"strconv"
@@ -20,7 +18,7 @@ import (
 	configManager "github.com/linuxdeepin/go-dbus-factory/org.desktopspec.ConfigManager"
 
 	"github.com/godbus/dbus/v5"
-	geoclue2 "github.com/linuxdeepin/go-dbus-factory/system/org.freedesktop.geoclue2"
+	"github.com/linuxdeepin/dde-daemon/timedate1/zoneinfo"
 	"github.com/linuxdeepin/go-lib/dbusutil"
 )
 
@@ -35,8 +33,6 @@ const (
 )
 
 const (
-	timeZoneFile = "/usr/share/zoneinfo/zone1970.tab"
-
 	defaultAutoColorTemperatureConf = "6500:3500"
 	defaultTemperature              = 6500
 )
@@ -67,175 +63,279 @@ func (m *Manager) setColorTempModeReal(mode int32) {
 	}
 	switch mode {
 	case ColorTemperatureModeAuto: // 自动模式调节色温 启动服务
-		m.redshiftRunner.start()
+		m.colorTempScheduler.start()
 		m.stopCustomColorTempMode()
 	case ColorTemperatureModeManual, ColorTemperatureModeNone:
 		// manual 手动调节色温
 		// none 恢复正常色温
-		m.redshiftRunner.stop()
+		m.colorTempScheduler.stop()
 		m.stopCustomColorTempMode()
 	case ColorTemperatureModeCustom:
-		m.redshiftRunner.stop()
+		m.colorTempScheduler.stop()
 		m.listenCustomColorTempTime()
 	}
 	// 对于自动模式，也要先把色温设置为正常。
 	m.setColorTempOneShot()
 }
 
+// colorTempScheduler 根据太阳高度角计算自动模式下夜晚的程度，
+// 过渡阶段每隔 colorTempUpdateInterval 更新一次，从而平滑地调整色温。
+type colorTempScheduler struct {
+	mu       sync.Mutex
+	running  bool
+	stopCh   chan struct{}
+	location *colorTempLocation
+	// 0 为白天，1 为夜晚
+	factor float64
+	// 白天的色温，启动时从配置中读取
+	dayTemp int
+	cb      func()
+}
+
+// colorTempLocation 是计算日出日落时使用的位置
+type colorTempLocation struct {
+	Latitude  float64
+	Longitude float64
+	// 位置的来源，timezone 或者 manual
+	Source string
+}
+
 const (
-	redshiftStateRunning = iota + 1
-	redshiftStateStopped
+	colorTempLocationSourceTimezone = "timezone"
+	colorTempLocationSourceManual   = "manual"
+
+	colorTempUpdateInterval = 30 * time.Second
 )
 
-type zoneInfo struct {
-	country   string
-	latitude  float64
-	longitude float64
-	distance  float64
+func newColorTempScheduler() *colorTempScheduler {
+	return &colorTempScheduler{}
 }
 
-type redshiftRunner struct {
-	mu                 sync.Mutex
-	state              int
-	timer              *time.Timer
-	process            *os.Process
-	value              int
-	cb                 func(value int)
-	sysService         *dbusutil.Service
-	geoAgentRegistered bool
+func (s *colorTempScheduler) start() {
+	dayTemp := getAutoDayTemperature()
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return
+	}
+	logger.Debug("colorTempScheduler.start")
+	s.running = true
+	s.dayTemp = dayTemp
+	s.stopCh = make(chan struct{})
+	stopCh := s.stopCh
+	s.mu.Unlock()
 
-	zoneInfoMap map[string]*zoneInfo
+	s.update()
+	go func() {
+		ticker := time.NewTicker(colorTempUpdateInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.update()
+			case <-stopCh:
+				return
+			}
+		}
+	}()
 }
 
-func convertPos(pos string, digits int32) float64 {
-	if len(pos) < 4 || digits > 9 {
-		return 0.0
+func (s *colorTempScheduler) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.running {
+		return
 	}
+	logger.Debug("colorTempScheduler.stop")
+	close(s.stopCh)
+	s.running = false
+	s.factor = 0
+}
 
-	integer := pos[:digits+1]
-	fraction := pos[digits+1:]
-	t1, _ := strconv.ParseFloat(integer, 64)
-	t2, _ := strconv.ParseFloat(fraction, 64)
-	if t1 > 0.0 {
-		return t1 + t2/math.Pow(10.0, float64(len(fraction)))
-	} else {
-		return t1 - t2/math.Pow(10.0, float64(len(fraction)))
+func (s *colorTempScheduler) setLocation(location *colorTempLocation) {
+	s.mu.Lock()
+	s.location = location
+	s.mu.Unlock()
+	logger.Infof("color temperature location: %+v", location)
+	s.update()
+}
+
+func (s *colorTempScheduler) getLocation() *colorTempLocation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.location
+}
+
+// update 按当前时间重新计算夜晚的程度，变化时调用 cb
+func (s *colorTempScheduler) update() {
+	s.mu.Lock()
+	if !s.running || s.location == nil {
+		s.mu.Unlock()
+		return
+	}
+	elevation := solarElevation(time.Now(), s.location.Latitude, s.location.Longitude)
+	// 取两位小数，避免频繁地设置 gamma
+	factor := math.Round(nightFactor(elevation)*100) / 100
+	changed := factor != s.factor
+	s.factor = factor
+	s.mu.Unlock()
+
+	if changed {
+		logger.Debugf("solar elevation: %.2f, night factor: %.2f", elevation, factor)
+		if s.cb != nil {
+			s.cb()
+		}
 	}
 }
 
-func newRedshiftRunner() *redshiftRunner {
-	sysService, err := dbusutil.NewSystemService()
-	if err != nil {
-		logger.Warning("new sys service failed:", err)
+func (s *colorTempScheduler) getFactor() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.factor
+}
+
+func (s *colorTempScheduler) getDayTemperature() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dayTemp == 0 {
+		return defaultTemperature
 	}
-	zoneInfoMap := make(map[string]*zoneInfo)
-	contents, err := os.ReadFile(timeZoneFile)
-	if err != nil {
-		logger.Warning("Red timezone file failed:", err)
-	}
-	lines := bytes.Split(contents, []byte{'\n'})
-	for _, line := range lines {
-		if !bytes.HasPrefix(line, []byte{'#'}) {
-			parts := bytes.Split(line, []byte{'\t'})
-			if len(parts) >= 3 {
-				coordinates := string(parts[1])
-				index := strings.Index(coordinates[3:], "+")
-				if index == -1 {
-					index = strings.Index(coordinates[3:], "-")
-				}
-				if index > -1 {
-					latitude := convertPos(coordinates[:index+3], 2)
-					longitude := convertPos(coordinates[index+3:], 3)
-					zone_info := &zoneInfo{
-						country:   string(parts[0]),
-						latitude:  latitude,
-						longitude: longitude,
-					}
-					zoneInfoMap[string(parts[2])] = zone_info
-				}
+	return s.dayTemp
+}
+
+// getColorTempLocation 优先使用手动设置的经纬度，否则使用时区主要城市的经纬度
+func (m *Manager) getColorTempLocation() *colorTempLocation {
+	if m.displayConfigMgr != nil {
+		v, err := m.displayConfigMgr.Value(0, DSettingsKeyColorTemperatureLocation)
+		if err == nil {
+			str, _ := v.Value().(string)
+			location, err := parseColorTempLocation(str)
+			if err == nil {
+				return location
+			}
+			if str != "" {
+				logger.Warning(err)
 			}
 		}
 	}
-	return &redshiftRunner{
-		sysService:  sysService,
-		zoneInfoMap: zoneInfoMap,
+
+	coord, err := zoneinfo.GetZoneCoordinates(_timeZone)
+	if err != nil {
+		logger.Warningf("get coordinates of timezone %q failed: %v", _timeZone, err)
+		return nil
+	}
+	return &colorTempLocation{
+		Latitude:  coord.Latitude,
+		Longitude: coord.Longitude,
+		Source:    colorTempLocationSourceTimezone,
 	}
 }
 
-func (r *redshiftRunner) start() {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	logger.Debugf("redshiftRunner.start")
-
-	if r.state == redshiftStateRunning {
-		return
+// parseColorTempLocation 解析 "纬度,经度" 格式的位置
+func parseColorTempLocation(str string) (*colorTempLocation, error) {
+	parts := strings.Split(str, ",")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid location %q", str)
 	}
-	r.state = redshiftStateRunning
-	var colorConf string
-	val, err := getGlobalDconfValue(DSettingsAppID, DSettingsDisplayName, "", DSettingsKeyAutoColorTemperature)
+	latitude, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
 	if err != nil {
-		colorConf = defaultAutoColorTemperatureConf
-		logger.Warning(err)
-	} else {
-		colorConf = val.(string)
-	}
-	latitude := r.zoneInfoMap[_timeZone].latitude
-	longitude := r.zoneInfoMap[_timeZone].longitude
-	geographicalPosition := strconv.FormatFloat(latitude, 'f', -1, 64) + ":" + strconv.FormatFloat(longitude, 'f', -1, 64)
-	logger.Info("Get geographicalPosition:", geographicalPosition)
-	cmd := exec.Command("redshift", "-m", "dummy", "-t", colorConf, "-r")
-	if geographicalPosition != "" {
-		cmd.Args = append(cmd.Args, "-l", geographicalPosition)
-	}
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	var errBuf bytes.Buffer
-	cmd.Stderr = &errBuf
-	stdout, err := cmd.StdoutPipe()
+		return nil, err
+	}
+	longitude, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
 	if err != nil {
-		logger.Warning("get stdout pipe failed:", err)
-		r.state = redshiftStateStopped
-		return
+		return nil, err
+	}
+	if !isValidLocation(latitude, longitude) {
+		return nil, fmt.Errorf("invalid location %q", str)
+	}
+	return &colorTempLocation{
+		Latitude:  latitude,
+		Longitude: longitude,
+		Source:    colorTempLocationSourceManual,
+	}, nil
+}
+
+func isValidLocation(latitude, longitude float64) bool {
+	return latitude >= -90 && latitude <= 90 && longitude >= -180 && longitude <= 180
+}
+
+// dbus 上导出的方法
+func (m *Manager) setColorTempLocation(latitude, longitude float64) error {
+	if !isValidLocation(latitude, longitude) {
+		return errors.New("location out of range")
 	}
-	err = cmd.Start()
+	value := strconv.FormatFloat(latitude, 'f', -1, 64) + "," + strconv.FormatFloat(longitude, 'f', -1, 64)
+	err := setGlobalDconfValue(DSettingsAppID, DSettingsDisplayName, "", DSettingsKeyColorTemperatureLocation, dbus.MakeVariant(value))
 	if err != nil {
-		logger.Warning("start redshift failed:", err)
-		r.state = redshiftStateStopped
-		return
+		return err
 	}
+	m.colorTempScheduler.setLocation(m.getColorTempLocation())
+	return nil
+}
 
-	r.process = cmd.Process
-	r.value = 0
+// dbus 上导出的方法
+func (m *Manager) resetColorTempLocation() error {
+	err := setGlobalDconfValue(DSettingsAppID, DSettingsDisplayName, "", DSettingsKeyColorTemperatureLocation, dbus.MakeVariant(""))
+	if err != nil {
+		return err
+	}
+	m.colorTempScheduler.setLocation(m.getColorTempLocation())
+	return nil
+}
 
-	go func() {
-		logger.Debugf("redshift is running, pid: %v", r.process.Pid)
-		scanner := bufio.NewScanner(stdout)
-		for scanner.Scan() {
-			line := scanner.Bytes()
-			logger.Debugf("line: %s", line)
-			temp, ok := getTemperatureWithLine(line)
-			if ok {
-				logger.Debug("temp:", temp)
-				r.updateValue(temp)
-			}
-		}
-		err := scanner.Err()
-		if err != nil {
-			logger.Warning("scanner err:", err)
-		}
+// colorTempSchedule 是 GetColorTemperatureSchedule 返回的自动模式下今天的色温变化
+type colorTempSchedule struct {
+	colorTempLocation
+	solarTransitions
+	// 当前的夜晚程度，0 为白天，1 为夜晚
+	NightFactor float64
+	// 当前自动模式下的色温
+	Temperature int32
+}
 
-		err = cmd.Wait()
-		if err != nil {
-			logger.Debugf("redshift cmd wait err: %v, errBuf: %s", err, errBuf.Bytes())
-		}
-		logger.Debug("redshift stopped")
+func (m *Manager) getColorTempSchedule() (*colorTempSchedule, error) {
+	location := m.colorTempScheduler.getLocation()
+	if location == nil {
+		return nil, errors.New("location is unknown")
+	}
+	now := time.Now()
+	if loc, err := time.LoadLocation(_timeZone); err == nil {
+		now = now.In(loc)
+	}
+	factor := nightFactor(solarElevation(now, location.Latitude, location.Longitude))
+	return &colorTempSchedule{
+		colorTempLocation: *location,
+		solarTransitions:  getSolarTransitions(now, location.Latitude, location.Longitude),
+		NightFactor:       factor,
+		Temperature:       int32(m.getAutoColorTemperature(factor)),
+	}, nil
+}
 
-		r.mu.Lock()
-		r.state = redshiftStateStopped
-		r.process = nil
-		r.mu.Unlock()
-	}()
+// getAutoColorTemperature 在白天色温与手动设置的色温之间按夜晚的程度插值
+func (m *Manager) getAutoColorTemperature(factor float64) int {
+	m.PropsMu.RLock()
+	manual := m.ColorTemperatureManual
+	m.PropsMu.RUnlock()
 
-	return
+	day := m.colorTempScheduler.getDayTemperature()
+	return int(math.Round(float64(day) + (float64(manual)-float64(day))*factor))
+}
+
+// getAutoDayTemperature 从 autoColorTemperature 配置（白天色温:夜晚色温）中获取白天色温，
+// 夜晚使用手动设置的色温(from v20)。
+func getAutoDayTemperature() int {
+	colorConf := defaultAutoColorTemperatureConf
+	val, err := getGlobalDconfValue(DSettingsAppID, DSettingsDisplayName, "", DSettingsKeyAutoColorTemperature)
+	if err == nil {
+		if str, ok := val.(string); ok {
+			colorConf = str
+		}
+	}
+	day, err := strconv.Atoi(strings.SplitN(colorConf, ":", 2)[0])
+	if err != nil || !isValidColorTempValue(int32(day)) {
+		return defaultTemperature
+	}
+	return day
 }
 
 func (m *Manager) listenTimezone() {
@@ -275,67 +375,12 @@ func (m *Manager) listenTimezone() {
 				timezone, _ := v.Value().(string)
 				logger.Info("Timezone change to", timezone)
 				_timeZone = timezone
-				if m.redshiftRunner.state == redshiftStateRunning {
-					logger.Info("Redshift is Running")
-					m.redshiftRunner.stop()
-					time.AfterFunc(50*time.Millisecond, func() {
-						m.redshiftRunner.start()
-					})
-				}
+				m.colorTempScheduler.setLocation(m.getColorTempLocation())
 			}
 		}
 	}
 }
 
-func (r *redshiftRunner) stop() {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	if r.state == redshiftStateStopped {
-		return
-	}
-
-	logger.Debug("redshiftRunner.stop")
-	if r.process != nil {
-		err := r.process.Signal(os.Interrupt)
-		if err != nil {
-			logger.Warning("send signal interrupt to redshift process failed:", err)
-		}
-	}
-	r.value = 0
-}
-
-func (r *redshiftRunner) updateValue(value int) {
-	r.mu.Lock()
-	if r.value == value {
-		// no change
-		r.mu.Unlock()
-		return
-	}
-	r.value = value
-	r.mu.Unlock()
-
-	if r.cb != nil {
-		r.cb(value)
-	}
-}
-
-func (r *redshiftRunner) getValue() int {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	return r.value
-}
-
-func getTemperatureWithLine(line []byte) (int, bool) {
-	const prefix = "Temperature: "
-	if bytes.HasPrefix(line, []byte(prefix)) {
-		numStr := string(line[len(prefix):])
-		num, err := strconv.Atoi(numStr)
-		return num, err == nil
-	}
-	return 0, false
-}
-
 // dbus 上导出的方法
 func (m *Manager) setColorTempValue(value int32) error {
 	if !isValidColorTempValue(value) {
@@ -390,12 +435,7 @@ func (m *Manager) getColorTemperatureValue() int {
 	case ColorTemperatureModeManual:
 		return int(manual)
 	case ColorTemperatureModeAuto:
-		value := m.redshiftRunner.getValue()
-		// 日落时，返回手动设置的色温(from v20)
-		if value != defaultTemperature {
-			value = int(manual)
-		}
-		return value
+		return m.getAutoColorTemperature(m.colorTempScheduler.getFactor())
 	case ColorTemperatureModeCustom:
 		value := defaultTemperature
 		if m.customColorTempFlag {
@@ -419,6 +459,7 @@ func (m *Manager) applyColorTempConfig(displayMode byte) {
 	m.setColorTempModeReal(m.ColorTemperatureMode)
 }
 
+// controlRedshift 控制用户的 redshift 服务，避免与色温调节冲突
 func controlRedshift(action string) {
 	// #nosec G204
 	_, err := exec.Command("systemctl", "--user", action, "redshift.service").Output()
@@ -449,72 +490,6 @@ func (m *Manager) setColorTempOneShot() {
 	}
 }
 
-const (
-	dbusIfcGeoClueAgent  = "org.freedesktop.GeoClue2.Agent"
-	dbusPathGeoClueAgent = "/org/freedesktop/GeoClue2/Agent"
-)
-
-type geoClueAgent struct {
-	MaxAccuracyLevel uint32
-}
-
-const (
-	AccuracyLevelNone         = 0
-	AccuracyLevelCountry      = 1
-	AccuracyLevelCity         = 4
-	AccuracyLevelNeighborhood = 5
-	AccuracyLevelStreet       = 6
-	AccuracyLevelExact        = 8
-)
-
-func (a *geoClueAgent) GetInterfaceName() string {
-	return dbusIfcGeoClueAgent
-}
-
-func (a *geoClueAgent) AuthorizeApp(desktopId string, reqAccuracyLevel uint32) (authorized bool, allowedAccuracyLevel uint32, busErr *dbus.Error) {
-	// 目前发现这个方法不会被调用。
-	logger.Debugf("AuthorizeApp desktopId: %q, reqAccuracyLevel: %v", desktopId, reqAccuracyLevel)
-	return true, reqAccuracyLevel, nil
-}
-
-func (a *geoClueAgent) GetExportedMethods() dbusutil.ExportedMethods {
-	return dbusutil.ExportedMethods{
-		{
-			Name:    "AuthorizeApp",
-			Fn:      a.AuthorizeApp,
-			InArgs:  []string{"desktopId", "reqAccuracyLevel"},
-			OutArgs: []string{"authorized", "allowedAccuracyLevel"},
-		},
-	}
-}
-
-func (r *redshiftRunner) registerGeoClueAgent() error {
-	if r.geoAgentRegistered {
-		return nil
-	}
-	if r.sysService == nil {
-		return errors.New("sys service is nil")
-	}
-
-	sysBus := r.sysService.Conn()
-	agent := &geoClueAgent{
-		MaxAccuracyLevel: AccuracyLevelStreet,
-	}
-	err := r.sysService.Export(dbusPathGeoClueAgent, agent)
-	if err != nil {
-		return err
-	}
-
-	geoManager := geoclue2.NewManager(sysBus)
-	err = geoManager.AddAgent(0, "geoclue-demo-agent")
-	if err != nil {
-		return err
-	}
-
-	r.geoAgentRegistered = true
-	return nil
-}
-
 func setGlobalDconfValue(appID string, name string, subPath string, key string, value dbus.Variant) error {
 	sysBus, err := dbus.SystemBus()
 	if err != nil {
@@ -53,6 +53,11 @@ func (v *Manager) GetExportedMethods() dbusutil.ExportedMethods {
 			Fn:      v.GetBuiltinMonitor,
 			OutArgs: []string{"outArg0", "outArg1"},
 		},
+		{
+			Name:    "GetColorTemperatureSchedule",
+			Fn:      v.GetColorTemperatureSchedule,
+			OutArgs: []string{"schedule"},
+		},
 		{
 			Name:    "GetRealDisplayMode",
 			Fn:      v.GetRealDisplayMode,
@@ -85,6 +90,10 @@ func (v *Manager) GetExportedMethods() dbusutil.ExportedMethods {
 			Name: "ResetChanges",
 			Fn:   v.ResetChanges,
 		},
+		{
+			Name: "ResetColorTemperatureLocation",
+			Fn:   v.ResetColorTemperatureLocation,
+		},
 		{
 			Name: "Save",
 			Fn:   v.Save,
@@ -104,6 +113,11 @@ func (v *Manager) GetExportedMethods() dbusutil.ExportedMethods {
 			Fn:     v.SetColorTemperature,
 			InArgs: []string{"value"},
 		},
+		{
+			Name:   "SetColorTemperatureLocation",
+			Fn:     v.SetColorTemperatureLocation,
+			InArgs: []string{"latitude", "longitude"},
+		},
 		{
 			Name:   "SetCustomColorTempTimePeriod",
 			Fn:     v.SetCustomColorTempTimePeriod,
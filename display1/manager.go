@@ -88,6 +88,7 @@ const (
 	DSettingsKeyColorTemperatureManual   = "colorTemperatureManual"
 	DSettingsKeyRotateScreenTimeDelay    = "rotateScreenTimeDelay"
 	DSettingsKeyCustomDisplayMode        = "customDisplayMode"
+	DSettingsKeyColorTemperatureLocation = "colorTemperatureLocation"
 
 	customModeDelim              = "+"
 	monitorsIdDelimiter          = ","
@@ -156,11 +157,11 @@ type Manager struct {
 	builtinMonitorMu         sync.Mutex
 	candidateBuiltinMonitors []*Monitor // 候补的
 
-	monitorMap     map[uint32]*Monitor
-	monitorMapMu   sync.Mutex
-	mm             monitorManager
-	debugOpts      debugOptions
-	redshiftRunner *redshiftRunner
+	monitorMap         map[uint32]*Monitor
+	monitorMapMu       sync.Mutex
+	mm                 monitorManager
+	debugOpts          debugOptions
+	colorTempScheduler *colorTempScheduler
 
 	sessionActive bool
 	newSysCfg     *SysRootConfig
@@ -249,10 +250,10 @@ var _dsDefaultTemperatureManual int32
 func newManager(service *dbusutil.Service) *Manager {
 	isVM, _ := isInVM()
 	m := &Manager{
-		service:        service,
-		monitorMap:     make(map[uint32]*Monitor),
-		Brightness:     make(map[string]float64),
-		redshiftRunner: newRedshiftRunner(),
+		service:            service,
+		monitorMap:         make(map[uint32]*Monitor),
+		Brightness:         make(map[string]float64),
+		colorTempScheduler: newColorTempScheduler(),
 		unsupportGammaDrmList: []string{
 			"Loongson",
 		},
@@ -261,7 +262,7 @@ func newManager(service *dbusutil.Service) *Manager {
 	if !_greeterMode {
 		m.xsManager = xs.NewXSettings(m.service.Conn())
 	}
-	m.redshiftRunner.cb = func(value int) {
+	m.colorTempScheduler.cb = func() {
 		m.setColorTempOneShot()
 	}
 
@@ -321,6 +322,7 @@ func newManager(service *dbusutil.Service) *Manager {
 		logger.Warning(err)
 		_timeZone = "Asia/Beijing"
 	}
+	m.colorTempScheduler.setLocation(m.getColorTempLocation())
 	go func() {
 		m.listenTimezone()
 	}()
@@ -462,6 +464,8 @@ func (m *Manager) initDConfig(sysBus *dbus.Conn) {
 			m.getCurrentCustomId()
 		case DSettingsKeyRotateScreenTimeDelay:
 			m.getRotateScreenTimeDelay()
+		case DSettingsKeyColorTemperatureLocation:
+			m.colorTempScheduler.setLocation(m.getColorTempLocation())
 		default:
 			break
 		}
@@ -5,6 +5,7 @@
 package display1
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -324,3 +325,36 @@ func (m *Manager) SetCustomColorTempTimePeriod(timePeriod string) *dbus.Error {
 	}
 	return nil
 }
+
+// GetColorTemperatureSchedule 以 JSON 对象返回自动色温模式使用的位置，以及今天色温开始和结束过渡的时间
+func (m *Manager) GetColorTemperatureSchedule() (schedule string, busErr *dbus.Error) {
+	s, err := m.getColorTempSchedule()
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	return string(data), nil
+}
+
+// SetColorTemperatureLocation 手动设置自动色温模式计算日出日落使用的经纬度
+func (m *Manager) SetColorTemperatureLocation(latitude, longitude float64) *dbus.Error {
+	err := m.setColorTempLocation(latitude, longitude)
+	if err != nil {
+		logger.Warning(err)
+		return dbusutil.ToError(err)
+	}
+	return nil
+}
+
+// ResetColorTemperatureLocation 恢复使用时区的经纬度计算日出日落
+func (m *Manager) ResetColorTemperatureLocation() *dbus.Error {
+	err := m.resetColorTempLocation()
+	if err != nil {
+		logger.Warning(err)
+		return dbusutil.ToError(err)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package display1
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// 太阳高度角高于该值时为白天
+	solarElevationDay = 3.0
+	// 太阳高度角低于该值时为夜晚，中间为过渡阶段
+	solarElevationNight = -6.0
+)
+
+func degToRad(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func radToDeg(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
+
+// solarElevation 返回 t 时刻太阳在 latitude, longitude 处的高度角（度），
+// 计算方法来自 NOAA 的太阳位置计算表。
+func solarElevation(t time.Time, latitude, longitude float64) float64 {
+	t = t.UTC()
+	julianDay := float64(t.UnixNano())/float64(24*time.Hour) + 2440587.5
+	// 自 J2000.0 起的儒略世纪数
+	jc := (julianDay - 2451545) / 36525
+
+	meanLong := math.Mod(280.46646+jc*(36000.76983+jc*0.0003032), 360)
+	meanAnom := 357.52911 + jc*(35999.05029-0.0001537*jc)
+	eccent := 0.016708634 - jc*(0.000042037+0.0000001267*jc)
+	eqOfCenter := math.Sin(degToRad(meanAnom))*(1.914602-jc*(0.004817+0.000014*jc)) +
+		math.Sin(degToRad(2*meanAnom))*(0.019993-0.000101*jc) +
+		math.Sin(degToRad(3*meanAnom))*0.000289
+	trueLong := meanLong + eqOfCenter
+	omega := degToRad(125.04 - 1934.136*jc)
+	appLong := trueLong - 0.00569 - 0.00478*math.Sin(omega)
+	meanObliq := 23 + (26+(21.448-jc*(46.815+jc*(0.00059-jc*0.001813)))/60)/60
+	obliq := meanObliq + 0.00256*math.Cos(omega)
+	declination := math.Asin(math.Sin(degToRad(obliq)) * math.Sin(degToRad(appLong)))
+
+	y := math.Pow(math.Tan(degToRad(obliq/2)), 2)
+	l0 := degToRad(meanLong)
+	m := degToRad(meanAnom)
+	// 时差，单位为分钟
+	eqOfTime := 4 * radToDeg(y*math.Sin(2*l0)-2*eccent*math.Sin(m)+
+		4*eccent*y*math.Sin(m)*math.Cos(2*l0)-
+		0.5*y*y*math.Sin(4*l0)-1.25*eccent*eccent*math.Sin(2*m))
+
+	minutes := float64(t.Hour()*60+t.Minute()) + float64(t.Second())/60
+	trueSolarTime := math.Mod(minutes+eqOfTime+4*longitude, 1440)
+	if trueSolarTime < 0 {
+		trueSolarTime += 1440
+	}
+	hourAngle := trueSolarTime/4 - 180
+
+	lat := degToRad(latitude)
+	cosZenith := math.Sin(lat)*math.Sin(declination) +
+		math.Cos(lat)*math.Cos(declination)*math.Cos(degToRad(hourAngle))
+	cosZenith = math.Max(-1, math.Min(1, cosZenith))
+	return 90 - radToDeg(math.Acos(cosZenith))
+}
+
+// nightFactor 根据太阳高度角返回夜晚的程度，0 为白天，1 为夜晚，过渡阶段线性变化
+func nightFactor(elevation float64) float64 {
+	if elevation >= solarElevationDay {
+		return 0
+	}
+	if elevation <= solarElevationNight {
+		return 1
+	}
+	return (solarElevationDay - elevation) / (solarElevationDay - solarElevationNight)
+}
+
+// solarTransitions 是一天中色温开始和结束过渡的时间，unix 时间戳（秒），
+// 极昼或者极夜没有对应的过渡时为 0。
+type solarTransitions struct {
+	// 早晨开始由夜晚色温过渡到白天色温
+	DawnStart int64
+	// 早晨过渡结束
+	DawnEnd int64
+	// 傍晚开始由白天色温过渡到夜晚色温
+	DuskStart int64
+	// 傍晚过渡结束
+	DuskEnd int64
+}
+
+// getSolarTransitions 按分钟计算 day 所在日期（day 的时区）中太阳高度角穿过过渡阈值的时间
+func getSolarTransitions(day time.Time, latitude, longitude float64) solarTransitions {
+	var result solarTransitions
+	year, month, date := day.Date()
+	start := time.Date(year, month, date, 0, 0, 0, 0, day.Location())
+	end := start.AddDate(0, 0, 1)
+
+	prev := solarElevation(start, latitude, longitude)
+	for t := start.Add(time.Minute); !t.After(end); t = t.Add(time.Minute) {
+		cur := solarElevation(t, latitude, longitude)
+		switch {
+		case prev < solarElevationNight && cur >= solarElevationNight && result.DawnStart == 0:
+			result.DawnStart = t.Unix()
+		case prev < solarElevationDay && cur >= solarElevationDay && result.DawnEnd == 0:
+			result.DawnEnd = t.Unix()
+		case prev > solarElevationDay && cur <= solarElevationDay && result.DuskStart == 0:
+			result.DuskStart = t.Unix()
+		case prev > solarElevationNight && cur <= solarElevationNight && result.DuskEnd == 0:
+			result.DuskEnd = t.Unix()
+		}
+		prev = cur
+	}
+	return result
+}
@@ -0,0 +1,57 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package display1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_solarElevation(t *testing.T) {
+	// 北京，2024 年夏至正午前后太阳高度角约为 73.5 度
+	loc := time.FixedZone("CST", 8*3600)
+	noon := time.Date(2024, 6, 21, 12, 15, 0, 0, loc)
+	assert.InDelta(t, 73.5, solarElevation(noon, 39.9, 116.4), 0.5)
+
+	midnight := time.Date(2024, 6, 21, 0, 15, 0, 0, loc)
+	assert.Less(t, solarElevation(midnight, 39.9, 116.4), solarElevationNight)
+}
+
+func Test_nightFactor(t *testing.T) {
+	assert.Equal(t, 0.0, nightFactor(10))
+	assert.Equal(t, 1.0, nightFactor(-10))
+	assert.InDelta(t, 0.5, nightFactor(-1.5), 0.0001)
+}
+
+func Test_getSolarTransitions(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	day := time.Date(2024, 6, 21, 8, 0, 0, 0, loc)
+	tr := getSolarTransitions(day, 39.9, 116.4)
+
+	// 北京夏至日出约为 4:46，日落约为 19:46
+	dawnEnd := time.Unix(tr.DawnEnd, 0).In(loc)
+	duskStart := time.Unix(tr.DuskStart, 0).In(loc)
+	assert.True(t, tr.DawnStart < tr.DawnEnd && tr.DawnEnd < tr.DuskStart && tr.DuskStart < tr.DuskEnd)
+	assert.Equal(t, 5, dawnEnd.Hour())
+	assert.Equal(t, 19, duskStart.Hour())
+
+	// 北极圈内夏至为极昼，没有过渡
+	tr = getSolarTransitions(time.Date(2024, 6, 21, 12, 0, 0, 0, time.UTC), 78.2, 15.6)
+	assert.Equal(t, solarTransitions{}, tr)
+}
+
+func Test_parseColorTempLocation(t *testing.T) {
+	location, err := parseColorTempLocation("39.9, 116.4")
+	require.NoError(t, err)
+	assert.Equal(t, &colorTempLocation{Latitude: 39.9, Longitude: 116.4, Source: colorTempLocationSourceManual}, location)
+
+	for _, str := range []string{"", "39.9", "91,0", "0,181", "a,b"} {
+		_, err = parseColorTempLocation(str)
+		assert.Error(t, err, str)
+	}
+}
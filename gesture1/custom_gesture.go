@@ -0,0 +1,299 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package gesture1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/linuxdeepin/dde-daemon/common/gestureevent"
+	applicationmanager "github.com/linuxdeepin/go-dbus-factory/session/org.desktopspec.applicationmanager1"
+	"github.com/linuxdeepin/go-lib/appinfo/desktopappinfo"
+	"github.com/linuxdeepin/go-lib/dbusutil"
+)
+
+const (
+	dconfigKeyCustomGestures = "customGestures"
+
+	deviceNameTouchpad    = gestureevent.DeviceTypeTouchpad
+	deviceNameTouchscreen = gestureevent.DeviceTypeTouchscreen
+
+	// 触摸屏边缘滑动，方向为手势来自屏幕的哪条边
+	gestureNameEdge = "edge"
+)
+
+// 自定义手势的动作类型
+const (
+	customActionCommand  = "command"  // Arg 为命令行
+	customActionDesktop  = "desktop"  // Arg 为 desktop 文件
+	customActionShortcut = "shortcut" // Arg 为快捷键 id，ShortcutType 为快捷键类型
+	customActionBuiltin  = "builtin"  // Arg 为内置的手势动作名
+)
+
+const (
+	keybindingDBusServiceName = "org.deepin.dde.Keybinding1"
+	keybindingDBusPath        = "/org/deepin/dde/Keybinding1"
+
+	appManagerDBusServiceName = "org.desktopspec.ApplicationManager1"
+	appManagerDBusPath        = "/org/desktopspec/ApplicationManager1"
+)
+
+type CustomGesture struct {
+	Device       string
+	Name         string
+	Direction    string
+	Fingers      int32
+	Action       string
+	Arg          string
+	ShortcutType int32 `json:",omitempty"`
+}
+
+type CustomGestures []*CustomGesture
+
+func (g *CustomGesture) match(device string, ev EventInfo) bool {
+	return g.Device == device &&
+		g.Name == ev.Name &&
+		g.Direction == ev.Direction &&
+		g.Fingers == ev.Fingers
+}
+
+func (g *CustomGesture) String() string {
+	return fmt.Sprintf("Device=%s, Name=%s, Direction=%s, Fingers=%d, Action=%s, Arg=%s",
+		g.Device, g.Name, g.Direction, g.Fingers, g.Action, g.Arg)
+}
+
+// check 检查自定义手势是否为设备支持的事件，以及动作是否合法
+func (g *CustomGesture) check(events []gestureevent.SupportedEvent, builtinActions []string) error {
+	supported := false
+	for _, ev := range events {
+		if ev.Name != g.Name || g.Fingers < ev.MinFingers || g.Fingers > ev.MaxFingers {
+			continue
+		}
+		for _, direction := range ev.Directions {
+			if direction == g.Direction {
+				supported = true
+				break
+			}
+		}
+	}
+	if !supported {
+		return fmt.Errorf("%s gesture %s %s with %d fingers is not supported",
+			g.Device, g.Name, g.Direction, g.Fingers)
+	}
+
+	if g.Arg == "" {
+		return errors.New("action arg is empty")
+	}
+	switch g.Action {
+	case customActionCommand:
+	case customActionDesktop:
+		if !strings.HasSuffix(g.Arg, ".desktop") {
+			return fmt.Errorf("invalid desktop file %q", g.Arg)
+		}
+	case customActionShortcut:
+	case customActionBuiltin:
+		for _, name := range builtinActions {
+			if name == g.Arg {
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid built-in action %q", g.Arg)
+	default:
+		return fmt.Errorf("invalid action %q", g.Action)
+	}
+	return nil
+}
+
+// find 查找和 device、ev 对应的自定义手势
+func (gestures CustomGestures) find(device string, ev EventInfo) *CustomGesture {
+	for _, g := range gestures {
+		if g.match(device, ev) {
+			return g
+		}
+	}
+	return nil
+}
+
+// set 添加自定义手势，同一个事件已经有自定义手势时替换掉
+func (gestures CustomGestures) set(gesture *CustomGesture) CustomGestures {
+	ev := EventInfo{Name: gesture.Name, Direction: gesture.Direction, Fingers: gesture.Fingers}
+	for i, g := range gestures {
+		if g.match(gesture.Device, ev) {
+			gestures[i] = gesture
+			return gestures
+		}
+	}
+	return append(gestures, gesture)
+}
+
+func (gestures CustomGestures) delete(device string, ev EventInfo) (CustomGestures, bool) {
+	for i, g := range gestures {
+		if g.match(device, ev) {
+			return append(gestures[:i:i], gestures[i+1:]...), true
+		}
+	}
+	return gestures, false
+}
+
+func getBuiltinActionNames() []string {
+	names := make([]string, 0, len(actions))
+	for _, action := range actions {
+		if action.fn != nil {
+			names = append(names, action.Name)
+		}
+	}
+	return names
+}
+
+func (m *Manager) getCustomGesturesConfig() CustomGestures {
+	data, err := m.dconfigGesture.GetValueString(dconfigKeyCustomGestures)
+	if err != nil {
+		logger.Warning(err)
+		return nil
+	}
+	if data == "" {
+		return nil
+	}
+	var gestures CustomGestures
+	err = json.Unmarshal([]byte(data), &gestures)
+	if err != nil {
+		logger.Warning("custom gesture config unmarshal fail:", err)
+		return nil
+	}
+	return gestures
+}
+
+func (m *Manager) saveCustomGesturesConfig() error {
+	data, err := json.Marshal(m.customGestures)
+	if err != nil {
+		return err
+	}
+	return m.dconfigGesture.SetValue(dconfigKeyCustomGestures, string(data))
+}
+
+func (m *Manager) doCustomGestureAction(gesture *CustomGesture) error {
+	logger.Debug("do custom gesture action:", gesture)
+	switch gesture.Action {
+	case customActionCommand:
+		return startCommand(exec.Command("/bin/sh", "-c", gesture.Arg))
+	case customActionDesktop:
+		return m.launchDesktopFile(gesture.Arg)
+	case customActionShortcut:
+		sessionBus, err := dbus.SessionBus()
+		if err != nil {
+			return err
+		}
+		obj := sessionBus.Object(keybindingDBusServiceName, keybindingDBusPath)
+		return obj.Call(keybindingDBusServiceName+".ActivateShortcut", 0,
+			gesture.Arg, gesture.ShortcutType).Err
+	case customActionBuiltin:
+		info := &GestureInfo{ActionName: gesture.Arg}
+		return info.doAction()
+	}
+	return fmt.Errorf("invalid action %q", gesture.Action)
+}
+
+// launchDesktopFile 通过 AM 启动 desktop 文件对应的应用
+func (m *Manager) launchDesktopFile(desktop string) error {
+	obj, err := desktopappinfo.GetDBusObjectFromAppDesktop(desktop, appManagerDBusServiceName, appManagerDBusPath)
+	if err != nil {
+		return err
+	}
+	app, err := applicationmanager.NewApplication(m.service.Conn(), obj)
+	if err != nil {
+		return err
+	}
+	_, err = app.Launch(0, "", []string{}, make(map[string]dbus.Variant))
+	return err
+}
+
+func startCommand(cmd *exec.Cmd) error {
+	err := cmd.Start()
+	if err != nil {
+		return err
+	}
+	go func() {
+		_ = cmd.Wait()
+	}()
+	return nil
+}
+
+// getCustomGesture 返回事件对应的自定义手势，没有时返回 nil
+func (m *Manager) getCustomGesture(device string, ev EventInfo) *CustomGesture {
+	m.customMu.Lock()
+	defer m.customMu.Unlock()
+	return m.customGestures.find(device, ev)
+}
+
+// ListSupportedEvents 返回设备支持自定义的手势事件，deviceType 为 touchpad 或 touchscreen，返回 JSON 格式
+func (m *Manager) ListSupportedEvents(deviceType string) (events string, busErr *dbus.Error) {
+	list, err := gestureevent.List(deviceType)
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	data, err := json.Marshal(list)
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	return string(data), nil
+}
+
+// ListCustomGestures 返回 JSON 格式的自定义手势列表
+func (m *Manager) ListCustomGestures() (gestures string, busErr *dbus.Error) {
+	m.customMu.Lock()
+	defer m.customMu.Unlock()
+	list := m.customGestures
+	if list == nil {
+		list = CustomGestures{}
+	}
+	data, err := json.Marshal(list)
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	return string(data), nil
+}
+
+// SetCustomGesture 设置自定义手势，gesture 为 JSON 格式，同一个手势事件只能有一个自定义手势
+func (m *Manager) SetCustomGesture(gesture string) *dbus.Error {
+	logger.Debug("SetCustomGesture:", gesture)
+	var g CustomGesture
+	err := json.Unmarshal([]byte(gesture), &g)
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+	events, err := gestureevent.List(g.Device)
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+	err = g.check(events, getBuiltinActionNames())
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+
+	m.customMu.Lock()
+	defer m.customMu.Unlock()
+	m.customGestures = m.customGestures.set(&g)
+	return dbusutil.ToError(m.saveCustomGesturesConfig())
+}
+
+// DeleteCustomGesture 删除自定义手势，恢复内置的手势动作
+func (m *Manager) DeleteCustomGesture(deviceType, name, direction string, fingers int32) *dbus.Error {
+	m.customMu.Lock()
+	defer m.customMu.Unlock()
+	var ok bool
+	m.customGestures, ok = m.customGestures.delete(deviceType, EventInfo{
+		Name:      name,
+		Direction: direction,
+		Fingers:   fingers,
+	})
+	if !ok {
+		return dbusutil.ToError(fmt.Errorf("custom gesture %s %s with %d fingers not found", name, direction, fingers))
+	}
+	return dbusutil.ToError(m.saveCustomGesturesConfig())
+}
@@ -0,0 +1,73 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package gesture1
+
+import (
+	"testing"
+
+	"github.com/linuxdeepin/dde-daemon/common/gestureevent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomGestureCheck(t *testing.T) {
+	events := []gestureevent.SupportedEvent{
+		{Name: "swipe", Directions: []string{"up", "down"}, MinFingers: 3, MaxFingers: 5},
+		{Name: "hold", Directions: []string{"none"}, MinFingers: 3, MaxFingers: 5},
+	}
+	builtin := []string{"ToggleLaunchPad"}
+
+	valid := []*CustomGesture{
+		{Device: deviceNameTouchpad, Name: "swipe", Direction: "up", Fingers: 5, Action: customActionCommand, Arg: "deepin-terminal"},
+		{Device: deviceNameTouchpad, Name: "hold", Direction: "none", Fingers: 3, Action: customActionDesktop, Arg: "/usr/share/applications/a.desktop"},
+		{Device: deviceNameTouchpad, Name: "swipe", Direction: "down", Fingers: 4, Action: customActionShortcut, Arg: "terminal"},
+		{Device: deviceNameTouchpad, Name: "swipe", Direction: "down", Fingers: 3, Action: customActionBuiltin, Arg: "ToggleLaunchPad"},
+	}
+	for _, g := range valid {
+		assert.NoError(t, g.check(events, builtin), g.String())
+	}
+
+	invalid := []*CustomGesture{
+		{Device: deviceNameTouchpad, Name: "swipe", Direction: "left", Fingers: 3, Action: customActionCommand, Arg: "ls"},
+		{Device: deviceNameTouchpad, Name: "swipe", Direction: "up", Fingers: 2, Action: customActionCommand, Arg: "ls"},
+		{Device: deviceNameTouchpad, Name: "pinch", Direction: "in", Fingers: 3, Action: customActionCommand, Arg: "ls"},
+		{Device: deviceNameTouchpad, Name: "swipe", Direction: "up", Fingers: 3, Action: customActionCommand},
+		{Device: deviceNameTouchpad, Name: "swipe", Direction: "up", Fingers: 3, Action: customActionDesktop, Arg: "a"},
+		{Device: deviceNameTouchpad, Name: "swipe", Direction: "up", Fingers: 3, Action: customActionBuiltin, Arg: "Unknown"},
+		{Device: deviceNameTouchpad, Name: "swipe", Direction: "up", Fingers: 3, Action: "unknown", Arg: "ls"},
+	}
+	for _, g := range invalid {
+		assert.Error(t, g.check(events, builtin), g.String())
+	}
+}
+
+func TestCustomGestures(t *testing.T) {
+	var gestures CustomGestures
+	up := EventInfo{Name: "swipe", Direction: "up", Fingers: 3}
+	gestures = gestures.set(&CustomGesture{Device: deviceNameTouchpad, Name: "swipe", Direction: "up", Fingers: 3, Action: customActionCommand, Arg: "a"})
+	gestures = gestures.set(&CustomGesture{Device: deviceNameTouchscreen, Name: gestureNameEdge, Direction: "left", Fingers: 1, Action: customActionCommand, Arg: "b"})
+	gestures = gestures.set(&CustomGesture{Device: deviceNameTouchpad, Name: "swipe", Direction: "up", Fingers: 3, Action: customActionCommand, Arg: "c"})
+	require.Len(t, gestures, 2)
+
+	g := gestures.find(deviceNameTouchpad, up)
+	require.NotNil(t, g)
+	assert.Equal(t, "c", g.Arg)
+	assert.Nil(t, gestures.find(deviceNameTouchscreen, up))
+
+	gestures, ok := gestures.delete(deviceNameTouchpad, up)
+	assert.True(t, ok)
+	assert.Len(t, gestures, 1)
+	_, ok = gestures.delete(deviceNameTouchpad, up)
+	assert.False(t, ok)
+}
+
+func TestTouchEventContextLogicalEdge(t *testing.T) {
+	// 旋转 90 度
+	context := &touchEventContext{top: "left", bot: "right", left: "bot", right: "top"}
+	assert.Equal(t, "top", context.logicalEdge("left"))
+	assert.Equal(t, "bot", context.logicalEdge("right"))
+	assert.Equal(t, "left", context.logicalEdge("bot"))
+	assert.Equal(t, "right", context.logicalEdge("top"))
+}
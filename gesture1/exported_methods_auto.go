@@ -49,5 +49,26 @@ func (v *Manager) GetExportedMethods() dbusutil.ExportedMethods {
 			Fn:     v.SetGesture,
 			InArgs: []string{"name", "direction", "fingers", "action"},
 		},
+		{
+			Name:   "DeleteCustomGesture",
+			Fn:     v.DeleteCustomGesture,
+			InArgs: []string{"deviceType", "name", "direction", "fingers"},
+		},
+		{
+			Name:    "ListCustomGestures",
+			Fn:      v.ListCustomGestures,
+			OutArgs: []string{"gestures"},
+		},
+		{
+			Name:    "ListSupportedEvents",
+			Fn:      v.ListSupportedEvents,
+			InArgs:  []string{"deviceType"},
+			OutArgs: []string{"events"},
+		},
+		{
+			Name:   "SetCustomGesture",
+			Fn:     v.SetCustomGesture,
+			InArgs: []string{"gesture"},
+		},
 	}
 }
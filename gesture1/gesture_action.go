@@ -6,6 +6,7 @@ package gesture1
 
 import (
 	"fmt"
+	"github.com/godbus/dbus/v5"
 	"github.com/linuxdeepin/go-lib/gettext"
 	"os/exec"
 )
@@ -51,6 +52,8 @@ func (m *Manager) initActions() {
 		{"ToggleClipboard", gettext.Tr("Show/hide clipboard"), m.doToggleClipboard},
 		{"ToggleGrandSearch", gettext.Tr("Show/hide grand search"), m.doToggleGrandSearch},
 		{"ToggleNotifications", gettext.Tr("Show/hide notification center"), m.doToggleNotifications},
+		{"ZoomIn", gettext.Tr("Zoom in"), m.doZoomIn},
+		{"ZoomOut", gettext.Tr("Zoom out"), m.doZoomOut},
 		{"Disable", gettext.Tr("Disable"), nil},
 	}
 }
@@ -216,3 +219,21 @@ func (m *Manager) doToggleNotifications() error {
 	cmd := "dbus-send --print-reply --dest=org.deepin.dde.Osd1 /org/deepin/dde/shell/notification/center org.deepin.dde.shell.notification.center.Toggle"
 	return exec.Command("/bin/bash", "-c", cmd).Run()
 }
+
+// 调用 KWin 的全局快捷键
+func invokeKWinShortcut(name string) error {
+	sessionBus, err := dbus.SessionBus()
+	if err != nil {
+		return err
+	}
+	obj := sessionBus.Object("org.kde.kglobalaccel", "/component/kwin")
+	return obj.Call("org.kde.kglobalaccel.Component.invokeShortcut", 0, name).Err
+}
+
+func (m *Manager) doZoomIn() error {
+	return invokeKWinShortcut("view_zoom_in")
+}
+
+func (m *Manager) doZoomOut() error {
+	return invokeKWinShortcut("view_zoom_out")
+}
@@ -34,10 +34,36 @@ var gestureInfos = GestureInfos{
 	{"swipe", "left", 4, "SwitchToPreDesktop"},
 	{"swipe", "right", 4, "SwitchToNextDesktop"},
 	{"tap", "none", 4, "ToggleLaunchPad"},
+	{"pinch", "in", 3, "Disable"},
+	{"pinch", "out", 3, "Disable"},
+	{"pinch", "in", 4, "ShowMultiTask"},
+	{"pinch", "out", 4, "HideMultitask"},
+	{"rotate", "clockwise", 3, "Disable"},
+	{"rotate", "counterclockwise", 3, "Disable"},
 	{"touch right button", "down", 0, "MouseRightButtonDown"},
 	{"touch right button", "up", 0, "MouseRightButtonUp"},
 }
 
+// mergeDefaultGestureInfos 把配置中没有的默认手势加到 infos 中，用于升级后新增的手势
+func mergeDefaultGestureInfos(infos GestureInfos) GestureInfos {
+	for _, def := range gestureInfos {
+		found := false
+		for _, info := range infos {
+			if info.Name == def.Name &&
+				info.Direction == def.Direction &&
+				info.Fingers == def.Fingers {
+				found = true
+				break
+			}
+		}
+		if !found {
+			info := *def
+			infos = append(infos, &info)
+		}
+	}
+	return infos
+}
+
 func (m *Manager) GetGestureByEvent(event EventInfo) *GestureInfo {
 	for _, gesture := range m.Infos {
 		if gesture.Name == event.Name &&
@@ -0,0 +1,36 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package gesture1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeDefaultGestureInfos(t *testing.T) {
+	infos := GestureInfos{
+		{"swipe", "up", 3, "ShowDesktop"},
+	}
+	infos = mergeDefaultGestureInfos(infos)
+	require.Len(t, infos, len(gestureInfos))
+	assert.Equal(t, "ShowDesktop", infos[0].ActionName)
+
+	m := &Manager{Infos: infos}
+	info := m.GetGestureByEvent(EventInfo{Name: "rotate", Direction: "clockwise", Fingers: 3})
+	require.NotNil(t, info)
+	info.ActionName = "ZoomIn"
+	for _, def := range gestureInfos {
+		assert.NotEqual(t, "ZoomIn", def.ActionName)
+	}
+}
+
+func TestGetAvailableGesturesKey(t *testing.T) {
+	assert.Equal(t, availableGesturesWith3Fingers, getAvailableGesturesKey("swipe", 3))
+	assert.Equal(t, availableGesturesWithPinch, getAvailableGesturesKey("pinch", 4))
+	assert.Equal(t, availableGesturesWithRotate, getAvailableGesturesKey("rotate", 3))
+	assert.Equal(t, "", getAvailableGesturesKey("swipe", 5))
+}
@@ -56,6 +56,8 @@ const (
 	availableGesturesWith3Fingers  = "availableGesturesWith3Fingers"
 	availableGesturesWith4Fingers  = "availableGesturesWith4Fingers"
 	availableGesturesWithActionTap = "availableGesturesWithActionTap"
+	availableGesturesWithPinch     = "availableGesturesWithPinch"
+	availableGesturesWithRotate    = "availableGesturesWithRotate"
 )
 
 type deviceType int32 // 设备类型(触摸屏，触摸板)
@@ -99,6 +101,27 @@ type Manager struct {
 	dconfigGesture     *dconfig.DConfig
 	dconfigTouchScreen *dconfig.DConfig
 	availableGestures  map[string][]string
+
+	customMu       sync.Mutex
+	customGestures CustomGestures
+
+	// 当前的缩放、旋转手势是否转发进度，只在系统信号循环中访问
+	pinchStarted bool
+	pinchHandled bool
+
+	// nolint
+	signals *struct {
+		// 触摸板三指及以上缩放、旋转的进度，用于动画，scale 为相对开始时的缩放，angle 为累计旋转的角度，顺时针为正
+		PinchMoving struct {
+			fingers      int32
+			scale, angle float64
+		}
+
+		PinchStop struct {
+			fingers   int32
+			cancelled bool
+		}
+	}
 }
 
 func newManager(service *dbusutil.Service) (*Manager, error) {
@@ -148,7 +171,10 @@ func newManager(service *dbusutil.Service) (*Manager, error) {
 	m.availableGestures[availableGesturesWith3Fingers] = m.getAvailableGestureConfigValue(availableGesturesWith3Fingers)
 	m.availableGestures[availableGesturesWith4Fingers] = m.getAvailableGestureConfigValue(availableGesturesWith4Fingers)
 	m.availableGestures[availableGesturesWithActionTap] = m.getAvailableGestureConfigValue(availableGesturesWithActionTap)
+	m.availableGestures[availableGesturesWithPinch] = m.getAvailableGestureConfigValue(availableGesturesWithPinch)
+	m.availableGestures[availableGesturesWithRotate] = m.getAvailableGestureConfigValue(availableGesturesWithRotate)
 	m.Infos = m.getGestureConfig()
+	m.customGestures = m.getCustomGesturesConfig()
 
 	if _useWayland {
 		setLongPressEnable(m.longPressEnable.Get())
@@ -203,7 +229,7 @@ func (m *Manager) getGestureConfig() (infos GestureInfos) {
 			return
 		}
 	}
-	return
+	return mergeDefaultGestureInfos(infos)
 }
 
 func (m *Manager) saveGestureConfig() {
@@ -367,6 +393,67 @@ func (m *Manager) init() {
 	if err != nil {
 		logger.Error("connect handleTouchMovementEvent failed:", err)
 	}
+
+	m.initPinchSignals(systemConn)
+}
+
+// initPinchSignals 转发系统手势服务的缩放、旋转进度信号
+func (m *Manager) initPinchSignals(systemConn *dbus.Conn) {
+	obj := systemConn.Object(dbusServiceName, dbusServicePath)
+	for _, member := range []string{"PinchMoving", "PinchStop"} {
+		err := obj.AddMatchSignal(dbusServiceIFC, member).Err
+		if err != nil {
+			logger.Warning(err)
+		}
+	}
+
+	m.systemSigLoop.AddHandler(&dbusutil.SignalRule{
+		Name: dbusServiceIFC + ".PinchMoving",
+	}, func(sig *dbus.Signal) {
+		var fingers int32
+		var scale, angle float64
+		err := dbus.Store(sig.Body, &fingers, &scale, &angle)
+		if err != nil {
+			logger.Warning(err)
+			return
+		}
+		if !m.pinchStarted {
+			m.pinchStarted = true
+			m.pinchHandled, err = m.shouldHandleEvent(deviceTouchPad)
+			if err != nil {
+				logger.Error("shouldHandleEvent failed:", err)
+			}
+		}
+		if !m.pinchHandled {
+			return
+		}
+		err = m.service.Emit(m, "PinchMoving", fingers, scale, angle)
+		if err != nil {
+			logger.Warning(err)
+		}
+	})
+
+	m.systemSigLoop.AddHandler(&dbusutil.SignalRule{
+		Name: dbusServiceIFC + ".PinchStop",
+	}, func(sig *dbus.Signal) {
+		var fingers int32
+		var cancelled bool
+		err := dbus.Store(sig.Body, &fingers, &cancelled)
+		if err != nil {
+			logger.Warning(err)
+			return
+		}
+		handled := m.pinchHandled
+		m.pinchStarted = false
+		m.pinchHandled = false
+		if !handled {
+			return
+		}
+		err = m.service.Emit(m, "PinchStop", fingers, cancelled)
+		if err != nil {
+			logger.Warning(err)
+		}
+	})
 }
 
 func (m *Manager) shouldIgnoreGesture(info *GestureInfo) bool {
@@ -413,6 +500,13 @@ func (m *Manager) Exec(evInfo EventInfo) error {
 		}
 	}
 
+	if custom := m.getCustomGesture(deviceNameTouchpad, evInfo); custom != nil {
+		if m.shouldIgnoreGesture(&GestureInfo{Name: evInfo.Name, Direction: evInfo.Direction, Fingers: evInfo.Fingers}) {
+			return nil
+		}
+		return m.doCustomGestureAction(custom)
+	}
+
 	info := m.GetGestureByEvent(evInfo)
 	if info == nil {
 		logger.Infof("[Exec]: not found event info: %s", evInfo.toString())
@@ -536,6 +630,21 @@ type touchEventContext struct {
 	screenWidth, screenHeight uint16
 }
 
+// logicalEdge 返回 edge 在屏幕旋转后对应的边
+func (context *touchEventContext) logicalEdge(edge string) string {
+	switch edge {
+	case context.top:
+		return "top"
+	case context.bot:
+		return "bot"
+	case context.left:
+		return "left"
+	case context.right:
+		return "right"
+	}
+	return edge
+}
+
 // func getTouchScreenRotationContext return a context represents the current touchScreen's rotation, and a func to transform point
 func (m *Manager) getTouchScreenRotationContext() (context *touchEventContext, pointTransformFn func(*point), err error) {
 	monitor, rotation := m.getTouchScreenRotation()
@@ -656,6 +765,14 @@ func (m *Manager) showWidgets(show bool) error {
 // p:    该手势的终点
 func (m *Manager) handleTouchEdgeEvent(context *touchEventContext, edge string, p *point) error {
 	logger.Debugf("handleTouchEdgeEvent: context:%+v edge:%s p:%+v", *context, edge, *p)
+	custom := m.getCustomGesture(deviceNameTouchscreen, EventInfo{
+		Name:      gestureNameEdge,
+		Direction: context.logicalEdge(edge),
+		Fingers:   1,
+	})
+	if custom != nil {
+		return m.doCustomGestureAction(custom)
+	}
 	switch edge {
 	case context.left:
 		if p.X*float64(context.screenHeight) > 100 && m.oneFingerLeftEnable.Get() {
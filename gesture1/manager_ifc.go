@@ -85,30 +85,34 @@ func (m *Manager) GetGestureAvaiableActions(name string, fingers int32) (string,
 		}
 		return string(infos), nil
 	}
-	if name == "swipe" {
-		if fingers == 3 {
-			return search(availableGesturesWith3Fingers)
-		} else if fingers == 4 {
-			return search(availableGesturesWith4Fingers)
-		}
-	} else if name == "tap" {
-		return search(availableGesturesWithActionTap)
+	typ := getAvailableGesturesKey(name, fingers)
+	if typ == "" {
+		return "", nil
 	}
-	return "", nil
+	return search(typ)
 }
 
-func (m *Manager) SetGesture(name string, direction string, fingers int32, action string) *dbus.Error {
-	var typ string
-	if name == "swipe" {
+// getAvailableGesturesKey 返回手势可选动作的 dconfig 配置项，没有限制时返回空
+func getAvailableGesturesKey(name string, fingers int32) string {
+	switch name {
+	case "swipe":
 		if fingers == 3 {
-			typ = availableGesturesWith3Fingers
-
+			return availableGesturesWith3Fingers
 		} else if fingers == 4 {
-			typ = availableGesturesWith4Fingers
+			return availableGesturesWith4Fingers
 		}
-	} else if name == "tap" {
-		typ = availableGesturesWithActionTap
+	case "tap":
+		return availableGesturesWithActionTap
+	case "pinch":
+		return availableGesturesWithPinch
+	case "rotate":
+		return availableGesturesWithRotate
 	}
+	return ""
+}
+
+func (m *Manager) SetGesture(name string, direction string, fingers int32, action string) *dbus.Error {
+	typ := getAvailableGesturesKey(name, fingers)
 	if typ != "" {
 		available := m.availableGestures[typ]
 		if !strv.Strv(available).Contains(action) {
@@ -0,0 +1,206 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package housekeeping
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const trashInfoExt = ".trashinfo"
+
+// RuleResult 是一条规则的清理结果，dry-run 时为可以释放的空间
+type RuleResult struct {
+	Name  string
+	Bytes uint64
+	Files int
+}
+
+// getDiskUsage 返回删除文件后可以释放的空间，有其他硬链接时删除不会释放空间
+func getDiskUsage(info fs.FileInfo) uint64 {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return uint64(info.Size())
+	}
+	if st.Nlink > 1 && !info.IsDir() {
+		return 0
+	}
+	return uint64(st.Blocks) * 512
+}
+
+// isExpired 判断文件的访问和修改时间是否都早于 deadline
+func isExpired(info fs.FileInfo, deadline time.Time) bool {
+	latest := info.ModTime()
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		atime := time.Unix(st.Atim.Sec, st.Atim.Nsec)
+		if atime.After(latest) {
+			latest = atime
+		}
+	}
+	return latest.Before(deadline)
+}
+
+func matchPatterns(patterns []string, name string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func getDeadline(rule *cleanupRule, now time.Time) time.Time {
+	return now.AddDate(0, 0, -rule.MaxAgeDays)
+}
+
+// runRule 执行清理规则，dryRun 为 true 时只统计可以释放的空间
+func runRule(rule *cleanupRule, home string, now time.Time, dryRun bool) RuleResult {
+	result := RuleResult{Name: rule.Name}
+	switch rule.Type {
+	case ruleTypeFiles:
+		for _, p := range rule.getPaths(home) {
+			cleanFiles(rule, p, getDeadline(rule, now), dryRun, &result)
+		}
+	case ruleTypeTrash:
+		cleanTrash(getTrashDir(home), getDeadline(rule, now), dryRun, &result)
+	}
+	return result
+}
+
+// cleanFiles 清理 root 中过期的文件，不跟随符号链接，清理后删除空的子目录
+func cleanFiles(rule *cleanupRule, root string, deadline time.Time, dryRun bool, result *RuleResult) {
+	rootInfo, err := os.Lstat(root)
+	if err != nil {
+		return
+	}
+	if rootInfo.Mode().IsRegular() {
+		if matchPatterns(rule.Patterns, rootInfo.Name()) && isExpired(rootInfo, deadline) {
+			removeFile(root, rootInfo, dryRun, result)
+		}
+		return
+	}
+	if !rootInfo.IsDir() {
+		return
+	}
+
+	var dirs []string
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			logger.Debug(err)
+			return nil
+		}
+		if d.IsDir() {
+			if path != root {
+				dirs = append(dirs, path)
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !matchPatterns(rule.Patterns, d.Name()) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || !isExpired(info, deadline) {
+			return nil
+		}
+		removeFile(path, info, dryRun, result)
+		return nil
+	})
+
+	if dryRun {
+		return
+	}
+	// 从最深的目录开始删除，非空的目录会删除失败
+	for i := len(dirs) - 1; i >= 0; i-- {
+		_ = os.Remove(dirs[i])
+	}
+}
+
+func removeFile(path string, info fs.FileInfo, dryRun bool, result *RuleResult) {
+	if !dryRun {
+		err := os.Remove(path)
+		if err != nil {
+			logger.Debug(err)
+			return
+		}
+	}
+	result.Bytes += getDiskUsage(info)
+	result.Files++
+}
+
+// parseTrashInfo 返回 .trashinfo 文件中的删除时间
+func parseTrashInfo(content []byte) (time.Time, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "DeletionDate=") {
+			return time.ParseInLocation("2006-01-02T15:04:05",
+				strings.TrimPrefix(line, "DeletionDate="), time.Local)
+		}
+	}
+	return time.Time{}, errors.New("DeletionDate not found")
+}
+
+// getTreeDiskUsage 返回 path 占用的空间和文件数量
+func getTreeDiskUsage(path string) (size uint64, count int) {
+	_ = filepath.Walk(path, func(_ string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		size += getDiskUsage(info)
+		if !info.IsDir() {
+			count++
+		}
+		return nil
+	})
+	return
+}
+
+// cleanTrash 按照 freedesktop 回收站规范清理删除时间早于 deadline 的文件
+func cleanTrash(trashDir string, deadline time.Time, dryRun bool, result *RuleResult) {
+	infoDir := filepath.Join(trashDir, "info")
+	filesDir := filepath.Join(trashDir, "files")
+	entries, err := os.ReadDir(infoDir)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), trashInfoExt) {
+			continue
+		}
+		infoFile := filepath.Join(infoDir, entry.Name())
+		content, err := os.ReadFile(infoFile)
+		if err != nil {
+			continue
+		}
+		deletionDate, err := parseTrashInfo(content)
+		if err != nil || !deletionDate.Before(deadline) {
+			continue
+		}
+
+		file := filepath.Join(filesDir, strings.TrimSuffix(entry.Name(), trashInfoExt))
+		size, count := getTreeDiskUsage(file)
+		if !dryRun {
+			err = os.RemoveAll(file)
+			if err != nil {
+				logger.Debug(err)
+				continue
+			}
+			_ = os.Remove(infoFile)
+		}
+		result.Bytes += size
+		result.Files += count
+	}
+}
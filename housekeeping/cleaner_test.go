@@ -0,0 +1,130 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package housekeeping
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, filename string, size int, mtime time.Time) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0755))
+	require.NoError(t, os.WriteFile(filename, make([]byte, size), 0644))
+	require.NoError(t, os.Chtimes(filename, mtime, mtime))
+}
+
+func TestRunFilesRule(t *testing.T) {
+	home := t.TempDir()
+	now := time.Now()
+	old := now.AddDate(0, 0, -40)
+	writeFile(t, filepath.Join(home, ".cache/thumbnails/large/a.png"), 4096, old)
+	writeFile(t, filepath.Join(home, ".cache/thumbnails/large/b.png"), 4096, now)
+	writeFile(t, filepath.Join(home, ".cache/thumbnails/normal/c.png"), 4096, old)
+
+	rule := &defaultCleanupRules[0]
+	result := runRule(rule, home, now, true)
+	assert.Equal(t, 2, result.Files)
+	assert.NotZero(t, result.Bytes)
+	assert.FileExists(t, filepath.Join(home, ".cache/thumbnails/large/a.png"))
+
+	result = runRule(rule, home, now, false)
+	assert.Equal(t, 2, result.Files)
+	assert.NoFileExists(t, filepath.Join(home, ".cache/thumbnails/large/a.png"))
+	assert.FileExists(t, filepath.Join(home, ".cache/thumbnails/large/b.png"))
+	// 清理后为空的目录会被删除
+	assert.NoDirExists(t, filepath.Join(home, ".cache/thumbnails/normal"))
+	assert.DirExists(t, filepath.Join(home, ".cache/thumbnails"))
+}
+
+func TestRunFilesRulePatterns(t *testing.T) {
+	home := t.TempDir()
+	now := time.Now()
+	old := now.AddDate(0, 0, -20)
+	writeFile(t, filepath.Join(home, ".cache/deepin/app/app.log"), 10, old)
+	writeFile(t, filepath.Join(home, ".cache/deepin/app/app.log.1"), 10, old)
+	writeFile(t, filepath.Join(home, ".cache/deepin/app/data.db"), 10, old)
+	writeFile(t, filepath.Join(home, ".xsession-errors.old"), 10, old)
+
+	result := runRule(&defaultCleanupRules[2], home, now, false)
+	assert.Equal(t, 3, result.Files)
+	assert.FileExists(t, filepath.Join(home, ".cache/deepin/app/data.db"))
+	assert.NoFileExists(t, filepath.Join(home, ".xsession-errors.old"))
+}
+
+func TestRunTrashRule(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", "")
+	trash := filepath.Join(home, ".local/share/Trash")
+	now := time.Now()
+
+	writeFile(t, filepath.Join(trash, "files/old.txt"), 100, now)
+	writeFile(t, filepath.Join(trash, "files/olddir/a"), 100, now)
+	writeFile(t, filepath.Join(trash, "files/new.txt"), 100, now)
+	writeTrashInfo := func(name string, deletionDate time.Time) {
+		content := "[Trash Info]\nPath=/home/u/" + name + "\nDeletionDate=" +
+			deletionDate.Format("2006-01-02T15:04:05") + "\n"
+		require.NoError(t, os.MkdirAll(filepath.Join(trash, "info"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(trash, "info", name+".trashinfo"), []byte(content), 0644))
+	}
+	writeTrashInfo("old.txt", now.AddDate(0, 0, -31))
+	writeTrashInfo("olddir", now.AddDate(0, 0, -31))
+	writeTrashInfo("new.txt", now.AddDate(0, 0, -1))
+
+	rule := &defaultCleanupRules[1]
+	result := runRule(rule, home, now, true)
+	assert.Equal(t, 2, result.Files)
+
+	result = runRule(rule, home, now, false)
+	assert.Equal(t, 2, result.Files)
+	assert.NoFileExists(t, filepath.Join(trash, "files/old.txt"))
+	assert.NoDirExists(t, filepath.Join(trash, "files/olddir"))
+	assert.NoFileExists(t, filepath.Join(trash, "info/old.txt.trashinfo"))
+	assert.FileExists(t, filepath.Join(trash, "files/new.txt"))
+	assert.FileExists(t, filepath.Join(trash, "info/new.txt.trashinfo"))
+}
+
+func TestParseConfig(t *testing.T) {
+	mounts, err := parseMountPolicies(`[{"path": "$HOME", "thresholds": [{"minFreeMB": 500}, {"minFreePercent": 1, "autoClean": true}]}]`)
+	require.NoError(t, err)
+	require.Len(t, mounts, 1)
+	assert.Equal(t, -1, mounts[0].getTriggeredLevel(600*1024*1024, 10*1024*1024*1024))
+	assert.Equal(t, 0, mounts[0].getTriggeredLevel(400*1024*1024, 10*1024*1024*1024))
+	assert.Equal(t, 1, mounts[0].getTriggeredLevel(400*1024*1024, 100*1024*1024*1024))
+
+	_, err = parseMountPolicies(`[{"path": "/", "thresholds": [{}]}]`)
+	assert.Error(t, err)
+
+	for _, invalid := range []string{
+		`[{"name": "a", "type": "files", "paths": ["/etc"]}]`,
+		`[{"name": "a", "type": "files", "paths": ["~/../etc"]}]`,
+		`[{"name": "a", "type": "files"}]`,
+		`[{"name": "a", "type": "unknown"}]`,
+		`[{"name": "a", "type": "trash"}, {"name": "a", "type": "trash"}]`,
+	} {
+		_, err = parseCleanupRules(invalid)
+		assert.Error(t, err, invalid)
+	}
+
+	cfg := newDefaultConfig()
+	// 默认不会在用户不知情时删除文件
+	for _, mount := range cfg.mounts {
+		for _, th := range mount.Thresholds {
+			assert.False(t, th.AutoClean, mount.Path)
+		}
+	}
+	rules, err := cfg.getRules([]string{""})
+	require.NoError(t, err)
+	assert.Len(t, rules, len(defaultCleanupRules))
+	rules, err = cfg.getRules([]string{"trash"})
+	require.NoError(t, err)
+	assert.Equal(t, "trash", rules[0].Name)
+	_, err = cfg.getRules([]string{"unknown"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,267 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package housekeeping
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	dconfigAppID = "org.deepin.dde.daemon"
+	dconfigName  = "org.deepin.dde.daemon.housekeeping"
+
+	dconfigKeyCheckInterval = "checkInterval"
+	dconfigKeyMountPolicies = "mountPolicies"
+	dconfigKeyCleanupRules  = "cleanupRules"
+
+	defaultCheckInterval = time.Minute
+
+	ruleTypeFiles = "files"
+	ruleTypeTrash = "trash"
+)
+
+// threshold 是挂载点的可用空间阈值，MinFreeMB 和 MinFreePercent 满足其一即触发
+type threshold struct {
+	MinFreeMB      uint64  `json:"minFreeMB"`
+	MinFreePercent float64 `json:"minFreePercent"`
+	// 触发时先自动清理，清理后空间仍然不足时才发送通知。
+	// 默认关闭，只在用户点击通知或者调用 CleanUp 时清理
+	AutoClean bool `json:"autoClean"`
+}
+
+func (t *threshold) isTriggered(availSize, totalSize uint64) bool {
+	if t.MinFreeMB > 0 && availSize < t.MinFreeMB*1024*1024 {
+		return true
+	}
+	if t.MinFreePercent > 0 && totalSize > 0 &&
+		float64(availSize)*100/float64(totalSize) < t.MinFreePercent {
+		return true
+	}
+	return false
+}
+
+// mountPolicy 是一个挂载点的阈值，Path 可以是挂载点中的任意路径，支持环境变量，例如 $HOME
+type mountPolicy struct {
+	Path string `json:"path"`
+	// 按严重程度从低到高排列
+	Thresholds []threshold `json:"thresholds"`
+}
+
+// cleanupRule 是用户家目录中的清理规则
+type cleanupRule struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Enabled bool   `json:"enabled"`
+	// 家目录中的文件或者目录，以 ~ 开头，type 为 trash 时不需要
+	Paths []string `json:"paths"`
+	// optional, 文件名的通配符，为空时匹配所有文件
+	Patterns []string `json:"patterns"`
+	// 只清理超过这些天没有访问和修改的文件
+	MaxAgeDays int `json:"maxAgeDays"`
+}
+
+type config struct {
+	checkInterval time.Duration
+	mounts        []mountPolicy
+	rules         []cleanupRule
+}
+
+var defaultMountPolicies = []mountPolicy{
+	{
+		Path: "$HOME",
+		Thresholds: []threshold{
+			{MinFreeMB: 500},
+			{MinFreeMB: 100},
+		},
+	},
+	{
+		Path: "/tmp",
+		Thresholds: []threshold{
+			{MinFreeMB: 500},
+		},
+	},
+}
+
+var defaultCleanupRules = []cleanupRule{
+	{
+		Name:       "thumbnails",
+		Type:       ruleTypeFiles,
+		Enabled:    true,
+		Paths:      []string{"~/.cache/thumbnails"},
+		MaxAgeDays: 30,
+	},
+	{
+		Name:       "trash",
+		Type:       ruleTypeTrash,
+		Enabled:    true,
+		MaxAgeDays: 30,
+	},
+	{
+		Name:       "logs",
+		Type:       ruleTypeFiles,
+		Enabled:    true,
+		Paths:      []string{"~/.cache/deepin", "~/.local/share/xorg", "~/.xsession-errors.old"},
+		Patterns:   []string{"*.log", "*.log.*", "*.old"},
+		MaxAgeDays: 14,
+	},
+	{
+		Name:       "package-cache",
+		Type:       ruleTypeFiles,
+		Enabled:    true,
+		Paths:      []string{"~/.cache/pip", "~/.npm/_cacache", "~/.cache/yarn"},
+		MaxAgeDays: 30,
+	},
+}
+
+func newDefaultConfig() *config {
+	return &config{
+		checkInterval: defaultCheckInterval,
+		mounts:        defaultMountPolicies,
+		rules:         defaultCleanupRules,
+	}
+}
+
+func parseMountPolicies(str string) ([]mountPolicy, error) {
+	var mounts []mountPolicy
+	err := json.Unmarshal([]byte(str), &mounts)
+	if err != nil {
+		return nil, err
+	}
+	for _, mount := range mounts {
+		if mount.Path == "" {
+			return nil, errors.New("mount policy path is empty")
+		}
+		for _, t := range mount.Thresholds {
+			if t.MinFreeMB == 0 && t.MinFreePercent <= 0 {
+				return nil, fmt.Errorf("threshold of %q is empty", mount.Path)
+			}
+			if t.MinFreePercent >= 100 {
+				return nil, fmt.Errorf("invalid minFreePercent %v of %q", t.MinFreePercent, mount.Path)
+			}
+		}
+	}
+	return mounts, nil
+}
+
+func parseCleanupRules(str string) ([]cleanupRule, error) {
+	var rules []cleanupRule
+	err := json.Unmarshal([]byte(str), &rules)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]struct{})
+	for _, rule := range rules {
+		err = rule.check()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := names[rule.Name]; ok {
+			return nil, fmt.Errorf("duplicate rule %q", rule.Name)
+		}
+		names[rule.Name] = struct{}{}
+	}
+	return rules, nil
+}
+
+func (rule *cleanupRule) check() error {
+	if rule.Name == "" {
+		return errors.New("rule name is empty")
+	}
+	if rule.MaxAgeDays < 0 {
+		return fmt.Errorf("rule %q has invalid maxAgeDays", rule.Name)
+	}
+	switch rule.Type {
+	case ruleTypeTrash:
+	case ruleTypeFiles:
+		if len(rule.Paths) == 0 {
+			return fmt.Errorf("rule %q has no paths", rule.Name)
+		}
+		for _, p := range rule.Paths {
+			if !strings.HasPrefix(p, "~/") || strings.Contains(p, "..") {
+				return fmt.Errorf("rule %q path %q is not in home", rule.Name, p)
+			}
+		}
+		for _, pattern := range rule.Patterns {
+			_, err := filepath.Match(pattern, "")
+			if err != nil {
+				return fmt.Errorf("rule %q has invalid pattern %q", rule.Name, pattern)
+			}
+		}
+	default:
+		return fmt.Errorf("rule %q has unknown type %q", rule.Name, rule.Type)
+	}
+	return nil
+}
+
+// getPaths 返回规则在 home 中的绝对路径
+func (rule *cleanupRule) getPaths(home string) []string {
+	if rule.Type == ruleTypeTrash {
+		return []string{getTrashDir(home)}
+	}
+	paths := make([]string, 0, len(rule.Paths))
+	for _, p := range rule.Paths {
+		paths = append(paths, filepath.Join(home, strings.TrimPrefix(p, "~/")))
+	}
+	return paths
+}
+
+func getTrashDir(home string) string {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(home, ".local/share")
+	}
+	return filepath.Join(dataHome, "Trash")
+}
+
+// getTriggeredLevel 返回被触发的最严重的阈值序号，没有触发时返回 -1
+func (mount *mountPolicy) getTriggeredLevel(availSize, totalSize uint64) int {
+	level := -1
+	for idx := range mount.Thresholds {
+		if mount.Thresholds[idx].isTriggered(availSize, totalSize) {
+			level = idx
+		}
+	}
+	return level
+}
+
+func (cfg *config) getRules(names []string) ([]cleanupRule, error) {
+	var result []cleanupRule
+	var filtered []string
+	for _, name := range names {
+		if name != "" {
+			filtered = append(filtered, name)
+		}
+	}
+	// 未指定规则时使用所有启用的规则
+	if len(filtered) == 0 {
+		for _, rule := range cfg.rules {
+			if rule.Enabled {
+				result = append(result, rule)
+			}
+		}
+		return result, nil
+	}
+
+	for _, name := range filtered {
+		found := false
+		for _, rule := range cfg.rules {
+			if rule.Name == name {
+				result = append(result, rule)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("rule %q not found", name)
+		}
+	}
+	return result, nil
+}
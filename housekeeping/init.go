@@ -5,20 +5,10 @@
 package housekeeping
 
 import (
-	"os"
-	"time"
-
 	"github.com/godbus/dbus/v5"
 	"github.com/linuxdeepin/dde-daemon/loader"
 	notifications "github.com/linuxdeepin/go-dbus-factory/session/org.freedesktop.notifications"
-	. "github.com/linuxdeepin/go-lib/gettext"
 	"github.com/linuxdeepin/go-lib/log"
-	"github.com/linuxdeepin/go-lib/utils"
-)
-
-const (
-	// 500MB
-	fsMinLeftSpace = 1024 * 1024 * 500
 )
 
 func init() {
@@ -27,8 +17,7 @@ func init() {
 
 type Daemon struct {
 	*loader.ModuleBase
-	ticker   *time.Ticker
-	stopChan chan struct{}
+	manager *Manager
 }
 
 func NewDaemon(logger *log.Logger) *Daemon {
@@ -46,44 +35,40 @@ var (
 )
 
 func (d *Daemon) Start() error {
-	if d.stopChan != nil {
+	if d.manager != nil {
 		return nil
 	}
 
-	d.ticker = time.NewTicker(time.Minute * 1)
-	d.stopChan = make(chan struct{})
-	go func() {
-		for {
-			select {
-			case _, ok := <-d.ticker.C:
-				if !ok {
-					logger.Error("Invalid ticker event")
-					return
-				}
+	service := loader.GetService()
+	d.manager = newManager(service)
+	d.manager.init()
 
-				if !d.checkSpace("HOME", true) {
-					break
-				}
-				if !d.checkSpace("/tmp", false) {
-					break
-				}
-			case <-d.stopChan:
-				logger.Debug("Stop housekeeping")
-				if d.ticker != nil {
-					d.ticker.Stop()
-					d.ticker = nil
-				}
-				return
-			}
-		}
-	}()
+	err := service.Export(dbusPath, d.manager)
+	if err != nil {
+		return err
+	}
+	err = service.RequestName(dbusServiceName)
+	if err != nil {
+		return err
+	}
+
+	d.manager.start()
 	return nil
 }
 
 func (d *Daemon) Stop() error {
-	if d.stopChan != nil {
-		close(d.stopChan)
-		d.stopChan = nil
+	if d.manager != nil {
+		d.manager.stop()
+		service := d.manager.service
+		err := service.ReleaseName(dbusServiceName)
+		if err != nil {
+			logger.Warning(err)
+		}
+		err = service.StopExport(d.manager)
+		if err != nil {
+			logger.Warning(err)
+		}
+		d.manager = nil
 	}
 	return nil
 }
@@ -99,47 +84,3 @@ func sendNotify(icon, summary, body string) error {
 		nil, nil, -1)
 	return err
 }
-
-func sendNotify2(icon, summary, body, action, call string, timeout int32) error {
-	sessionBus, err := dbus.SessionBus()
-	if err != nil {
-		return err
-	}
-	notifier := notifications.NewNotifications(sessionBus)
-	_, err = notifier.Notify(0, "dde-control-center", 0,
-		icon, summary, body,
-		[]string{"_dbus", action},
-		map[string]dbus.Variant{
-			"x-deepin-action-_dbus":       dbus.MakeVariant(call),
-			"x-deepin-ClickToDisappear":   dbus.MakeVariant(false),
-			"x-deepin-DisappearAfterLock": dbus.MakeVariant(false),
-		}, timeout)
-	return err
-}
-
-func (d *Daemon) checkSpace(dir string, state bool) bool {
-	if state {
-		dir = os.Getenv(dir)
-	}
-	fs, err := utils.QueryFilesytemInfo(dir)
-	if err != nil {
-		logger.Error("Failed to get filesystem info for :", dir, err)
-		return false
-	}
-
-	if fs.AvailSize > fsMinLeftSpace {
-		logger.Debug("Sufficient space for:", dir)
-		return true
-	}
-	logger.Info("checkSpace fs.AvailSize(M) : ", dir, fs.AvailSize/1024/1024)
-	err = sendNotify2("dialog-warning", "",
-		Tr("Insufficient disk space, please clean up in time!"),
-		Tr("Go to clean up"),
-		"dbus-send,--type=method_call,--dest=com.deepin.defender.hmiscreen,/com/deepin/defender/hmiscreen,com.deepin.defender.hmiscreen.ShowModule,string:diskcleaner",
-		5000,
-	)
-	if err != nil {
-		logger.Warning("Failed to send notification for", dir, ":", err)
-	}
-	return false
-}
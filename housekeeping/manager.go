@@ -0,0 +1,339 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package housekeeping
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/linuxdeepin/dde-daemon/common/dconfig"
+	notifications "github.com/linuxdeepin/go-dbus-factory/session/org.freedesktop.notifications"
+	"github.com/linuxdeepin/go-lib/dbusutil"
+	. "github.com/linuxdeepin/go-lib/gettext"
+	"github.com/linuxdeepin/go-lib/utils"
+)
+
+//go:generate dbusutil-gen em -type Manager
+
+const (
+	dbusServiceName = "org.deepin.dde.Housekeeping1"
+	dbusPath        = "/org/deepin/dde/Housekeeping1"
+	dbusInterface   = dbusServiceName
+
+	actionKeyCleanUp  = "_clean"
+	actionKeyDefender = "_dbus"
+
+	defenderCleanerCmd = "dbus-send,--type=method_call,--dest=com.deepin.defender.hmiscreen,/com/deepin/defender/hmiscreen,com.deepin.defender.hmiscreen.ShowModule,string:diskcleaner"
+)
+
+type Manager struct {
+	service *dbusutil.Service
+	home    string
+	dc      *dconfig.DConfig
+
+	cfgMu sync.Mutex
+	cfg   *config
+
+	// key 为展开后的挂载点路径，value 为已经触发的最严重的阈值序号
+	levels map[string]int
+
+	notifications notifications.Notifications
+	sigLoop       *dbusutil.SignalLoop
+	notifyMu      sync.Mutex
+	notifyId      uint32
+	notifyPath    string
+
+	cleanMu sync.Mutex
+	stopCh  chan struct{}
+}
+
+func newManager(service *dbusutil.Service) *Manager {
+	return &Manager{
+		service: service,
+		home:    os.Getenv("HOME"),
+		cfg:     newDefaultConfig(),
+		levels:  make(map[string]int),
+		stopCh:  make(chan struct{}),
+	}
+}
+
+func (*Manager) GetInterfaceName() string {
+	return dbusInterface
+}
+
+func (m *Manager) init() {
+	var err error
+	m.dc, err = dconfig.NewDConfig(dconfigAppID, dconfigName, "")
+	if err != nil {
+		logger.Warning("new dconfig failed, use default config:", err)
+	} else {
+		m.loadConfig()
+		m.dc.ConnectValueChanged(func(key string) {
+			switch key {
+			case dconfigKeyCheckInterval, dconfigKeyMountPolicies, dconfigKeyCleanupRules:
+				m.loadConfig()
+			}
+		})
+	}
+
+	sessionBus := m.service.Conn()
+	m.notifications = notifications.NewNotifications(sessionBus)
+	m.sigLoop = dbusutil.NewSignalLoop(sessionBus, 10)
+	m.sigLoop.Start()
+	m.notifications.InitSignalExt(m.sigLoop, true)
+	_, err = m.notifications.ConnectActionInvoked(m.handleActionInvoked)
+	if err != nil {
+		logger.Warning(err)
+	}
+}
+
+// loadConfig 从 dconfig 读取配置，格式错误的配置项使用默认值
+func (m *Manager) loadConfig() {
+	cfg := newDefaultConfig()
+
+	interval, err := m.dc.GetValueInt64(dconfigKeyCheckInterval)
+	if err == nil && interval > 0 {
+		cfg.checkInterval = time.Duration(interval) * time.Second
+	}
+
+	str, err := m.dc.GetValueString(dconfigKeyMountPolicies)
+	if err == nil {
+		mounts, err := parseMountPolicies(str)
+		if err != nil {
+			logger.Warning("invalid mount policies:", err)
+		} else {
+			cfg.mounts = mounts
+		}
+	}
+
+	str, err = m.dc.GetValueString(dconfigKeyCleanupRules)
+	if err == nil {
+		rules, err := parseCleanupRules(str)
+		if err != nil {
+			logger.Warning("invalid cleanup rules:", err)
+		} else {
+			cfg.rules = rules
+		}
+	}
+
+	m.cfgMu.Lock()
+	m.cfg = cfg
+	m.cfgMu.Unlock()
+}
+
+func (m *Manager) getConfig() *config {
+	m.cfgMu.Lock()
+	defer m.cfgMu.Unlock()
+	return m.cfg
+}
+
+func (m *Manager) start() {
+	go func() {
+		for {
+			select {
+			case <-time.After(m.getConfig().checkInterval):
+				m.checkMounts()
+			case <-m.stopCh:
+				logger.Debug("Stop housekeeping")
+				return
+			}
+		}
+	}()
+}
+
+func (m *Manager) stop() {
+	close(m.stopCh)
+	if m.sigLoop != nil {
+		m.sigLoop.Stop()
+	}
+}
+
+func (m *Manager) checkMounts() {
+	cfg := m.getConfig()
+	for idx := range cfg.mounts {
+		m.checkMount(cfg, &cfg.mounts[idx])
+	}
+}
+
+// checkMount 在可用空间达到更严重的阈值时自动清理或者发送通知，空间恢复后重置
+func (m *Manager) checkMount(cfg *config, mount *mountPolicy) {
+	path := os.ExpandEnv(mount.Path)
+	fsInfo, err := utils.QueryFilesytemInfo(path)
+	if err != nil {
+		logger.Warning("Failed to get filesystem info for :", path, err)
+		return
+	}
+
+	level := mount.getTriggeredLevel(fsInfo.AvailSize, fsInfo.TotalSize)
+	prevLevel, ok := m.levels[path]
+	if !ok {
+		prevLevel = -1
+	}
+	m.levels[path] = level
+	if level <= prevLevel {
+		return
+	}
+	logger.Infof("low disk space on %s, avail: %dM, level: %d", path, fsInfo.AvailSize/1024/1024, level)
+
+	if mount.Thresholds[level].AutoClean {
+		results := m.cleanUpMount(cfg, path)
+		logger.Info("auto clean up results:", results)
+		fsInfo, err = utils.QueryFilesytemInfo(path)
+		if err != nil {
+			logger.Warning(err)
+			return
+		}
+		level = mount.getTriggeredLevel(fsInfo.AvailSize, fsInfo.TotalSize)
+		m.levels[path] = level
+		if level < 0 {
+			return
+		}
+	}
+	m.notifyLowSpace(path)
+}
+
+func getDevice(path string) (uint64, bool) {
+	var st syscall.Stat_t
+	err := syscall.Stat(path, &st)
+	if err != nil {
+		return 0, false
+	}
+	return uint64(st.Dev), true
+}
+
+// cleanUpMount 执行路径位于挂载点 path 所在文件系统上的规则
+func (m *Manager) cleanUpMount(cfg *config, path string) []RuleResult {
+	dev, ok := getDevice(path)
+	if !ok {
+		return nil
+	}
+	rules, _ := cfg.getRules(nil)
+	var matched []cleanupRule
+	for _, rule := range rules {
+		for _, p := range rule.getPaths(m.home) {
+			if ruleDev, ok := getDevice(p); ok && ruleDev == dev {
+				matched = append(matched, rule)
+				break
+			}
+		}
+	}
+	return m.runRules(matched, false)
+}
+
+func (m *Manager) runRules(rules []cleanupRule, dryRun bool) []RuleResult {
+	m.cleanMu.Lock()
+	defer m.cleanMu.Unlock()
+
+	now := time.Now()
+	results := make([]RuleResult, 0, len(rules))
+	for idx := range rules {
+		results = append(results, runRule(&rules[idx], m.home, now, dryRun))
+	}
+	return results
+}
+
+func (m *Manager) notifyLowSpace(path string) {
+	if m.notifications == nil {
+		return
+	}
+	id, err := m.notifications.Notify(0, "dde-control-center", 0,
+		"dialog-warning", "",
+		Tr("Insufficient disk space, please clean up in time!"),
+		[]string{actionKeyCleanUp, Tr("Clean up now"), actionKeyDefender, Tr("Go to clean up")},
+		map[string]dbus.Variant{
+			"x-deepin-action-" + actionKeyDefender: dbus.MakeVariant(defenderCleanerCmd),
+			"x-deepin-ClickToDisappear":            dbus.MakeVariant(false),
+			"x-deepin-DisappearAfterLock":          dbus.MakeVariant(false),
+		}, 5000)
+	if err != nil {
+		logger.Warning("Failed to send notification for", path, ":", err)
+		return
+	}
+	m.notifyMu.Lock()
+	m.notifyId = id
+	m.notifyPath = path
+	m.notifyMu.Unlock()
+}
+
+func (m *Manager) handleActionInvoked(id uint32, actionKey string) {
+	m.notifyMu.Lock()
+	if id != m.notifyId || actionKey != actionKeyCleanUp {
+		m.notifyMu.Unlock()
+		return
+	}
+	path := m.notifyPath
+	m.notifyMu.Unlock()
+
+	go func() {
+		var total uint64
+		for _, result := range m.cleanUpMount(m.getConfig(), path) {
+			total += result.Bytes
+		}
+		err := sendNotify("dialog-information", "",
+			fmt.Sprintf(Tr("%s of disk space has been freed"), formatBytes(total)))
+		if err != nil {
+			logger.Warning(err)
+		}
+	}()
+}
+
+func formatBytes(size uint64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	value := float64(size) / unit
+	for _, suffix := range []string{"KB", "MB", "GB"} {
+		if value < unit {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+		value /= unit
+	}
+	return fmt.Sprintf("%.1f TB", value)
+}
+
+func (m *Manager) GetRules() (rules string, busErr *dbus.Error) {
+	data, err := json.Marshal(m.getConfig().rules)
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	return string(data), nil
+}
+
+func (m *Manager) runRulesByName(names []string, dryRun bool) (string, error) {
+	rules, err := m.getConfig().getRules(names)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(m.runRules(rules, dryRun))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// DryRun 返回每条规则可以释放的空间，rules 为空时使用所有启用的规则
+func (m *Manager) DryRun(rules []string) (result string, busErr *dbus.Error) {
+	result, err := m.runRulesByName(rules, true)
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	return result, nil
+}
+
+// CleanUp 执行清理，返回每条规则释放的空间，rules 为空时使用所有启用的规则
+func (m *Manager) CleanUp(rules []string) (result string, busErr *dbus.Error) {
+	logger.Infof("dbus call CleanUp with rules %v", rules)
+	result, err := m.runRulesByName(rules, false)
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	return result, nil
+}
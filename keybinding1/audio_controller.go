@@ -246,6 +246,55 @@ func (c *AudioController) changeSinkVolume(raised bool) error {
 	return nil
 }
 
+// setSinkMute 设置默认输出设备是否静音，用于宏
+func (c *AudioController) setSinkMute(mute bool) error {
+	sink, err := c.getDefaultSink()
+	if err != nil {
+		return err
+	}
+	return sink.SetMute(0, mute)
+}
+
+// isSinkMuted 返回默认输出设备是否静音
+func (c *AudioController) isSinkMuted() (bool, error) {
+	sink, err := c.getDefaultSink()
+	if err != nil {
+		return false, err
+	}
+	return sink.Mute().Get(0)
+}
+
+// setSinkVolume 设置默认输出设备的音量，用于宏
+func (c *AudioController) setSinkVolume(v float64) error {
+	sink, err := c.getDefaultSink()
+	if err != nil {
+		return err
+	}
+
+	maxVolume, err := c.audioDaemon.MaxUIVolume().Get(0)
+	if err != nil {
+		logger.Warning(err)
+		maxVolume = volumeMax
+	}
+	if v < volumeMin {
+		v = volumeMin
+	} else if v > maxVolume {
+		v = maxVolume
+	}
+
+	mute, err := sink.Mute().Get(0)
+	if err != nil {
+		return err
+	}
+	if mute {
+		err = sink.SetMute(0, false)
+		if err != nil {
+			logger.Warning(err)
+		}
+	}
+	return sink.SetVolume(0, v, true)
+}
+
 func (c *AudioController) getDefaultSink() (audio.Sink, error) {
 	sinkPath, err := c.audioDaemon.DefaultSink().Get(0)
 	if err != nil {
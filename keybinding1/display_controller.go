@@ -124,6 +124,29 @@ func (c *DisplayController) ExecCmd(cmd ActionCmd) error {
 	return nil
 }
 
+// setBrightness 设置所有可以调节亮度的显示器的亮度，用于宏
+func (c *DisplayController) setBrightness(value float64) error {
+	brightness, err := c.display.Brightness().Get(0)
+	if err != nil {
+		return err
+	}
+	for name := range brightness {
+		canSet, err := c.display.CanSetBrightness(0, name)
+		if err != nil {
+			logger.Warning(err)
+			continue
+		}
+		if !canSet {
+			continue
+		}
+		err = c.display.SetAndSaveBrightness(0, name, value)
+		if err != nil {
+			logger.Warning(err)
+		}
+	}
+	return nil
+}
+
 func (c *DisplayController) changeBrightness(raised bool) error {
 	var osd = "BrightnessUp"
 	if !raised {
@@ -0,0 +1,251 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package keybinding
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/linuxdeepin/dde-daemon/keybinding1/shortcuts"
+	configManager "github.com/linuxdeepin/go-dbus-factory/org.desktopspec.ConfigManager"
+	"github.com/linuxdeepin/go-lib/dbusutil"
+)
+
+var errNoAvailableKeystroke = errors.New("no available keystroke")
+
+func getKeystrokesStrv(shortcut shortcuts.Shortcut) []string {
+	keystrokes := shortcut.GetKeystrokes()
+	strv := make([]string, 0, len(keystrokes))
+	for _, ks := range keystrokes {
+		strv = append(strv, ks.String())
+	}
+	return strv
+}
+
+// exportModified 导出 type0 类型中与 dconfig 默认值不同的快捷键
+func (m *Manager) exportModified(type0 int32, configMgr configManager.Manager) map[string][]string {
+	result := make(map[string][]string)
+	for _, shortcut := range m.shortcutManager.List() {
+		if shortcut.GetType() != type0 {
+			continue
+		}
+		if configMgr != nil {
+			isDefault, err := configMgr.IsDefaultValue(0, shortcut.GetId())
+			if err != nil {
+				logger.Debug(err)
+				continue
+			}
+			if isDefault {
+				continue
+			}
+		}
+		result[shortcut.GetId()] = getKeystrokesStrv(shortcut)
+	}
+	return result
+}
+
+// ExportShortcuts 导出修改过的系统和多媒体快捷键以及所有自定义快捷键，返回 JSON 格式的文档
+func (m *Manager) ExportShortcuts() (document string, busErr *dbus.Error) {
+	doc := &shortcuts.ShortcutDocument{
+		Version: shortcuts.ShortcutDocumentVersion,
+		System:  m.exportModified(shortcuts.ShortcutTypeSystem, m.shortcutSystemConfigMgr),
+		Media:   m.exportModified(shortcuts.ShortcutTypeMedia, m.shortcutMediaConfigMgr),
+	}
+	for _, shortcut := range m.shortcutManager.List() {
+		cs, ok := shortcut.(*shortcuts.CustomShortcut)
+		if !ok {
+			continue
+		}
+		doc.Custom = append(doc.Custom, &shortcuts.DocumentCustomShortcut{
+			Name:   cs.GetId(),
+			Exec:   cs.Cmd,
+			Macro:  cs.Macro,
+			Accels: getKeystrokesStrv(cs),
+		})
+	}
+	sort.Slice(doc.Custom, func(i, j int) bool {
+		return doc.Custom[i].Name < doc.Custom[j].Name
+	})
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	return string(data), nil
+}
+
+// lookupImportConflict 通过 LookupConflictingShortcut 查找按键是否被其他快捷键占用
+func (m *Manager) lookupImportConflict(entry *shortcuts.DocumentEntry, ks *shortcuts.Keystroke) (*shortcuts.ImportConflict, error) {
+	detail, busErr := m.LookupConflictingShortcut(ks.String())
+	if busErr != nil {
+		return nil, busErr
+	}
+	if detail == "" {
+		return nil, nil
+	}
+	var conflict struct {
+		Id   string
+		Type int32
+	}
+	err := json.Unmarshal([]byte(detail), &conflict)
+	if err != nil {
+		return nil, err
+	}
+	if conflict.Id == entry.Id && conflict.Type == entry.Type {
+		return nil, nil
+	}
+	return &shortcuts.ImportConflict{
+		Id:           entry.Id,
+		Type:         entry.Type,
+		Keystroke:    ks.String(),
+		ConflictId:   conflict.Id,
+		ConflictType: conflict.Type,
+	}, nil
+}
+
+// checkImport 检查文档中的快捷键，返回每个快捷键可以使用的按键
+func (m *Manager) checkImport(entries []*shortcuts.DocumentEntry, result *shortcuts.ImportResult) map[*shortcuts.DocumentEntry][]*shortcuts.Keystroke {
+	type conflictKey struct {
+		id        string
+		type0     int32
+		keystroke string
+	}
+	conflicted := make(map[conflictKey]bool)
+	for _, conflict := range shortcuts.FindDuplicateKeystrokes(entries) {
+		result.Conflicts = append(result.Conflicts, conflict)
+		conflicted[conflictKey{conflict.Id, conflict.Type, conflict.Keystroke}] = true
+	}
+
+	available := make(map[*shortcuts.DocumentEntry][]*shortcuts.Keystroke, len(entries))
+	for _, entry := range entries {
+		if entry.Type != shortcuts.ShortcutTypeCustom {
+			shortcut := m.shortcutManager.GetByIdType(entry.Id, entry.Type)
+			if shortcut == nil {
+				result.AddError(entry.Id, entry.Type, ErrShortcutNotFound{entry.Id, entry.Type})
+				continue
+			}
+			if !shortcut.GetKeystrokesModifiable() {
+				result.AddError(entry.Id, entry.Type, errShortcutKeystrokesUnmodifiable)
+				continue
+			}
+		}
+
+		cmd := ""
+		if entry.Custom != nil {
+			cmd = entry.Custom.Exec
+		}
+		keystrokes := make([]*shortcuts.Keystroke, 0, len(entry.Keystrokes))
+		for _, ks := range entry.Keystrokes {
+			err := checkKeymapSupported(entry.Type, ks, cmd)
+			if err != nil {
+				result.AddError(entry.Id, entry.Type, err)
+				continue
+			}
+			if conflicted[conflictKey{entry.Id, entry.Type, ks.String()}] {
+				continue
+			}
+			conflict, err := m.lookupImportConflict(entry, ks)
+			if err != nil {
+				result.AddError(entry.Id, entry.Type, err)
+				continue
+			}
+			if conflict != nil {
+				result.Conflicts = append(result.Conflicts, conflict)
+				continue
+			}
+			keystrokes = append(keystrokes, ks)
+		}
+		// 系统和媒体快捷键的按键全部冲突时跳过，避免清空原有按键，冲突已记录在结果中
+		if entry.Type != shortcuts.ShortcutTypeCustom && len(entry.Keystrokes) > 0 && len(keystrokes) == 0 {
+			logger.Debugf("skip importing shortcut %s, no available keystroke", entry.Id)
+			continue
+		}
+		available[entry] = keystrokes
+	}
+	return available
+}
+
+func (m *Manager) importShortcut(entry *shortcuts.DocumentEntry, keystrokes []*shortcuts.Keystroke) error {
+	if entry.Custom != nil {
+		return m.importCustomShortcut(entry.Custom, keystrokes)
+	}
+
+	shortcut := m.shortcutManager.GetByIdType(entry.Id, entry.Type)
+	if shortcut == nil {
+		return ErrShortcutNotFound{entry.Id, entry.Type}
+	}
+	m.shortcutManager.ModifyShortcutKeystrokes(shortcut, keystrokes)
+	err := shortcut.SaveKeystrokes()
+	if err != nil {
+		return err
+	}
+	if shortcut.ShouldEmitSignalChanged() {
+		m.emitShortcutSignal(shortcutSignalChanged, shortcut)
+	}
+	return nil
+}
+
+func (m *Manager) importCustomShortcut(cs *shortcuts.DocumentCustomShortcut, keystrokes []*shortcuts.Keystroke) error {
+	keystroke := ""
+	if len(keystrokes) > 0 {
+		keystroke = keystrokes[0].String()
+	}
+
+	var busErr *dbus.Error
+	exist := m.shortcutManager.GetByIdType(cs.Name, shortcuts.ShortcutTypeCustom)
+	if exist != nil {
+		busErr = m.ModifyCustomShortcut(cs.Name, cs.Name, cs.Exec, keystroke)
+	} else {
+		if keystroke == "" {
+			return errNoAvailableKeystroke
+		}
+		_, _, busErr = m.AddCustomShortcut(cs.Name, cs.Exec, keystroke)
+	}
+	if busErr != nil {
+		return busErr
+	}
+	if exist != nil || cs.Macro != "" {
+		busErr = m.SetCustomShortcutMacro(cs.Name, cs.Macro)
+		if busErr != nil {
+			return busErr
+		}
+	}
+	return nil
+}
+
+// ImportShortcuts 导入 ExportShortcuts 导出的文档，被其他快捷键占用的按键不会导入，
+// dryRun 为 true 时只检查不修改，返回 JSON 格式的冲突和错误
+func (m *Manager) ImportShortcuts(document string, dryRun bool) (result string, busErr *dbus.Error) {
+	logger.Debug("ImportShortcuts, dry run:", dryRun)
+	doc, err := shortcuts.ParseShortcutDocument(document)
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+
+	var importResult shortcuts.ImportResult
+	entries := doc.GetEntries(&importResult)
+	available := m.checkImport(entries, &importResult)
+	if !dryRun {
+		for _, entry := range entries {
+			keystrokes, ok := available[entry]
+			if !ok {
+				continue
+			}
+			err = m.importShortcut(entry, keystrokes)
+			if err != nil {
+				logger.Warningf("failed to import shortcut %s: %v", entry.Id, err)
+				importResult.AddError(entry.Id, entry.Type, err)
+			}
+		}
+	}
+
+	data, err := json.Marshal(&importResult)
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	return string(data), nil
+}
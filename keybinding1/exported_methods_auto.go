@@ -8,12 +8,22 @@ import (
 
 func (v *Manager) GetExportedMethods() dbusutil.ExportedMethods {
 	return dbusutil.ExportedMethods{
+		{
+			Name:   "ActivateShortcut",
+			Fn:     v.ActivateShortcut,
+			InArgs: []string{"id", "type0"},
+		},
 		{
 			Name:    "Add",
 			Fn:      v.Add,
 			InArgs:  []string{"name", "action", "keystroke"},
 			OutArgs: []string{"ret0", "ret1"},
 		},
+		{
+			Name:   "AddProfile",
+			Fn:     v.AddProfile,
+			InArgs: []string{"profile"},
+		},
 		{
 			Name:    "AddCustomShortcut",
 			Fn:      v.AddCustomShortcut,
@@ -46,6 +56,11 @@ func (v *Manager) GetExportedMethods() dbusutil.ExportedMethods {
 			Fn:     v.DeleteCustomShortcut,
 			InArgs: []string{"id"},
 		},
+		{
+			Name:   "DeleteProfile",
+			Fn:     v.DeleteProfile,
+			InArgs: []string{"name"},
+		},
 		{
 			Name:   "DeleteShortcutKeystroke",
 			Fn:     v.DeleteShortcutKeystroke,
@@ -61,6 +76,11 @@ func (v *Manager) GetExportedMethods() dbusutil.ExportedMethods {
 			Fn:     v.EnableSystemShortcut,
 			InArgs: []string{"shortcuts", "enabled", "isPersistent"},
 		},
+		{
+			Name:    "ExportShortcuts",
+			Fn:      v.ExportShortcuts,
+			OutArgs: []string{"document"},
+		},
 		{
 			Name:    "GetCapsLockState",
 			Fn:      v.GetCapsLockState,
@@ -76,6 +96,12 @@ func (v *Manager) GetExportedMethods() dbusutil.ExportedMethods {
 			Name: "GrabScreen",
 			Fn:   v.GrabScreen,
 		},
+		{
+			Name:    "ImportShortcuts",
+			Fn:      v.ImportShortcuts,
+			InArgs:  []string{"document", "dryRun"},
+			OutArgs: []string{"result"},
+		},
 		{
 			Name:    "List",
 			Fn:      v.List,
@@ -86,6 +112,11 @@ func (v *Manager) GetExportedMethods() dbusutil.ExportedMethods {
 			Fn:      v.ListAllShortcuts,
 			OutArgs: []string{"shortcuts"},
 		},
+		{
+			Name:    "ListProfiles",
+			Fn:      v.ListProfiles,
+			OutArgs: []string{"profiles"},
+		},
 		{
 			Name:    "ListShortcutsByType",
 			Fn:      v.ListShortcutsByType,
@@ -134,6 +165,11 @@ func (v *Manager) GetExportedMethods() dbusutil.ExportedMethods {
 			Fn:     v.SetCapsLockState,
 			InArgs: []string{"state"},
 		},
+		{
+			Name:   "SetCustomShortcutMacro",
+			Fn:     v.SetCustomShortcutMacro,
+			InArgs: []string{"id", "macro"},
+		},
 		{
 			Name:   "SetNumLockState",
 			Fn:     v.SetNumLockState,
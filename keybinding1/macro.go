@@ -0,0 +1,195 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package keybinding
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	dbus "github.com/godbus/dbus/v5"
+	"github.com/linuxdeepin/dde-daemon/keybinding1/shortcuts"
+	power "github.com/linuxdeepin/go-dbus-factory/system/org.deepin.dde.power1"
+	"github.com/linuxdeepin/go-lib/dbusutil"
+)
+
+var errControllerNotFound = errors.New("controller not found")
+
+// getControllerByCmd 返回执行控制器命令 cmd 的控制器，控制器没有初始化时返回 nil
+func (m *Manager) getControllerByCmd(cmd shortcuts.ActionCmd) Controller {
+	switch {
+	case cmd <= shortcuts.AudioSourceMuteToggle:
+		if m.audioController != nil {
+			return m.audioController
+		}
+	case cmd <= shortcuts.MediaPlayerRepeat:
+		if m.mediaPlayerController != nil {
+			return m.mediaPlayerController
+		}
+	case cmd <= shortcuts.AdjustBrightnessSwitch:
+		if m.displayController != nil {
+			return m.displayController
+		}
+	case cmd <= shortcuts.KbdLightBrightnessDown:
+		if m.kbdLightController != nil {
+			return m.kbdLightController
+		}
+	default:
+		if m.touchPadController != nil {
+			return m.touchPadController
+		}
+	}
+	return nil
+}
+
+func (m *Manager) checkMacroCondition(step *shortcuts.MacroStep) bool {
+	if step.Condition == "" {
+		return true
+	}
+	name, negated := step.ParseCondition()
+	var result bool
+	switch name {
+	case shortcuts.MacroConditionWayland:
+		result = _useWayland
+	case shortcuts.MacroConditionX11:
+		result = !_useWayland
+	case shortcuts.MacroConditionOnBattery:
+		sysBus, err := dbus.SystemBus()
+		if err != nil {
+			logger.Warning(err)
+			return false
+		}
+		result, err = power.NewPower(sysBus).OnBattery().Get(0)
+		if err != nil {
+			logger.Warning("failed to get OnBattery:", err)
+			return false
+		}
+	case shortcuts.MacroConditionAudioMuted:
+		if m.audioController == nil {
+			return false
+		}
+		var err error
+		result, err = m.audioController.isSinkMuted()
+		if err != nil {
+			logger.Warning(err)
+			return false
+		}
+	}
+	return result != negated
+}
+
+func (m *Manager) execMacroStep(step *shortcuts.MacroStep) error {
+	switch step.Action {
+	case shortcuts.MacroActionExec:
+		return m.execCmd(step.Arg, true)
+	case shortcuts.MacroActionDesktopFile:
+		return m.runDesktopFile(step.Arg)
+	case shortcuts.MacroActionMimeType:
+		return m.execCmd(queryCommandByMime(step.Arg), true)
+	case shortcuts.MacroActionCtrl:
+		cmd, _ := shortcuts.GetActionCmdByName(step.Arg)
+		c := m.getControllerByCmd(cmd)
+		if c == nil {
+			return errControllerNotFound
+		}
+		return c.ExecCmd(cmd)
+	case shortcuts.MacroActionMute:
+		if m.audioController == nil {
+			return errControllerNotFound
+		}
+		mute, _ := strconv.ParseBool(step.Arg)
+		return m.audioController.setSinkMute(mute)
+	case shortcuts.MacroActionVolume:
+		if m.audioController == nil {
+			return errControllerNotFound
+		}
+		v, _ := step.GetPercent()
+		return m.audioController.setSinkVolume(v)
+	case shortcuts.MacroActionBrightness:
+		if m.displayController == nil {
+			return errControllerNotFound
+		}
+		v, _ := step.GetPercent()
+		return m.displayController.setBrightness(v)
+	case shortcuts.MacroActionEnterMode:
+		if _useWayland {
+			return errKeymapNotSupported
+		}
+		m.shortcutManager.EnterMode(step.Arg)
+		return nil
+	}
+	return errors.New("unknown macro action " + step.Action)
+}
+
+// runMacro 依次执行宏中的每一步，某一步失败不影响后面的步骤
+func (m *Manager) runMacro(steps []*shortcuts.MacroStep) {
+	for i, step := range steps {
+		if step.Delay > 0 {
+			time.Sleep(time.Duration(step.Delay) * time.Millisecond)
+		}
+		if !m.checkMacroCondition(step) {
+			logger.Debugf("skip macro step %d, condition: %s", i, step.Condition)
+			continue
+		}
+		err := m.execMacroStep(step)
+		if err != nil {
+			logger.Warningf("failed to exec macro step %d %+v: %v", i, step, err)
+		}
+	}
+}
+
+// runCustomShortcutMacro 在 Wayland 中执行自定义快捷键的宏，id 为 KWin 中的快捷键 id
+func (m *Manager) runCustomShortcutMacro(wlname string) bool {
+	id := strings.TrimSuffix(wlname, "-cs")
+	if id == wlname {
+		return false
+	}
+	shortcut := m.shortcutManager.GetByIdType(id, shortcuts.ShortcutTypeCustom)
+	if shortcut == nil {
+		return false
+	}
+	action := shortcut.GetAction()
+	steps, ok := action.Arg.([]*shortcuts.MacroStep)
+	if action.Type != shortcuts.ActionTypeMacro || !ok {
+		return false
+	}
+	go m.runMacro(steps)
+	return true
+}
+
+// SetCustomShortcutMacro 设置自定义快捷键的宏，macro 为 JSON 格式，为空时清除宏，执行原来的命令
+func (m *Manager) SetCustomShortcutMacro(id, macro string) *dbus.Error {
+	logger.Debugf("SetCustomShortcutMacro id: %q, macro: %q", id, macro)
+	const ty = shortcuts.ShortcutTypeCustom
+	shortcut := m.shortcutManager.GetByIdType(id, ty)
+	if shortcut == nil {
+		return dbusutil.ToError(ErrShortcutNotFound{id, ty})
+	}
+	customShortcut, ok := shortcut.(*shortcuts.CustomShortcut)
+	if !ok {
+		return dbusutil.ToError(errTypeAssertionFail)
+	}
+
+	if macro != "" {
+		steps, err := shortcuts.ParseMacro(macro)
+		if err != nil {
+			return dbusutil.ToError(err)
+		}
+		for _, step := range steps {
+			if step.Action == shortcuts.MacroActionEnterMode && _useWayland {
+				return dbusutil.ToError(errKeymapNotSupported)
+			}
+		}
+	}
+
+	customShortcut.Macro = macro
+	err := customShortcut.Save()
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+	m.emitShortcutSignal(shortcutSignalChanged, shortcut)
+	return nil
+}
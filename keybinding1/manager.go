@@ -18,6 +18,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	dbus "github.com/godbus/dbus/v5"
@@ -157,6 +158,13 @@ type Manager struct {
 	delayUpdateRfTimer   *time.Timer
 	grabScreenKeystroke  *shortcuts.Keystroke
 
+	// 应用的快捷键配置
+	profiles         []*shortcuts.Profile
+	activeProfile    *shortcuts.Profile
+	activeAppIds     []string
+	profilesMu       sync.Mutex
+	activeWindowConn *x.Conn
+
 	// for switch kbd layout
 	switchKbdLayoutState SKLState
 	sklWaitQuit          chan int
@@ -338,6 +346,9 @@ func (m *Manager) init() {
 	m.customShortcutManager = shortcuts.NewCustomShortcutManager(customConfigFilePath)
 	m.shortcutManager.AddCustom(m.customShortcutManager, m.wm)
 
+	m.loadProfiles()
+	m.listenActiveWindow()
+
 	// init controllers
 	m.backlightHelper = backlight.NewBacklight(sysBus)
 	m.audioController = NewAudioController(sessionBus, m.backlightHelper)
@@ -667,12 +678,18 @@ func (m *Manager) listenGlobalAccel(sessionBus *dbus.Conn) error {
 				logger.Warningf("shortcut id: %s is disabled", shortId)
 				return
 			}
+			if shortId != "" && m.isDisabledByProfile(shortId) {
+				logger.Debugf("shortcut id: %s is disabled by profile", shortId)
+				return
+			}
 			ok := strings.Compare(string("kwin"), m.shortcutKey)
 			if ok == 0 {
 				logger.Debug("[global key] get accel sig.Body[1]", m.shortcutKeyCmd)
 				if m.shortcutKeyCmd == "" {
 					// + 把响应一次的逻辑放到协程外执行，防止协程响应延迟
 					m.handleKeyEventByWayland(waylandMediaIdMap[m.shortcutKeyCmd])
+				} else if m.runCustomShortcutMacro(m.shortcutKeyCmd) {
+					logger.Debug("run macro of custom shortcut", m.shortcutKeyCmd)
 				} else {
 					m.shortcutCmd = shortcuts.GetSystemActionCmd(kwinSysActionCmdMap[m.shortcutKeyCmd])
 					if m.shortcutCmd == "" {
@@ -1157,6 +1174,11 @@ func (m *Manager) destroy() {
 		m.shortcutManager = nil
 	}
 
+	if m.activeWindowConn != nil {
+		m.activeWindowConn.Close()
+		m.activeWindowConn = nil
+	}
+
 	if m.audioController != nil {
 		m.audioController.Destroy()
 		m.audioController = nil
@@ -42,6 +42,26 @@ func (m *Manager) initHandlers() {
 		}
 	}
 
+	m.handlers[ActionTypeEnterMode] = func(ev *KeyEvent) {
+		action := ev.Shortcut.GetAction()
+		mode, ok := action.Arg.(string)
+		if !ok {
+			logger.Warning(ErrTypeAssertionFail)
+			return
+		}
+		m.shortcutManager.EnterMode(mode)
+	}
+
+	m.handlers[ActionTypeMacro] = func(ev *KeyEvent) {
+		action := ev.Shortcut.GetAction()
+		steps, ok := action.Arg.([]*MacroStep)
+		if !ok {
+			logger.Warning(ErrTypeAssertionFail)
+			return
+		}
+		go m.runMacro(steps)
+	}
+
 	m.handlers[ActionTypeExecCmd] = func(ev *KeyEvent) {
 		action := ev.Shortcut.GetAction()
 		arg, ok := action.Arg.(*ActionExecCmdArg)
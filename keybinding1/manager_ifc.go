@@ -45,6 +45,18 @@ var errTypeAssertionFail = errors.New("type assertion failed")
 var errShortcutKeystrokesUnmodifiable = errors.New("keystrokes of this shortcut is unmodifiable")
 var errKeystrokeUsed = errors.New("keystroke had been used")
 var errNameUsed = errors.New("name had been used")
+var errKeymapNotSupported = errors.New("key sequence and keymap mode are only supported by custom shortcuts on X11")
+
+// checkKeymapSupported 多步组合键和按键模式只支持 X11 中的自定义快捷键
+func checkKeymapSupported(type0 int32, ks *shortcuts.Keystroke, cmd string) error {
+	if (ks == nil || !ks.IsKeymapBinding()) && !shortcuts.IsEnterModeCmd(cmd) {
+		return nil
+	}
+	if _useWayland || type0 != shortcuts.ShortcutTypeCustom {
+		return errKeymapNotSupported
+	}
+	return nil
+}
 
 func (*Manager) GetInterfaceName() string {
 	return dbusInterface
@@ -224,6 +236,13 @@ func (m *Manager) AddCustomShortcut(name, action, keystroke string) (id string,
 		return
 	}
 
+	err = checkKeymapSupported(shortcuts.ShortcutTypeCustom, ks, action)
+	if err != nil {
+		logger.Warning(err)
+		busErr = dbusutil.ToError(err)
+		return
+	}
+
 	exist := m.shortcutManager.GetByIdType(name, shortcuts.ShortcutTypeCustom)
 	if exist != nil {
 		err = errNameUsed
@@ -385,6 +404,10 @@ func (m *Manager) ModifyCustomShortcut(id, name, cmd, keystroke string) *dbus.Er
 		if err != nil {
 			return dbusutil.ToError(err)
 		}
+		err = checkKeymapSupported(ty, ks, cmd)
+		if err != nil {
+			return dbusutil.ToError(err)
+		}
 		// check conflicting
 		conflictKeystroke, err := m.shortcutManager.FindConflictingKeystroke(ks)
 		if err != nil {
@@ -432,6 +455,11 @@ func (m *Manager) AddShortcutKeystroke(id string, type0 int32, keystroke string)
 	}
 	logger.Debug("keystroke:", ks.DebugString())
 
+	err = checkKeymapSupported(type0, ks, "")
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+
 	if type0 == shortcuts.ShortcutTypeWM && ks.Mods == 0 {
 		keyLower := strings.ToLower(ks.Keystr)
 		if keyLower == "super_l" || keyLower == "super_r" {
@@ -500,6 +528,17 @@ func (m *Manager) GetShortcut(id string, type0 int32) (shortcut string, busErr *
 	return detail, nil
 }
 
+// ActivateShortcut 执行快捷键的动作，和按下快捷键的效果相同，用于手势等其他模块触发快捷键
+func (m *Manager) ActivateShortcut(id string, type0 int32) *dbus.Error {
+	logger.Debugf("ActivateShortcut id: %q, type: %d", id, type0)
+	shortcut := m.shortcutManager.GetByIdType(id, type0)
+	if shortcut == nil {
+		return dbusutil.ToError(ErrShortcutNotFound{id, type0})
+	}
+	m.handleKeyEvent(&shortcuts.KeyEvent{Shortcut: shortcut})
+	return nil
+}
+
 func (m *Manager) SelectKeystroke() *dbus.Error {
 	logger.Debug("SelectKeystroke")
 	err := m.selectKeystroke()
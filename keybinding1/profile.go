@@ -0,0 +1,291 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package keybinding
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/linuxdeepin/dde-daemon/keybinding1/shortcuts"
+	kwayland "github.com/linuxdeepin/go-dbus-factory/session/org.deepin.dde.kwayland1"
+	"github.com/linuxdeepin/go-lib/dbusutil"
+	"github.com/linuxdeepin/go-lib/xdg/basedir"
+	x "github.com/linuxdeepin/go-x11-client"
+	"github.com/linuxdeepin/go-x11-client/util/wm/ewmh"
+	"github.com/linuxdeepin/go-x11-client/util/wm/icccm"
+)
+
+const profileConfigFile = "deepin/dde-daemon/keybinding/profiles.json"
+
+var (
+	errProfileNotFound       = errors.New("profile not found")
+	errRemapNotSupportedInWL = errors.New("remapping shortcuts in profile is not supported on wayland")
+)
+
+func getProfileConfigFile() string {
+	return filepath.Join(basedir.GetUserConfigDir(), profileConfigFile)
+}
+
+func (m *Manager) loadProfiles() {
+	data, err := os.ReadFile(getProfileConfigFile())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warning(err)
+		}
+		return
+	}
+	var profiles []*shortcuts.Profile
+	err = json.Unmarshal(data, &profiles)
+	if err != nil {
+		logger.Warning("invalid shortcut profiles:", err)
+		return
+	}
+	for _, profile := range profiles {
+		err = profile.Check()
+		if err != nil {
+			logger.Warning("invalid shortcut profile:", err)
+			continue
+		}
+		if _useWayland && len(profile.Remap) > 0 {
+			// 保留配置以便在 X11 中使用，Wayland 中只有禁用生效
+			logger.Warningf("ignore remapped shortcuts of profile %q on wayland", profile.Name)
+		}
+		m.profiles = append(m.profiles, profile)
+	}
+}
+
+func (m *Manager) saveProfilesLocked() error {
+	data, err := json.Marshal(m.profiles)
+	if err != nil {
+		return err
+	}
+	filename := getProfileConfigFile()
+	err = os.MkdirAll(filepath.Dir(filename), 0755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// updateActiveProfileLocked 根据焦点应用切换生效的快捷键配置
+func (m *Manager) updateActiveProfileLocked() {
+	var profile *shortcuts.Profile
+	for _, p := range m.profiles {
+		if p.Match(m.activeAppIds...) {
+			profile = p
+			break
+		}
+	}
+	if profile == m.activeProfile {
+		return
+	}
+	m.activeProfile = profile
+	if profile != nil {
+		logger.Debugf("active apps: %v, profile: %s", m.activeAppIds, profile.Name)
+	}
+	// Wayland 中的快捷键由 KWin 处理，只在响应时检查是否被禁用
+	if !_useWayland {
+		m.shortcutManager.ApplyProfile(profile)
+	}
+}
+
+func (m *Manager) handleActiveAppChanged(appIds []string) {
+	m.profilesMu.Lock()
+	m.activeAppIds = appIds
+	m.updateActiveProfileLocked()
+	m.profilesMu.Unlock()
+}
+
+// isDisabledByProfile 判断系统快捷键是否被焦点应用的配置禁用
+func (m *Manager) isDisabledByProfile(id string) bool {
+	m.profilesMu.Lock()
+	defer m.profilesMu.Unlock()
+	return m.activeProfile != nil && m.activeProfile.IsDisabled(id)
+}
+
+func (m *Manager) listenActiveWindow() {
+	if _useWayland {
+		m.listenActiveWindowWayland()
+	} else {
+		m.listenActiveWindowX()
+	}
+}
+
+// listenActiveWindowX 监听根窗口的 _NET_ACTIVE_WINDOW 属性变化，
+// 使用单独的连接，避免影响 EventLoop 中的按键事件
+func (m *Manager) listenActiveWindowX() {
+	conn, err := x.NewConn()
+	if err != nil {
+		logger.Warning(err)
+		return
+	}
+	atom, err := conn.GetAtom("_NET_ACTIVE_WINDOW")
+	if err != nil {
+		logger.Warning(err)
+		conn.Close()
+		return
+	}
+	rootWin := conn.GetDefaultScreen().Root
+	err = x.ChangeWindowAttributesChecked(conn, rootWin, x.CWEventMask,
+		[]uint32{x.EventMaskPropertyChange}).Check(conn)
+	if err != nil {
+		logger.Warning(err)
+		conn.Close()
+		return
+	}
+	m.activeWindowConn = conn
+	m.handleActiveAppChanged(getActiveAppIdsX(conn))
+
+	eventChan := make(chan x.GenericEvent, 50)
+	conn.AddEventChan(eventChan)
+	go func() {
+		for ev := range eventChan {
+			if ev.GetEventCode() != x.PropertyNotifyEventCode {
+				continue
+			}
+			event, _ := x.NewPropertyNotifyEvent(ev)
+			if event.Window == rootWin && event.Atom == atom {
+				m.handleActiveAppChanged(getActiveAppIdsX(conn))
+			}
+		}
+	}()
+}
+
+// getActiveAppIdsX 返回焦点窗口 WM_CLASS 的 instance 和 class
+func getActiveAppIdsX(conn *x.Conn) []string {
+	activeWin, err := ewmh.GetActiveWindow(conn).Reply(conn)
+	if err != nil || activeWin == 0 {
+		return nil
+	}
+	wmClass, err := icccm.GetWMClass(conn, activeWin).Reply(conn)
+	if err != nil {
+		logger.Debugf("get WM_CLASS of window %d failed: %v", activeWin, err)
+		return nil
+	}
+	return []string{wmClass.Instance, wmClass.Class}
+}
+
+// listenActiveWindowWayland 通过 kwayland 监听焦点窗口变化
+func (m *Manager) listenActiveWindowWayland() {
+	sessionBus := m.service.Conn()
+	wm := kwayland.NewWindowManager(sessionBus)
+	wm.InitSignalExt(m.sessionSigLoop, true)
+
+	handle := func() {
+		winId, err := wm.ActiveWindow(0)
+		if err != nil || winId == 0 {
+			m.handleActiveAppChanged(nil)
+			return
+		}
+		win, err := kwayland.NewWindow(sessionBus,
+			dbus.ObjectPath(fmt.Sprintf("/org/deepin/dde/KWayland1/PlasmaWindow_%v", winId)))
+		if err != nil {
+			logger.Warning(err)
+			return
+		}
+		appId, err := win.AppId(0)
+		if err != nil {
+			logger.Debugf("get app id of window %d failed: %v", winId, err)
+			return
+		}
+		m.handleActiveAppChanged([]string{appId})
+	}
+
+	_, err := wm.ConnectActiveWindowChanged(handle)
+	if err != nil {
+		logger.Warning(err)
+		return
+	}
+	handle()
+}
+
+func (m *Manager) checkProfileShortcuts(profile *shortcuts.Profile) error {
+	for _, id := range profile.GetShortcutIds() {
+		if m.shortcutManager.GetByIdType(id, shortcuts.ShortcutTypeSystem) == nil {
+			return ErrShortcutNotFound{id, shortcuts.ShortcutTypeSystem}
+		}
+	}
+	return nil
+}
+
+// AddProfile 添加应用的快捷键配置，profile 为 JSON 格式，
+// 例如 {"Name":"ide","Apps":["code"],"Disabled":["terminal"],"Remap":{"launcher":["<Super>A"]}}
+func (m *Manager) AddProfile(profile string) *dbus.Error {
+	logger.Debug("AddProfile", profile)
+	var p shortcuts.Profile
+	err := json.Unmarshal([]byte(profile), &p)
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+	err = p.Check()
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+	// Wayland 中的快捷键由 KWin 处理，只能在响应时禁用，无法重新映射
+	if _useWayland && len(p.Remap) > 0 {
+		return dbusutil.ToError(errRemapNotSupportedInWL)
+	}
+	err = m.checkProfileShortcuts(&p)
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+
+	m.profilesMu.Lock()
+	defer m.profilesMu.Unlock()
+	for _, p0 := range m.profiles {
+		if p0.Name == p.Name {
+			return dbusutil.ToError(errNameUsed)
+		}
+	}
+	m.profiles = append(m.profiles, &p)
+	err = m.saveProfilesLocked()
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+	m.updateActiveProfileLocked()
+	return nil
+}
+
+func (m *Manager) DeleteProfile(name string) *dbus.Error {
+	logger.Debug("DeleteProfile", name)
+	m.profilesMu.Lock()
+	defer m.profilesMu.Unlock()
+
+	idx := -1
+	for i, p := range m.profiles {
+		if p.Name == name {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return dbusutil.ToError(errProfileNotFound)
+	}
+	m.profiles = append(m.profiles[:idx:idx], m.profiles[idx+1:]...)
+	err := m.saveProfilesLocked()
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+	m.updateActiveProfileLocked()
+	return nil
+}
+
+func (m *Manager) ListProfiles() (profiles string, busErr *dbus.Error) {
+	m.profilesMu.Lock()
+	defer m.profilesMu.Unlock()
+	list := m.profiles
+	if list == nil {
+		list = []*shortcuts.Profile{}
+	}
+	data, err := json.Marshal(list)
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	return string(data), nil
+}
@@ -32,6 +32,10 @@ const (
 
 	ActionTypeCallback // 触发回调函数点Action
 
+	ActionTypeEnterMode // 进入按键模式
+
+	ActionTypeMacro // 依次执行多个动作
+
 	// end
 	actionTypeMax
 )
@@ -146,3 +150,10 @@ func NewCallbackAction(fn func(ev *KeyEvent)) *Action {
 		Arg:  fn,
 	}
 }
+
+func NewEnterModeAction(mode string) *Action {
+	return &Action{
+		Type: ActionTypeEnterMode,
+		Arg:  mode,
+	}
+}
@@ -18,13 +18,19 @@ const (
 	kfKeyName       = "Name"
 	kfKeyKeystrokes = "Accels"
 	kfKeyAction     = "Action"
+	kfKeyMacro      = "Macro"
+
+	// 自定义快捷键的命令为 "mode:<name>" 时进入按键模式 name
+	EnterModeCmdPrefix = "mode:"
 )
 
 type CustomShortcut struct {
 	BaseShortcut
 	manager *CustomShortcutManager
 	Cmd     string `json:"Exec"`
-	wm      wm.Wm
+	// JSON 格式的宏，不为空时代替 Cmd 执行
+	Macro string `json:",omitempty"`
+	wm    wm.Wm
 }
 
 func (cs *CustomShortcut) Marshal() (string, error) {
@@ -62,11 +68,38 @@ func (cs *CustomShortcut) Save() error {
 	kfile := cs.manager.kfile
 	kfile.SetString(section, kfKeyName, cs.Name)
 	kfile.SetString(section, kfKeyAction, cs.Cmd)
+	setMacro(kfile, section, cs.Macro)
 	kfile.SetStringList(section, kfKeyKeystrokes, cs.getKeystrokesStrv())
 	return cs.manager.Save()
 }
 
+// IsEnterModeCmd 判断命令是否是进入按键模式
+func IsEnterModeCmd(cmd string) bool {
+	return strings.HasPrefix(cmd, EnterModeCmdPrefix) && len(cmd) > len(EnterModeCmdPrefix)
+}
+
+func setMacro(kfile *keyfile.KeyFile, section, macro string) {
+	if macro == "" {
+		kfile.DeleteKey(section, kfKeyMacro)
+	} else {
+		kfile.SetString(section, kfKeyMacro, macro)
+	}
+}
+
 func (cs *CustomShortcut) GetAction() *Action {
+	if cs.Macro != "" {
+		steps, err := ParseMacro(cs.Macro)
+		if err != nil {
+			logger.Warningf("invalid macro of custom shortcut %q: %v", cs.Id, err)
+			return ActionNoOp
+		}
+		return NewMacroAction(steps)
+	}
+
+	if IsEnterModeCmd(cs.Cmd) {
+		return NewEnterModeAction(strings.TrimPrefix(cs.Cmd, EnterModeCmdPrefix))
+	}
+
 	_, err := os.Stat(cs.Cmd)
 	if !os.IsNotExist(err) {
 		if strings.HasSuffix(cs.Cmd, ".desktop") {
@@ -100,7 +133,7 @@ type CustomShortcutManager struct {
 	pinyinEnabled bool
 }
 
-func newCustomShort(id, name, cmd string, keystrokes []string, wm wm.Wm, csm *CustomShortcutManager) *CustomShortcut {
+func newCustomShort(id, name, cmd, macro string, keystrokes []string, wm wm.Wm, csm *CustomShortcutManager) *CustomShortcut {
 	return &CustomShortcut{
 		BaseShortcut: BaseShortcut{
 			Id:         id,
@@ -111,6 +144,7 @@ func newCustomShort(id, name, cmd string, keystrokes []string, wm wm.Wm, csm *Cu
 		manager: csm,
 		wm:      wm,
 		Cmd:     cmd,
+		Macro:   macro,
 	}
 }
 
@@ -137,6 +171,7 @@ func (csm *CustomShortcutManager) List() []Shortcut {
 		name, _ := kfile.GetString(section, kfKeyName)
 		cmd, _ := kfile.GetString(section, kfKeyAction)
 		keystrokes, _ := kfile.GetStringList(section, kfKeyKeystrokes)
+		macro, _ := kfile.GetString(section, kfKeyMacro)
 
 		shortcut := &CustomShortcut{
 			BaseShortcut: BaseShortcut{
@@ -147,6 +182,7 @@ func (csm *CustomShortcutManager) List() []Shortcut {
 			},
 			manager: csm,
 			Cmd:     cmd,
+			Macro:   macro,
 		}
 
 		ret = append(ret, shortcut)
@@ -0,0 +1,179 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package shortcuts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ShortcutDocumentVersion 是导入导出快捷键的文档格式版本，格式不兼容时增加
+const ShortcutDocumentVersion = 1
+
+// ShortcutDocument 是导入导出的快捷键文档，只包含修改过的系统和多媒体快捷键以及所有自定义快捷键
+type ShortcutDocument struct {
+	Version int
+	// key 为快捷键 id，value 为按键
+	System map[string][]string       `json:",omitempty"`
+	Media  map[string][]string       `json:",omitempty"`
+	Custom []*DocumentCustomShortcut `json:",omitempty"`
+}
+
+type DocumentCustomShortcut struct {
+	Name   string
+	Exec   string
+	Macro  string `json:",omitempty"`
+	Accels []string
+}
+
+// DocumentEntry 是文档中一个快捷键的按键
+type DocumentEntry struct {
+	Id         string
+	Type       int32
+	Keystrokes []*Keystroke
+	// 只有自定义快捷键有
+	Custom *DocumentCustomShortcut
+}
+
+// ImportConflict 是导入时不能使用的按键，ConflictId 和 ConflictType 是占用按键的快捷键
+type ImportConflict struct {
+	Id           string
+	Type         int32
+	Keystroke    string
+	ConflictId   string
+	ConflictType int32
+}
+
+type ImportError struct {
+	Id    string
+	Type  int32
+	Error string
+}
+
+// ImportResult 是导入的结果，冲突的按键和出错的快捷键不会被导入
+type ImportResult struct {
+	Conflicts []*ImportConflict
+	Errors    []*ImportError
+}
+
+func (r *ImportResult) AddError(id string, type0 int32, err error) {
+	r.Errors = append(r.Errors, &ImportError{
+		Id:    id,
+		Type:  type0,
+		Error: err.Error(),
+	})
+}
+
+func parseDocumentKeystrokes(keystrokes []string) ([]*Keystroke, error) {
+	result := make([]*Keystroke, 0, len(keystrokes))
+	for _, str := range keystrokes {
+		ks, err := ParseKeystroke(str)
+		if err != nil {
+			return nil, fmt.Errorf("invalid keystroke %q: %v", str, err)
+		}
+		result = append(result, ks)
+	}
+	return result, nil
+}
+
+// ParseShortcutDocument 解析并检查快捷键文档
+func ParseShortcutDocument(data string) (*ShortcutDocument, error) {
+	var doc ShortcutDocument
+	err := json.Unmarshal([]byte(data), &doc)
+	if err != nil {
+		return nil, err
+	}
+	if doc.Version <= 0 || doc.Version > ShortcutDocumentVersion {
+		return nil, fmt.Errorf("unsupported document version %d", doc.Version)
+	}
+
+	names := make(map[string]bool, len(doc.Custom))
+	for _, cs := range doc.Custom {
+		if cs == nil || cs.Name == "" {
+			return nil, errors.New("custom shortcut name is empty")
+		}
+		if names[cs.Name] {
+			return nil, fmt.Errorf("custom shortcut %q is duplicated", cs.Name)
+		}
+		names[cs.Name] = true
+		if cs.Exec == "" && cs.Macro == "" {
+			return nil, fmt.Errorf("custom shortcut %q has no action", cs.Name)
+		}
+		if cs.Macro != "" {
+			_, err = ParseMacro(cs.Macro)
+			if err != nil {
+				return nil, fmt.Errorf("invalid macro of custom shortcut %q: %v", cs.Name, err)
+			}
+		}
+		if len(cs.Accels) > 1 {
+			return nil, fmt.Errorf("custom shortcut %q has more than one keystroke", cs.Name)
+		}
+	}
+	return &doc, nil
+}
+
+func sortedIds(m map[string][]string) []string {
+	ids := make([]string, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// GetEntries 按照系统、多媒体、自定义的顺序返回文档中的快捷键，按键解析失败的快捷键记录到 result 中
+func (doc *ShortcutDocument) GetEntries(result *ImportResult) []*DocumentEntry {
+	var entries []*DocumentEntry
+	addEntry := func(id string, type0 int32, keystrokes []string, cs *DocumentCustomShortcut) {
+		list, err := parseDocumentKeystrokes(keystrokes)
+		if err != nil {
+			result.AddError(id, type0, err)
+			return
+		}
+		entries = append(entries, &DocumentEntry{
+			Id:         id,
+			Type:       type0,
+			Keystrokes: list,
+			Custom:     cs,
+		})
+	}
+
+	for _, id := range sortedIds(doc.System) {
+		addEntry(id, ShortcutTypeSystem, doc.System[id], nil)
+	}
+	for _, id := range sortedIds(doc.Media) {
+		addEntry(id, ShortcutTypeMedia, doc.Media[id], nil)
+	}
+	for _, cs := range doc.Custom {
+		addEntry(cs.Name, ShortcutTypeCustom, cs.Accels, cs)
+	}
+	return entries
+}
+
+// FindDuplicateKeystrokes 查找文档中被多个快捷键使用的按键，后出现的按键记为冲突
+func FindDuplicateKeystrokes(entries []*DocumentEntry) []*ImportConflict {
+	var conflicts []*ImportConflict
+	used := make(map[string]*DocumentEntry)
+	for _, entry := range entries {
+		for _, ks := range entry.Keystrokes {
+			str := ks.String()
+			owner, ok := used[str]
+			if ok && owner != entry {
+				conflicts = append(conflicts, &ImportConflict{
+					Id:           entry.Id,
+					Type:         entry.Type,
+					Keystroke:    str,
+					ConflictId:   owner.Id,
+					ConflictType: owner.Type,
+				})
+				continue
+			}
+			used[str] = entry
+		}
+	}
+	return conflicts
+}
@@ -0,0 +1,56 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package shortcuts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseShortcutDocument(t *testing.T) {
+	doc, err := ParseShortcutDocument(`{
+		"Version": 1,
+		"System": {"terminal": ["<Control><Alt>T"], "launcher": ["<Super>A", "bad"]},
+		"Media": {"mute": ["<Super>M"]},
+		"Custom": [
+			{"Name": "term2", "Exec": "deepin-terminal", "Accels": ["<Control><Alt>T"]},
+			{"Name": "quiet", "Exec": "", "Macro": "[{\"Action\":\"mute\",\"Arg\":\"true\"}]", "Accels": ["<Super>Q"]}
+		]
+	}`)
+	require.NoError(t, err)
+
+	var result ImportResult
+	entries := doc.GetEntries(&result)
+	require.Len(t, entries, 4)
+	assert.Equal(t, "terminal", entries[0].Id)
+	assert.Equal(t, ShortcutTypeSystem, entries[0].Type)
+	assert.Equal(t, ShortcutTypeMedia, entries[1].Type)
+	assert.Equal(t, "term2", entries[2].Id)
+	assert.NotNil(t, entries[2].Custom)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "launcher", result.Errors[0].Id)
+
+	conflicts := FindDuplicateKeystrokes(entries)
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, "term2", conflicts[0].Id)
+	assert.Equal(t, "terminal", conflicts[0].ConflictId)
+	assert.Equal(t, ShortcutTypeSystem, conflicts[0].ConflictType)
+	assert.Equal(t, "<Control><Alt>T", conflicts[0].Keystroke)
+
+	for _, invalid := range []string{
+		`{}`,
+		`{"Version": 2}`,
+		`{"Version": 1, "Custom": [{"Name": "", "Exec": "ls"}]}`,
+		`{"Version": 1, "Custom": [{"Name": "a", "Exec": ""}]}`,
+		`{"Version": 1, "Custom": [{"Name": "a", "Exec": "ls"}, {"Name": "a", "Exec": "ls"}]}`,
+		`{"Version": 1, "Custom": [{"Name": "a", "Exec": "ls", "Accels": ["<Super>A", "<Super>B"]}]}`,
+		`{"Version": 1, "Custom": [{"Name": "a", "Macro": "[]"}]}`,
+	} {
+		_, err = ParseShortcutDocument(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,385 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package shortcuts
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/linuxdeepin/go-x11-client/util/keybind"
+	"github.com/linuxdeepin/go-x11-client/util/keysyms"
+)
+
+const (
+	modeKeystrokePrefix = "<Mode:"
+	maxKeySequenceLen   = 4
+
+	// 多步组合键两步之间的最长等待时间
+	chordTimeout = 3 * time.Second
+	// 按键模式在没有按键时自动退出的时间
+	modeTimeout = 10 * time.Second
+)
+
+// keymapState 是当前激活的按键模式或者等待中的多步组合键
+type keymapState struct {
+	// 为空时是多步组合键
+	mode string
+	// 已经按下的按键
+	steps   []Key
+	timeout time.Duration
+	timer   *time.Timer
+}
+
+// parseKeySequence 解析多步组合键和模式中的按键，
+// 例如 "<Super>W L"、"<Mode:resize>L"、"<Mode:resize>G H"
+func parseKeySequence(str string) (*Keystroke, error) {
+	var mode string
+	if strings.HasPrefix(str, modeKeystrokePrefix) {
+		end := strings.IndexByte(str, '>')
+		if end < 0 {
+			return nil, errors.New("> not found")
+		}
+		mode = str[len(modeKeystrokePrefix):end]
+		if mode == "" || strings.ContainsAny(mode, " \t<") {
+			return nil, errors.New("invalid mode " + mode)
+		}
+		str = str[end+1:]
+	}
+
+	parts := strings.Fields(str)
+	if len(parts) == 0 {
+		return nil, errors.New("keystroke is empty")
+	}
+	if len(parts) > maxKeySequenceLen {
+		return nil, errors.New("key sequence is too long")
+	}
+
+	steps := make([]*Keystroke, 0, len(parts))
+	for _, part := range parts {
+		step, err := parseSingleKeystroke(part)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	ks := steps[0]
+	ks.mode = mode
+	ks.chord = steps[1:]
+	if len(ks.chord) == 0 {
+		ks.chord = nil
+	}
+	return ks, nil
+}
+
+// IsKeymapBinding 判断是否是多步组合键或者模式中的按键，这些按键不能直接抓取
+func (ks *Keystroke) IsKeymapBinding() bool {
+	return ks.mode != "" || len(ks.chord) > 0
+}
+
+// getSteps 返回按键序列中的每一步
+func (ks *Keystroke) getSteps() []*Keystroke {
+	steps := make([]*Keystroke, 0, len(ks.chord)+1)
+	steps = append(steps, &Keystroke{
+		Mods:             ks.Mods,
+		Keystr:           ks.Keystr,
+		Keysym:           ks.Keysym,
+		isKeystrAboveTab: ks.isKeystrAboveTab,
+	})
+	return append(steps, ks.chord...)
+}
+
+// isKeySequenceConflict 判断两个按键序列是否冲突，同一个模式中一个序列是另一个序列的前缀时冲突
+func isKeySequenceConflict(a, b *Keystroke, stepEqual func(a, b *Keystroke) bool) bool {
+	if a.mode != b.mode {
+		return false
+	}
+	stepsA := a.getSteps()
+	stepsB := b.getSteps()
+	n := len(stepsA)
+	if len(stepsB) < n {
+		n = len(stepsB)
+	}
+	for i := 0; i < n; i++ {
+		if !stepEqual(stepsA[i], stepsB[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (sm *ShortcutManager) stepEqual(a, b *Keystroke) bool {
+	return a.stepEqual(sm.keySymbols, b)
+}
+
+func (sm *ShortcutManager) findKeymapConflictLocked(ks *Keystroke) *Keystroke {
+	for ks0 := range sm.keymapKeystrokes {
+		if isKeySequenceConflict(ks0, ks, sm.stepEqual) {
+			return ks0
+		}
+	}
+	return nil
+}
+
+func (sm *ShortcutManager) findKeymapConflictingKeystroke(ks *Keystroke) (*Keystroke, error) {
+	if ks.mode == "" {
+		// 第一步已经被其他快捷键占用
+		conflictKeystroke, err := sm.findGrabbedKeystroke(ks.getSteps()[0])
+		if err != nil || conflictKeystroke != nil {
+			return conflictKeystroke, err
+		}
+	}
+
+	sm.keymapMu.Lock()
+	defer sm.keymapMu.Unlock()
+	return sm.findKeymapConflictLocked(ks), nil
+}
+
+func (sm *ShortcutManager) isChordPrefixKey(key Key) bool {
+	sm.keymapMu.Lock()
+	defer sm.keymapMu.Unlock()
+	return sm.prefixKeyCount[key] > 0
+}
+
+// grabKeymapKeystroke 记录按键序列，只抓取多步组合键的第一步
+func (sm *ShortcutManager) grabKeymapKeystroke(shortcut Shortcut, ks *Keystroke, dummy bool) {
+	sm.keymapMu.Lock()
+	defer sm.keymapMu.Unlock()
+
+	if _, ok := sm.keymapKeystrokes[ks]; ok {
+		return
+	}
+	conflictKeystroke := sm.findKeymapConflictLocked(ks)
+	if conflictKeystroke != nil && conflictKeystroke.Shortcut != shortcut {
+		logger.Debugf("key sequence %v conflicts with %v", ks, conflictKeystroke.DebugString())
+		if !sm.EliminateConflictDone {
+			sm.storeConflictingKeystroke(ks)
+		}
+		return
+	}
+
+	var grabbedKeys []Key
+	if ks.mode == "" {
+		keyList, err := ks.ToKeyList(sm.keySymbols)
+		if err != nil {
+			logger.Debugf("grabKeymapKeystroke failed, shortcut: %v, ks: %v, err: %v", shortcut.GetId(), ks, err)
+			return
+		}
+		for _, key := range keyList {
+			if sm.prefixKeyCount[key] == 0 {
+				sm.keyKeystrokeMapMu.Lock()
+				_, used := sm.keyKeystrokeMap[key]
+				sm.keyKeystrokeMapMu.Unlock()
+				if used {
+					logger.Debugf("prefix key %v of %v is grabbed", key, ks)
+					continue
+				}
+				if !dummy {
+					err = key.Grab(sm.conn)
+					if err != nil {
+						logger.Debug(err)
+						continue
+					}
+				}
+			}
+			sm.prefixKeyCount[key]++
+			grabbedKeys = append(grabbedKeys, key)
+		}
+		if len(grabbedKeys) == 0 {
+			if !sm.EliminateConflictDone {
+				sm.storeConflictingKeystroke(ks)
+			}
+			return
+		}
+	}
+	sm.keymapKeystrokes[ks] = grabbedKeys
+}
+
+func (sm *ShortcutManager) ungrabKeymapKeystroke(ks *Keystroke, dummy bool) {
+	sm.keymapMu.Lock()
+	defer sm.keymapMu.Unlock()
+
+	for ks0, keys := range sm.keymapKeystrokes {
+		if ks0 != ks && !ks0.Equal(sm.keySymbols, ks) {
+			continue
+		}
+		delete(sm.keymapKeystrokes, ks0)
+		for _, key := range keys {
+			sm.prefixKeyCount[key]--
+			if sm.prefixKeyCount[key] > 0 {
+				continue
+			}
+			delete(sm.prefixKeyCount, key)
+			if !dummy {
+				key.Ungrab(sm.conn)
+			}
+		}
+	}
+}
+
+func (sm *ShortcutManager) ungrabAllKeymapKeystrokes() {
+	sm.keymapMu.Lock()
+	for key := range sm.prefixKeyCount {
+		key.Ungrab(sm.conn)
+	}
+	sm.prefixKeyCount = make(map[Key]int)
+	sm.keymapKeystrokes = make(map[*Keystroke][]Key)
+	sm.keymapMu.Unlock()
+}
+
+// EnterMode 进入按键模式，模式中的按键生效，按 Escape 或者超时后退出
+func (sm *ShortcutManager) EnterMode(mode string) {
+	logger.Debug("enter keymap mode:", mode)
+	sm.keymapMu.Lock()
+	sm.activateKeymapLocked(mode, nil)
+	sm.keymapMu.Unlock()
+}
+
+func (sm *ShortcutManager) startChord(key Key) bool {
+	sm.keymapMu.Lock()
+	defer sm.keymapMu.Unlock()
+	if sm.prefixKeyCount[key] == 0 {
+		return false
+	}
+	return sm.activateKeymapLocked("", []Key{key})
+}
+
+// activateKeymapLocked 抓取键盘，之后的按键都由 handleKeymapKeyEvent 处理
+func (sm *ShortcutManager) activateKeymapLocked(mode string, steps []Key) bool {
+	if sm.keymapState == nil {
+		err := keybind.GrabKeyboard(sm.conn, sm.conn.GetDefaultScreen().Root)
+		if err != nil {
+			logger.Warning("failed to grab keyboard:", err)
+			return false
+		}
+	} else {
+		sm.keymapState.timer.Stop()
+	}
+
+	state := &keymapState{
+		mode:    mode,
+		steps:   steps,
+		timeout: chordTimeout,
+	}
+	if mode != "" {
+		state.timeout = modeTimeout
+	}
+	state.timer = time.AfterFunc(state.timeout, func() {
+		sm.keymapMu.Lock()
+		defer sm.keymapMu.Unlock()
+		if sm.keymapState == state {
+			logger.Debug("keymap timeout, mode:", state.mode)
+			sm.exitKeymapLocked()
+		}
+	})
+	sm.keymapState = state
+	return true
+}
+
+func (sm *ShortcutManager) exitKeymapLocked() {
+	sm.keymapState.timer.Stop()
+	sm.keymapState = nil
+	err := keybind.UngrabKeyboard(sm.conn)
+	if err != nil {
+		logger.Warning("failed to ungrab keyboard:", err)
+	}
+}
+
+func (sm *ShortcutManager) isKeyOfStep(step *Keystroke, key Key) bool {
+	keyList, err := step.ToKeyList(sm.keySymbols)
+	if err != nil {
+		return false
+	}
+	for _, k := range keyList {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// matchKeymapLocked 在模式 mode 中查找与已按下的按键 steps 完全匹配的按键序列，
+// isPrefix 表示还有以 steps 开头的更长的按键序列
+func (sm *ShortcutManager) matchKeymapLocked(mode string, steps []Key) (matched *Keystroke, isPrefix bool) {
+	for ks := range sm.keymapKeystrokes {
+		if ks.mode != mode {
+			continue
+		}
+		seq := ks.getSteps()
+		if len(seq) < len(steps) {
+			continue
+		}
+		ok := true
+		for i, key := range steps {
+			if !sm.isKeyOfStep(seq[i], key) {
+				ok = false
+				break
+			}
+		}
+		if !ok {
+			continue
+		}
+		if len(seq) == len(steps) {
+			matched = ks
+		} else {
+			isPrefix = true
+		}
+	}
+	return
+}
+
+// handleKeymapKeyEvent 处理按键模式或者多步组合键激活时的按键，没有激活时返回 false
+func (sm *ShortcutManager) handleKeymapKeyEvent(key Key) bool {
+	sm.keymapMu.Lock()
+	state := sm.keymapState
+	if state == nil {
+		sm.keymapMu.Unlock()
+		return false
+	}
+
+	ks := key.ToKeystroke(sm.keySymbols)
+	if ks == nil || keysyms.IsModifierKey(ks.Keysym) {
+		sm.keymapMu.Unlock()
+		return true
+	}
+	if key.Mods == 0 && ks.Keysym == keysyms.XK_Escape {
+		logger.Debug("exit keymap, mode:", state.mode)
+		sm.exitKeymapLocked()
+		sm.keymapMu.Unlock()
+		return true
+	}
+
+	steps := append(state.steps[:len(state.steps):len(state.steps)], key)
+	matched, isPrefix := sm.matchKeymapLocked(state.mode, steps)
+	switch {
+	case matched != nil:
+		if state.mode == "" {
+			sm.exitKeymapLocked()
+		} else {
+			// 模式中的按键执行后保持在模式中
+			state.steps = nil
+			state.timer.Reset(state.timeout)
+		}
+	case isPrefix:
+		state.steps = steps
+		state.timer.Reset(state.timeout)
+	case state.mode == "":
+		logger.Debug("key sequence not matched:", ks)
+		sm.exitKeymapLocked()
+	default:
+		state.steps = nil
+		state.timer.Reset(state.timeout)
+	}
+	sm.keymapMu.Unlock()
+
+	if matched != nil && matched.Shortcut != nil {
+		sm.callEventCallback(&KeyEvent{
+			Mods:     key.Mods,
+			Code:     key.Code,
+			Shortcut: matched.Shortcut,
+		})
+	}
+	return true
+}
@@ -0,0 +1,90 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package shortcuts
+
+import (
+	"testing"
+
+	"github.com/linuxdeepin/go-x11-client/util/keysyms"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKeySequence(t *testing.T) {
+	ks, err := ParseKeystroke("<Super>W L")
+	require.NoError(t, err)
+	assert.True(t, ks.IsKeymapBinding())
+	assert.Equal(t, Modifiers(keysyms.ModMaskSuper), ks.Mods)
+	assert.Equal(t, "W", ks.Keystr)
+	require.Len(t, ks.chord, 1)
+	assert.Equal(t, "L", ks.chord[0].Keystr)
+	assert.Equal(t, "<Super>W L", ks.String())
+
+	ks, err = ParseKeystroke("<Mode:resize><Shift>Left")
+	require.NoError(t, err)
+	assert.True(t, ks.IsKeymapBinding())
+	assert.Equal(t, "resize", ks.mode)
+	assert.Nil(t, ks.chord)
+	assert.Equal(t, "<Mode:resize><Shift>Left", ks.String())
+
+	ks, err = ParseKeystroke("<Mode:git>G  <Control>H")
+	require.NoError(t, err)
+	assert.Equal(t, "<Mode:git>G <Control>H", ks.String())
+
+	ks, err = ParseKeystroke("<Super>L")
+	require.NoError(t, err)
+	assert.False(t, ks.IsKeymapBinding())
+
+	for _, invalid := range []string{
+		"<Mode:>L",
+		"<Mode:resize",
+		"<Mode:resize>",
+		"<Super>W Foo",
+		"A B C D E",
+	} {
+		_, err = ParseKeystroke(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestIsKeySequenceConflict(t *testing.T) {
+	stepEqual := func(a, b *Keystroke) bool {
+		return a.Mods == b.Mods && a.Keystr == b.Keystr
+	}
+	parse := func(str string) *Keystroke {
+		ks, err := ParseKeystroke(str)
+		require.NoError(t, err)
+		return ks
+	}
+
+	testData := []struct {
+		a, b     string
+		conflict bool
+	}{
+		{"<Super>W L", "<Super>W L", true},
+		{"<Super>W L", "<Super>W H", false},
+		{"<Super>W", "<Super>W L", true},
+		{"<Super>W L", "<Super>W L H", true},
+		{"<Super>W L", "<Super>E L", false},
+		{"<Mode:resize>L", "<Mode:resize>L H", true},
+		{"<Mode:resize>L", "<Mode:move>L", false},
+		{"<Mode:resize>L", "L", false},
+	}
+	for _, data := range testData {
+		assert.Equal(t, data.conflict, isKeySequenceConflict(parse(data.a), parse(data.b), stepEqual),
+			data.a+" | "+data.b)
+	}
+}
+
+func TestIsEnterModeCmd(t *testing.T) {
+	assert.True(t, IsEnterModeCmd("mode:resize"))
+	assert.False(t, IsEnterModeCmd("mode:"))
+	assert.False(t, IsEnterModeCmd("/usr/bin/mode"))
+
+	cs := &CustomShortcut{Cmd: "mode:resize"}
+	action := cs.GetAction()
+	assert.Equal(t, ActionTypeEnterMode, action.Type)
+	assert.Equal(t, "resize", action.Arg)
+}
@@ -23,6 +23,10 @@ type Keystroke struct {
 	Shortcut Shortcut
 
 	isKeystrAboveTab bool
+	// 按键所属的模式，为空时全局生效
+	mode string
+	// 多步组合键中第一步之后的按键
+	chord []*Keystroke
 }
 
 func (ks *Keystroke) DebugString() string {
@@ -36,6 +40,19 @@ func (ks *Keystroke) DebugString() string {
 
 func (a *Keystroke) Equal(keySymbols *keysyms.KeySymbols, b *Keystroke) bool {
 	logger.Debug(a, " equal? ", b)
+	if a.mode != b.mode || len(a.chord) != len(b.chord) {
+		return false
+	}
+	for i := range a.chord {
+		if !a.chord[i].stepEqual(keySymbols, b.chord[i]) {
+			return false
+		}
+	}
+	return a.stepEqual(keySymbols, b)
+}
+
+// stepEqual 只比较按键序列中的一步
+func (a *Keystroke) stepEqual(keySymbols *keysyms.KeySymbols, b *Keystroke) bool {
 	if a.Mods != b.Mods {
 		logger.Debug("Mods no equal, return false")
 		return false
@@ -211,8 +228,17 @@ func splitKeystroke(str string) ([]string, error) {
 // <Super> mods() key Super
 // Print mods() key Print
 // <Control>Print mods(Control) key Print
+// <Super>W L key sequence, press <Super>W then L
+// <Mode:resize>L key L in mode resize
 // check Keystroke.Keystr valid later
 func ParseKeystroke(keystroke string) (*Keystroke, error) {
+	if strings.HasPrefix(keystroke, modeKeystrokePrefix) || strings.ContainsAny(keystroke, " \t") {
+		return parseKeySequence(keystroke)
+	}
+	return parseSingleKeystroke(keystroke)
+}
+
+func parseSingleKeystroke(keystroke string) (*Keystroke, error) {
 	parts, err := splitKeystroke(keystroke)
 	if err != nil {
 		return nil, err
@@ -275,6 +301,17 @@ func ParseKeystrokes(keystrokes []string) []*Keystroke {
 }
 
 func (ks *Keystroke) String() string {
+	str := ks.stepString()
+	for _, step := range ks.chord {
+		str += " " + step.stepString()
+	}
+	if ks.mode != "" {
+		str = modeKeystrokePrefix + ks.mode + ">" + str
+	}
+	return str
+}
+
+func (ks *Keystroke) stepString() string {
 	var keys []string
 	mods := ks.Mods
 	if mods&keysyms.ModMaskShift > 0 {
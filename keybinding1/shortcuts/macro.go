@@ -0,0 +1,180 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package shortcuts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxMacroSteps = 32
+	// 每一步执行前最长的等待时间，单位毫秒
+	maxMacroStepDelay = 60 * 1000
+)
+
+// 宏中每一步的动作
+const (
+	MacroActionExec        = "exec"       // Arg 为命令行
+	MacroActionDesktopFile = "desktop"    // Arg 为 desktop 文件路径
+	MacroActionMimeType    = "mime"       // Arg 为 mime type，运行默认程序
+	MacroActionCtrl        = "ctrl"       // Arg 为控制器命令，例如 AudioSinkMuteToggle
+	MacroActionMute        = "mute"       // Arg 为 true 或者 false，设置默认输出设备是否静音
+	MacroActionVolume      = "volume"     // Arg 为默认输出设备的音量百分比
+	MacroActionBrightness  = "brightness" // Arg 为所有显示器的亮度百分比
+	MacroActionEnterMode   = "mode"       // Arg 为按键模式名称
+)
+
+// 宏中每一步执行的条件，前面加 ! 表示取反
+const (
+	MacroConditionWayland    = "wayland"
+	MacroConditionX11        = "x11"
+	MacroConditionOnBattery  = "on-battery"
+	MacroConditionAudioMuted = "audio-muted"
+)
+
+var macroConditions = []string{
+	MacroConditionWayland,
+	MacroConditionX11,
+	MacroConditionOnBattery,
+	MacroConditionAudioMuted,
+}
+
+var actionCmdNameMap = map[string]ActionCmd{
+	"AudioSinkMuteToggle":    AudioSinkMuteToggle,
+	"AudioSinkVolumeUp":      AudioSinkVolumeUp,
+	"AudioSinkVolumeDown":    AudioSinkVolumeDown,
+	"AudioSourceMuteToggle":  AudioSourceMuteToggle,
+	"MediaPlayerPlay":        MediaPlayerPlay,
+	"MediaPlayerPause":       MediaPlayerPause,
+	"MediaPlayerStop":        MediaPlayerStop,
+	"MediaPlayerPrevious":    MediaPlayerPrevious,
+	"MediaPlayerNext":        MediaPlayerNext,
+	"MediaPlayerRewind":      MediaPlayerRewind,
+	"MediaPlayerForword":     MediaPlayerForword,
+	"MediaPlayerRepeat":      MediaPlayerRepeat,
+	"MonitorBrightnessUp":    MonitorBrightnessUp,
+	"MonitorBrightnessDown":  MonitorBrightnessDown,
+	"DisplayModeSwitch":      DisplayModeSwitch,
+	"KbdLightToggle":         KbdLightToggle,
+	"KbdLightBrightnessUp":   KbdLightBrightnessUp,
+	"KbdLightBrightnessDown": KbdLightBrightnessDown,
+	"TouchpadToggle":         TouchpadToggle,
+	"TouchpadOn":             TouchpadOn,
+	"TouchpadOff":            TouchpadOff,
+}
+
+// GetActionCmdByName 根据名称返回控制器命令
+func GetActionCmdByName(name string) (ActionCmd, bool) {
+	cmd, ok := actionCmdNameMap[name]
+	return cmd, ok
+}
+
+// MacroStep 是宏中的一步
+type MacroStep struct {
+	Action string
+	Arg    string `json:",omitempty"`
+	// 执行前等待的时间，单位毫秒
+	Delay uint32 `json:",omitempty"`
+	// 为空时总是执行
+	Condition string `json:",omitempty"`
+}
+
+// ParseCondition 返回条件名称和是否取反
+func (step *MacroStep) ParseCondition() (name string, negated bool) {
+	if strings.HasPrefix(step.Condition, "!") {
+		return step.Condition[1:], true
+	}
+	return step.Condition, false
+}
+
+// GetPercent 返回 Arg 表示的百分比
+func (step *MacroStep) GetPercent() (float64, error) {
+	value, err := strconv.ParseFloat(strings.TrimSuffix(step.Arg, "%"), 64)
+	if err != nil {
+		return 0, err
+	}
+	if value < 0 || value > 100 {
+		return 0, fmt.Errorf("percent %v out of range", value)
+	}
+	return value / 100, nil
+}
+
+func (step *MacroStep) check() error {
+	if step.Delay > maxMacroStepDelay {
+		return fmt.Errorf("delay %d is too long", step.Delay)
+	}
+	if step.Condition != "" {
+		name, _ := step.ParseCondition()
+		found := false
+		for _, cond := range macroConditions {
+			if cond == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown condition %q", step.Condition)
+		}
+	}
+
+	switch step.Action {
+	case MacroActionExec, MacroActionDesktopFile, MacroActionMimeType, MacroActionEnterMode:
+		if step.Arg == "" {
+			return fmt.Errorf("action %q requires arg", step.Action)
+		}
+	case MacroActionCtrl:
+		if _, ok := GetActionCmdByName(step.Arg); !ok {
+			return fmt.Errorf("unknown controller cmd %q", step.Arg)
+		}
+	case MacroActionMute:
+		if _, err := strconv.ParseBool(step.Arg); err != nil {
+			return fmt.Errorf("invalid mute arg %q", step.Arg)
+		}
+	case MacroActionVolume, MacroActionBrightness:
+		if _, err := step.GetPercent(); err != nil {
+			return fmt.Errorf("invalid %s arg %q: %v", step.Action, step.Arg, err)
+		}
+	default:
+		return fmt.Errorf("unknown action %q", step.Action)
+	}
+	return nil
+}
+
+// ParseMacro 解析 JSON 格式的宏，例如
+// [{"Action":"mute","Arg":"true"},{"Action":"brightness","Arg":"30"},{"Action":"desktop","Arg":"/usr/share/applications/a.desktop","Delay":500}]
+func ParseMacro(str string) ([]*MacroStep, error) {
+	var steps []*MacroStep
+	err := json.Unmarshal([]byte(str), &steps)
+	if err != nil {
+		return nil, err
+	}
+	if len(steps) == 0 {
+		return nil, errors.New("macro is empty")
+	}
+	if len(steps) > maxMacroSteps {
+		return nil, errors.New("too many macro steps")
+	}
+	for i, step := range steps {
+		if step == nil {
+			return nil, fmt.Errorf("step %d is null", i)
+		}
+		err = step.check()
+		if err != nil {
+			return nil, fmt.Errorf("step %d: %v", i, err)
+		}
+	}
+	return steps, nil
+}
+
+func NewMacroAction(steps []*MacroStep) *Action {
+	return &Action{
+		Type: ActionTypeMacro,
+		Arg:  steps,
+	}
+}
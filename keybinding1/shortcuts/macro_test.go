@@ -0,0 +1,109 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package shortcuts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/linuxdeepin/go-lib/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMacro(t *testing.T) {
+	steps, err := ParseMacro(`[
+		{"Action":"mute","Arg":"true"},
+		{"Action":"brightness","Arg":"30%","Condition":"!on-battery"},
+		{"Action":"ctrl","Arg":"KbdLightToggle"},
+		{"Action":"desktop","Arg":"/usr/share/applications/a.desktop","Delay":500}
+	]`)
+	require.NoError(t, err)
+	require.Len(t, steps, 4)
+
+	v, err := steps[1].GetPercent()
+	require.NoError(t, err)
+	assert.InDelta(t, 0.3, v, 1e-9)
+	name, negated := steps[1].ParseCondition()
+	assert.Equal(t, MacroConditionOnBattery, name)
+	assert.True(t, negated)
+
+	cmd, ok := GetActionCmdByName(steps[2].Arg)
+	assert.True(t, ok)
+	assert.Equal(t, KbdLightToggle, cmd)
+	assert.Equal(t, uint32(500), steps[3].Delay)
+
+	for _, invalid := range []string{
+		``,
+		`[]`,
+		`[null]`,
+		`[{"Action":"unknown"}]`,
+		`[{"Action":"exec"}]`,
+		`[{"Action":"ctrl","Arg":"Foo"}]`,
+		`[{"Action":"mute","Arg":"yes"}]`,
+		`[{"Action":"volume","Arg":"150"}]`,
+		`[{"Action":"exec","Arg":"ls","Condition":"unknown"}]`,
+		`[{"Action":"exec","Arg":"ls","Delay":600000}]`,
+	} {
+		_, err = ParseMacro(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestCustomShortcutMacroAction(t *testing.T) {
+	SetLogger(log.NewLogger("shortcuts"))
+	cs := &CustomShortcut{
+		Cmd:   "/usr/bin/true",
+		Macro: `[{"Action":"volume","Arg":"50"}]`,
+	}
+	action := cs.GetAction()
+	assert.Equal(t, ActionTypeMacro, action.Type)
+	steps, ok := action.Arg.([]*MacroStep)
+	require.True(t, ok)
+	assert.Equal(t, MacroActionVolume, steps[0].Action)
+
+	cs.Macro = `[{"Action":"unknown"}]`
+	assert.Equal(t, ActionNoOp, cs.GetAction())
+
+	cs.Macro = ""
+	assert.Equal(t, ActionTypeExecCmd, cs.GetAction().Type)
+}
+
+func TestLoadCustomShortcutMacro(t *testing.T) {
+	SetLogger(log.NewLogger("shortcuts"))
+	file := filepath.Join(t.TempDir(), "custom.ini")
+	err := os.WriteFile(file, []byte(`[macro]
+Name=macro
+Action=
+Macro=[{"Action":"mute","Arg":"true"}]
+Accels=<Control><Alt>M
+
+[exec]
+Name=exec
+Action=/usr/bin/true
+Accels=<Control><Alt>E
+`), 0644)
+	require.NoError(t, err)
+
+	csm := NewCustomShortcutManager(file)
+	list := csm.List()
+	require.Len(t, list, 2)
+	for _, shortcut := range list {
+		cmd, macro, ok := getWaylandCustomCmd(shortcut)
+		require.True(t, ok, shortcut.GetId())
+		cs := newCustomShort(shortcut.GetId(), shortcut.GetName(), cmd, macro,
+			shortcut.getKeystrokesStrv(), nil, csm)
+		switch shortcut.GetId() {
+		case "macro":
+			assert.Empty(t, cmd)
+			assert.Equal(t, ActionTypeMacro, cs.GetAction().Type)
+		case "exec":
+			assert.Equal(t, "/usr/bin/true", cmd)
+			assert.Empty(t, macro)
+			assert.Equal(t, ActionTypeExecCmd, cs.GetAction().Type)
+		}
+	}
+}
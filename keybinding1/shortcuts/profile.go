@@ -0,0 +1,168 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package shortcuts
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Profile 是应用的快捷键配置，应用的窗口获得焦点时生效
+type Profile struct {
+	Name string
+	// 应用窗口 WM_CLASS 的 instance 或者 class，Wayland 中为 app id
+	Apps []string
+	// 禁用的系统快捷键 id
+	Disabled []string
+	// 重新映射的系统快捷键，key 为快捷键 id，value 为新的按键
+	Remap map[string][]string
+}
+
+func (p *Profile) Check() error {
+	if p.Name == "" {
+		return errors.New("profile name is empty")
+	}
+	if len(p.Apps) == 0 {
+		return fmt.Errorf("profile %q has no apps", p.Name)
+	}
+	for id, keystrokes := range p.Remap {
+		if p.IsDisabled(id) {
+			return fmt.Errorf("shortcut %q is both disabled and remapped", id)
+		}
+		for _, keystroke := range keystrokes {
+			ks, err := ParseKeystroke(keystroke)
+			if err != nil {
+				return fmt.Errorf("invalid keystroke %q of shortcut %q: %v", keystroke, id, err)
+			}
+			if ks.IsKeymapBinding() {
+				return fmt.Errorf("key sequence %q can not be used in profile", keystroke)
+			}
+		}
+	}
+	return nil
+}
+
+// GetShortcutIds 返回被禁用或者重新映射的快捷键 id
+func (p *Profile) GetShortcutIds() []string {
+	ids := make([]string, 0, len(p.Disabled)+len(p.Remap))
+	ids = append(ids, p.Disabled...)
+	for id := range p.Remap {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Match 判断 appIds 中是否有配置中的应用，不区分大小写
+func (p *Profile) Match(appIds ...string) bool {
+	for _, app := range p.Apps {
+		for _, appId := range appIds {
+			if appId != "" && strings.EqualFold(app, appId) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *Profile) IsDisabled(id string) bool {
+	for _, disabled := range p.Disabled {
+		if disabled == id {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyProfile 应用快捷键配置，取消抓取被禁用和重新映射的系统快捷键，
+// 抓取重新映射后的按键，profile 为 nil 时恢复所有快捷键
+func (sm *ShortcutManager) ApplyProfile(profile *Profile) {
+	sm.profileMu.Lock()
+	defer sm.profileMu.Unlock()
+
+	sm.restoreProfileLocked()
+	sm.profile = profile
+	if profile == nil {
+		return
+	}
+	logger.Debug("apply shortcut profile:", profile.Name)
+
+	for _, id := range profile.GetShortcutIds() {
+		shortcut := sm.GetByIdType(id, ShortcutTypeSystem)
+		if shortcut == nil {
+			logger.Debugf("shortcut %q of profile %q not found", id, profile.Name)
+			continue
+		}
+		sm.ungrabShortcut(shortcut)
+		sm.profileShortcuts = append(sm.profileShortcuts, shortcut)
+	}
+
+	for id, keystrokes := range profile.Remap {
+		shortcut := sm.GetByIdType(id, ShortcutTypeSystem)
+		if shortcut == nil {
+			continue
+		}
+		for _, ks := range ParseKeystrokes(keystrokes) {
+			conflictKeystroke, err := sm.FindConflictingKeystroke(ks)
+			if err != nil || conflictKeystroke != nil {
+				logger.Debugf("remapped keystroke %v of %q is not available", ks, id)
+				continue
+			}
+			sm.grabKeystroke(shortcut, ks, dummyGrab(shortcut, ks))
+			ks.Shortcut = shortcut
+			sm.profileKeystrokes = append(sm.profileKeystrokes, ks)
+		}
+	}
+}
+
+func (sm *ShortcutManager) restoreProfileLocked() {
+	for _, ks := range sm.profileKeystrokes {
+		sm.ungrabOwnedKeystroke(ks, dummyGrab(ks.Shortcut, ks))
+		ks.Shortcut = nil
+	}
+	for _, shortcut := range sm.profileShortcuts {
+		sm.grabShortcut(shortcut)
+	}
+	sm.profileKeystrokes = nil
+	sm.profileShortcuts = nil
+}
+
+// ungrabOwnedKeystroke 只取消抓取仍然属于 ks 的按键
+func (sm *ShortcutManager) ungrabOwnedKeystroke(ks *Keystroke, dummy bool) {
+	keyList, err := ks.ToKeyList(sm.keySymbols)
+	if err != nil {
+		logger.Debug(err)
+		return
+	}
+
+	sm.keyKeystrokeMapMu.Lock()
+	defer sm.keyKeystrokeMapMu.Unlock()
+	for _, key := range keyList {
+		if sm.keyKeystrokeMap[key] != ks {
+			continue
+		}
+		delete(sm.keyKeystrokeMap, key)
+		if !dummy {
+			key.Ungrab(sm.conn)
+		}
+	}
+}
+
+// resetProfileKeystrokes 在 UngrabAll 之后清除记录，GrabAll 时重新应用
+func (sm *ShortcutManager) resetProfileKeystrokes() {
+	sm.profileMu.Lock()
+	sm.profileKeystrokes = nil
+	sm.profileShortcuts = nil
+	sm.profileMu.Unlock()
+}
+
+func (sm *ShortcutManager) reapplyProfile() {
+	sm.profileMu.Lock()
+	profile := sm.profile
+	sm.profileMu.Unlock()
+	if profile != nil {
+		sm.ApplyProfile(profile)
+	}
+}
@@ -0,0 +1,53 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package shortcuts
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfileCheck(t *testing.T) {
+	p := &Profile{
+		Name:     "ide",
+		Apps:     []string{"code"},
+		Disabled: []string{"terminal"},
+		Remap:    map[string][]string{"launcher": {"<Super>A"}},
+	}
+	assert.NoError(t, p.Check())
+
+	for _, invalid := range []*Profile{
+		{Apps: []string{"code"}},
+		{Name: "ide"},
+		{Name: "ide", Apps: []string{"code"}, Disabled: []string{"launcher"},
+			Remap: map[string][]string{"launcher": {"<Super>A"}}},
+		{Name: "ide", Apps: []string{"code"}, Remap: map[string][]string{"launcher": {"<Super>Foo"}}},
+		{Name: "ide", Apps: []string{"code"}, Remap: map[string][]string{"launcher": {"<Super>W L"}}},
+	} {
+		assert.Error(t, invalid.Check(), invalid.Name)
+	}
+}
+
+func TestProfileMatch(t *testing.T) {
+	p := &Profile{
+		Name:     "ide",
+		Apps:     []string{"code", "jetbrains-idea"},
+		Disabled: []string{"terminal", "launcher"},
+		Remap:    map[string][]string{"switch-next-ws": {"<Super>N"}},
+	}
+	assert.True(t, p.Match("", "Code"))
+	assert.True(t, p.Match("jetbrains-idea"))
+	assert.False(t, p.Match("", "deepin-terminal"))
+	assert.False(t, p.Match())
+
+	assert.True(t, p.IsDisabled("terminal"))
+	assert.False(t, p.IsDisabled("switch-next-ws"))
+
+	ids := p.GetShortcutIds()
+	sort.Strings(ids)
+	assert.Equal(t, []string{"launcher", "switch-next-ws", "terminal"}, ids)
+}
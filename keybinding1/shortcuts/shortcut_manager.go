@@ -122,6 +122,18 @@ type ShortcutManager struct {
 	keyKeystrokeMapMu sync.Mutex
 	keySymbols        *keysyms.KeySymbols
 
+	// 多步组合键和模式中的按键，value 为抓取的多步组合键第一步的按键
+	keymapKeystrokes map[*Keystroke][]Key
+	prefixKeyCount   map[Key]int
+	keymapState      *keymapState
+	keymapMu         sync.Mutex
+
+	// 当前生效的应用快捷键配置，以及被禁用的快捷键和重新映射后抓取的按键
+	profile           *Profile
+	profileShortcuts  []Shortcut
+	profileKeystrokes []*Keystroke
+	profileMu         sync.Mutex
+
 	recordEnable        bool
 	recordEnableMu      sync.Mutex
 	recordContext       record.Context
@@ -179,6 +191,8 @@ func NewShortcutManager(conn *x.Conn, keySymbols *keysyms.KeySymbols, eventCb Ke
 		keySymbols:               keySymbols,
 		recordEnable:             true,
 		keyKeystrokeMap:          make(map[Key]*Keystroke),
+		keymapKeystrokes:         make(map[*Keystroke][]Key),
+		prefixKeyCount:           make(map[Key]int),
 		layoutChanged:            make(chan struct{}),
 		pinyinEnabled:            isZH(),
 		WaylandCustomShortCutMap: make(map[string]string),
@@ -538,6 +552,11 @@ func (sm *ShortcutManager) storeConflictingKeystroke(ks *Keystroke) {
 }
 
 func (sm *ShortcutManager) grabKeystroke(shortcut Shortcut, ks *Keystroke, dummy bool) {
+	if ks.IsKeymapBinding() {
+		sm.grabKeymapKeystroke(shortcut, ks, dummy)
+		return
+	}
+
 	keyList, err := ks.ToKeyList(sm.keySymbols)
 	if err != nil {
 		logger.Debugf("grabKeystroke failed, shortcut: %v, ks: %v, err: %v", shortcut.GetId(), ks, err)
@@ -553,6 +572,12 @@ func (sm *ShortcutManager) grabKeystroke(shortcut Shortcut, ks *Keystroke, dummy
 
 		logger.Debugf("grabKeystroke shortcut: %s, ks: %s, key: %s, dummy: %v", shortcut.GetId(), ks, key, dummy)
 
+		if !ok && sm.isChordPrefixKey(key) {
+			conflictCount++
+			logger.Debugf("key %v is the prefix of key sequence", key)
+			continue
+		}
+
 		if ok {
 			// conflict
 			if conflictKeystroke.Shortcut != nil {
@@ -593,6 +618,11 @@ func (sm *ShortcutManager) grabKeystroke(shortcut Shortcut, ks *Keystroke, dummy
 }
 
 func (sm *ShortcutManager) ungrabKeystroke(ks *Keystroke, dummy bool) {
+	if ks.IsKeymapBinding() {
+		sm.ungrabKeymapKeystroke(ks, dummy)
+		return
+	}
+
 	keyList, err := ks.ToKeyList(sm.keySymbols)
 	if err != nil {
 		logger.Debug(err)
@@ -705,16 +735,20 @@ func (sm *ShortcutManager) UngrabAll() {
 	count := len(sm.keyKeystrokeMap)
 	sm.keyKeystrokeMap = make(map[Key]*Keystroke, count)
 	sm.keyKeystrokeMapMu.Unlock()
+
+	sm.ungrabAllKeymapKeystrokes()
+	sm.resetProfileKeystrokes()
 }
 
 func (sm *ShortcutManager) GrabAll() {
 	sm.idShortcutMapMu.Lock()
-	defer sm.idShortcutMapMu.Unlock()
-
 	// re-grab all shortcuts
 	for _, shortcut := range sm.idShortcutMap {
 		sm.grabShortcut(shortcut)
 	}
+	sm.idShortcutMapMu.Unlock()
+
+	sm.reapplyProfile()
 }
 
 func (sm *ShortcutManager) regrabAll() {
@@ -779,6 +813,10 @@ func (sm *ShortcutManager) handleKeyEvent(pressed bool, detail x.Keycode, state
 	logger.Debug("event key:", key)
 
 	if pressed {
+		// 按键模式或者多步组合键激活时，按键由 keymap 处理
+		if sm.handleKeymapKeyEvent(key) {
+			return
+		}
 		// key press
 		sm.emitKeyEvent(Modifiers(state), key)
 	}
@@ -804,6 +842,8 @@ func (sm *ShortcutManager) emitKeyEvent(mods Modifiers, key Key) {
 		}
 
 		sm.callEventCallback(keyEvent)
+	} else if sm.startChord(key) {
+		logger.Debug("wait for next key of key sequence")
 	} else {
 		logger.Debug("keystroke not found")
 	}
@@ -1041,6 +1081,23 @@ func (sm *ShortcutManager) GetByUid(uid string) Shortcut {
 // ret0: Conflicting keystroke
 // ret1: error
 func (sm *ShortcutManager) FindConflictingKeystroke(ks *Keystroke) (*Keystroke, error) {
+	if ks.IsKeymapBinding() {
+		return sm.findKeymapConflictingKeystroke(ks)
+	}
+
+	conflictKeystroke, err := sm.findGrabbedKeystroke(ks)
+	if err != nil || conflictKeystroke != nil {
+		return conflictKeystroke, err
+	}
+
+	// 与多步组合键的第一步冲突
+	sm.keymapMu.Lock()
+	defer sm.keymapMu.Unlock()
+	return sm.findKeymapConflictLocked(ks), nil
+}
+
+// findGrabbedKeystroke 查找抓取了 ks 所有按键的快捷键
+func (sm *ShortcutManager) findGrabbedKeystroke(ks *Keystroke) (*Keystroke, error) {
 	keyList, err := ks.ToKeyList(sm.keySymbols)
 	if err != nil {
 		return nil, err
@@ -1053,7 +1110,6 @@ func (sm *ShortcutManager) FindConflictingKeystroke(ks *Keystroke) (*Keystroke,
 	logger.Debug("key list:", keyList)
 
 	sm.keyKeystrokeMapMu.Lock()
-	defer sm.keyKeystrokeMapMu.Unlock()
 	var count = 0
 	var ks1 *Keystroke
 	for _, key := range keyList {
@@ -1064,6 +1120,7 @@ func (sm *ShortcutManager) FindConflictingKeystroke(ks *Keystroke) (*Keystroke,
 		count++
 		ks1 = tmp
 	}
+	sm.keyKeystrokeMapMu.Unlock()
 
 	if count == len(keyList) {
 		return ks1, nil
@@ -1303,27 +1360,25 @@ func (sm *ShortcutManager) AddCustom(csm *CustomShortcutManager, wmObj wm.Wm) {
 	if _useWayland {
 		for _, shortcut := range csm.List() {
 			id := shortcut.GetId()
-			keystrokesStrv := shortcut.getKeystrokesStrv()
-			action := shortcut.GetAction()
-			var cmd string
-			switch arg := action.Arg.(type) {
-			case *ActionExecCmdArg:
-				cmd = arg.Cmd
-			case string:
-				cmd = arg
+			if !isWaylandSupported(shortcut) {
+				logger.Warning("key sequence and keymap mode are not supported on wayland:", id)
+				continue
 			}
-			if cmd == "" {
-				logger.Warning(ErrTypeAssertionFail, id, action)
+			keystrokesStrv := shortcut.getKeystrokesStrv()
+			cmd, macro, ok := getWaylandCustomCmd(shortcut)
+			if !ok {
+				logger.Warning(ErrTypeAssertionFail, id, shortcut.GetAction())
 				continue
 			}
-			logger.Debugf("customshort: %+v", cmd)
+			logger.Debugf("customshort: %+v, macro: %+v", cmd, macro)
 			ok, err := setShortForWayland(shortcut, wmObj)
 			if !ok {
 				logger.Warning("failed to setShortForWayland:", err)
 				continue
 			}
+			// 宏由 runCustomShortcutMacro 处理，这里只记录原来的命令
 			sm.WaylandCustomShortCutMap[id+"-cs"] = cmd
-			cs := newCustomShort(id, id, cmd, keystrokesStrv, wmObj, csm)
+			cs := newCustomShort(id, id, cmd, macro, keystrokesStrv, wmObj, csm)
 			sm.addWithoutLock(cs)
 		}
 	} else {
@@ -1333,6 +1388,38 @@ func (sm *ShortcutManager) AddCustom(csm *CustomShortcutManager, wmObj wm.Wm) {
 	}
 }
 
+// getWaylandCustomCmd 返回自定义快捷键在 Wayland 中要执行的命令和宏，两者都没有时 ok 为 false
+func getWaylandCustomCmd(shortcut Shortcut) (cmd, macro string, ok bool) {
+	action := shortcut.GetAction()
+	switch arg := action.Arg.(type) {
+	case *ActionExecCmdArg:
+		cmd = arg.Cmd
+	case string:
+		cmd = arg
+	}
+	if action.Type == ActionTypeMacro {
+		cs, isCustom := shortcut.(*CustomShortcut)
+		if !isCustom {
+			return "", "", false
+		}
+		return cs.Cmd, cs.Macro, true
+	}
+	return cmd, "", cmd != ""
+}
+
+// isWaylandSupported 判断快捷键是否可以交给 KWin 处理，多步组合键和按键模式只在 X11 中支持
+func isWaylandSupported(shortcut Shortcut) bool {
+	if shortcut.GetAction().Type == ActionTypeEnterMode {
+		return false
+	}
+	for _, ks := range shortcut.GetKeystrokes() {
+		if ks.IsKeymapBinding() {
+			return false
+		}
+	}
+	return true
+}
+
 func setShortForWayland(shortcut Shortcut, wmObj wm.Wm) (bool, error) {
 	id := shortcut.GetId()
 	isCustom := shortcut.GetType() == ShortcutTypeCustom
@@ -16,10 +16,7 @@ var _loader *Loader
 
 func getLoader() *Loader {
 	loaderInitializer.Do(func() {
-		_loader = &Loader{
-			modules: Modules{},
-			log:     log.NewLogger("daemon/loader"),
-		}
+		_loader = newLoader()
 	})
 	return _loader
 }
@@ -50,6 +47,18 @@ func SetLogLevel(pri log.Priority) {
 	getLoader().SetLogLevel(pri)
 }
 
+func StartModule(name string) error {
+	return getLoader().StartModule(name)
+}
+
+func StopModule(name string) error {
+	return getLoader().StopModule(name)
+}
+
+func RestartModule(name string) error {
+	return getLoader().RestartModule(name)
+}
+
 func EnableModules(enablingModules []string, disableModules []string, flag EnableFlag) error {
 	return getLoader().EnableModules(enablingModules, disableModules, flag)
 }
@@ -75,8 +84,8 @@ func StartAll() {
 
 // TODO: check dependencies
 func StopAll() {
-	modules := getLoader().List()
-	for _, module := range modules {
-		_ = module.Enable(false)
+	l := getLoader()
+	for _, module := range l.List() {
+		_ = l.disableModule(module)
 	}
 }
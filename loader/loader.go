@@ -31,6 +31,7 @@ const (
 	ErrorMissingModule
 	ErrorInternalError
 	ErrorConflict
+	ErrorModulePanic
 )
 
 type EnableError struct {
@@ -52,15 +53,27 @@ func (e *EnableError) Error() string {
 		return fmt.Sprintf("%s started failed: %s", e.ModuleName, e.detail)
 	case ErrorConflict:
 		return fmt.Sprintf("tring to enable disabled module(%s)", e.ModuleName)
+	case ErrorModulePanic:
+		return fmt.Sprintf("%s panicked: %s", e.ModuleName, e.detail)
 	}
 	panic("EnableError: Unknown Error, Should not be reached")
 }
 
 type Loader struct {
-	modules Modules
-	log     *log.Logger
-	lock    sync.Mutex
-	service *dbusutil.Service
+	modules  Modules
+	log      *log.Logger
+	lock     sync.Mutex
+	service  *dbusutil.Service
+	manager  *Manager
+	watchdog *watchdog
+}
+
+func newLoader() *Loader {
+	return &Loader{
+		modules:  Modules{},
+		log:      log.NewLogger("daemon/loader"),
+		watchdog: newWatchdog(),
+	}
 }
 
 func (l *Loader) SetLogLevel(pri log.Priority) {
@@ -140,41 +153,242 @@ func (l *Loader) EnableModules(enablingModules []string, disableModules []string
 	l.log.Infof("topo sort done, cost add up to %s", duration)
 
 	// enable modules
+	var wg sync.WaitGroup
 	for _, node := range nodes {
 		if node == nil {
 			continue
 		}
-		module := l.modules[node.ID]
+		// 使用 EnableFlagIgnoreMissingModule 时，不存在的依赖也在 dag 中
+		module, ok := l.modules[node.ID]
+		if !ok {
+			continue
+		}
 		name := node.ID
 
+		wg.Add(1)
 		go func() {
+			defer wg.Done()
+			if module.IsEnable() {
+				return
+			}
 			l.log.Info("enable module", name)
 			startTime := time.Now()
 
 			// wait for its dependency
-			l.WaitDependencies(module)
+			if dependency, ok := l.waitDependencies(module); !ok {
+				l.log.Warningf("skip module %s, its dependency %s failed to start", name, dependency)
+				l.markSkipped(module, dependency)
+				return
+			}
 			endTime := time.Now()
 			duration := endTime.Sub(startTime)
 			l.log.Info("module", name, "wait done, cost", duration)
 
-			err := module.Enable(true)
+			err := l.enableModule(module)
 			endTime = time.Now()
 			duration = endTime.Sub(startTime)
 			if err != nil {
 				l.log.Errorf("enable module %s failed: %s, cost %s", name, err, duration)
+				l.handleModuleFailure(name, err)
 			} else {
 				l.log.Infof("enable module %s done cost %s", name, duration)
 			}
 		}()
 	}
-
-	for _, n := range nodes {
-		m := l.modules[n.ID]
-		m.WaitEnable()
-	}
+	wg.Wait()
 
 	endTime = time.Now()
 	duration = endTime.Sub(startTime)
 	l.log.Infof("enable modules done, cost add up to %s", duration)
 	return nil
 }
+
+// sortedModules 返回所有已注册模块的名字，被依赖的模块总是排在依赖它的模块之前
+func (l *Loader) sortedModules() ([]string, error) {
+	names := make([]string, 0, len(l.modules))
+	for name := range l.modules {
+		names = append(names, name)
+	}
+
+	dag, err := NewDAGBuilder(l, names, nil, EnableFlagIgnoreMissingModule).Execute()
+	if err != nil {
+		return nil, err
+	}
+	nodes, ok := dag.TopologicalDag()
+	if !ok {
+		return nil, &EnableError{Code: ErrorCircleDependencies}
+	}
+
+	result := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		if _, ok := l.modules[node.ID]; ok {
+			result = append(result, node.ID)
+		}
+	}
+	return result, nil
+}
+
+// dependents 返回直接或间接依赖 name 的模块，顺序与 order 一致
+func (l *Loader) dependents(order []string, name string) []string {
+	affected := map[string]struct{}{name: {}}
+	var result []string
+	for _, moduleName := range order {
+		if moduleName == name {
+			continue
+		}
+		for _, dependency := range l.modules[moduleName].GetDependencies() {
+			if _, ok := affected[dependency]; ok {
+				affected[moduleName] = struct{}{}
+				result = append(result, moduleName)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// dependencies 返回 name 直接或间接依赖的模块，顺序与 order 一致
+func (l *Loader) dependencies(order []string, name string) ([]string, error) {
+	needed := map[string]struct{}{}
+	queue := []string{name}
+	for len(queue) != 0 {
+		moduleName := queue[0]
+		queue = queue[1:]
+		for _, dependency := range l.modules[moduleName].GetDependencies() {
+			if _, ok := l.modules[dependency]; !ok {
+				return nil, &EnableError{ModuleName: moduleName, Code: ErrorNoDependencies, detail: dependency}
+			}
+			if _, ok := needed[dependency]; !ok {
+				needed[dependency] = struct{}{}
+				queue = append(queue, dependency)
+			}
+		}
+	}
+
+	var result []string
+	for _, moduleName := range order {
+		if _, ok := needed[moduleName]; ok {
+			result = append(result, moduleName)
+		}
+	}
+	return result, nil
+}
+
+// stopModule 停止 name 及所有依赖它的模块，依赖者先于被依赖者停止。
+// 返回值为实际被停止的模块，按依赖顺序排列。
+func (l *Loader) stopModule(order []string, name string) ([]string, error) {
+	chain := append([]string{name}, l.dependents(order, name)...)
+	var stopped []string
+	for i := len(chain) - 1; i >= 0; i-- {
+		module := l.modules[chain[i]]
+		if !module.IsEnable() {
+			continue
+		}
+		l.log.Info("stop module", chain[i])
+		err := l.disableModule(module)
+		if err != nil {
+			if _, ok := err.(*EnableError); ok {
+				return stopped, err
+			}
+			return stopped, &EnableError{ModuleName: chain[i], Code: ErrorInternalError, detail: err.Error()}
+		}
+		stopped = append([]string{chain[i]}, stopped...)
+	}
+	return stopped, nil
+}
+
+// startModule 启动 name，尚未启动的依赖会先被启动
+func (l *Loader) startModule(order []string, name string) error {
+	dependencies, err := l.dependencies(order, name)
+	if err != nil {
+		return err
+	}
+
+	for _, moduleName := range append(dependencies, name) {
+		module := l.modules[moduleName]
+		if module.IsEnable() {
+			continue
+		}
+		startTime := time.Now()
+		err := l.enableModule(module)
+		if err != nil {
+			if _, ok := err.(*EnableError); ok {
+				return err
+			}
+			return &EnableError{ModuleName: moduleName, Code: ErrorInternalError, detail: err.Error()}
+		}
+		l.log.Infof("enable module %s done cost %s", moduleName, time.Since(startTime))
+	}
+	return nil
+}
+
+func (l *Loader) StartModule(name string) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if _, ok := l.modules[name]; !ok {
+		return &EnableError{ModuleName: name, Code: ErrorMissingModule}
+	}
+	order, err := l.sortedModules()
+	if err != nil {
+		return err
+	}
+	return l.startModule(order, name)
+}
+
+func (l *Loader) StopModule(name string) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if _, ok := l.modules[name]; !ok {
+		return &EnableError{ModuleName: name, Code: ErrorMissingModule}
+	}
+	order, err := l.sortedModules()
+	if err != nil {
+		return err
+	}
+	_, err = l.stopModule(order, name)
+	return err
+}
+
+// RestartModule 先停止 name 及依赖它的模块，再按依赖顺序重新启动它们
+func (l *Loader) RestartModule(name string) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if _, ok := l.modules[name]; !ok {
+		return &EnableError{ModuleName: name, Code: ErrorMissingModule}
+	}
+	// 模块失败后等待自动重启期间，重启由 watchdog 完成
+	if l.watchdog.isRestarting(name) {
+		return fmt.Errorf("module %s is waiting to be restarted", name)
+	}
+	order, err := l.sortedModules()
+	if err != nil {
+		return err
+	}
+
+	stopped, err := l.stopModule(order, name)
+	if err != nil {
+		return err
+	}
+
+	err = l.startModule(order, name)
+	if err != nil {
+		return err
+	}
+	for _, moduleName := range stopped {
+		err = l.startModule(order, moduleName)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *Loader) notifyStateChanged(name string, state ModuleState) {
+	l.watchdog.broadcast()
+	if l.manager != nil {
+		l.manager.emitModuleStateChanged(name, state)
+	}
+}
@@ -5,6 +5,7 @@
 package loader
 
 import (
+	"errors"
 	"math/rand"
 	"strings"
 	"testing"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/linuxdeepin/go-lib/log"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type Test_Module struct {
@@ -87,10 +89,7 @@ func Test_Loader(t *testing.T) {
 		},
 	}
 	for _, data := range testItems {
-		_loader = &Loader{
-			modules: Modules{},
-			log:     log.NewLogger("daemon/loader"),
-		}
+		_loader = newLoader()
 		allModules := []string{}
 		for name, module := range data.input {
 			Register(module)
@@ -100,3 +99,195 @@ func Test_Loader(t *testing.T) {
 		assert.Equal(t, err, data.output)
 	}
 }
+
+type recordModule struct {
+	*ModuleBase
+	dependencies []string
+	records      *[]string
+}
+
+func newRecordModule(name string, dependencies []string, records *[]string) *recordModule {
+	m := &recordModule{
+		dependencies: dependencies,
+		records:      records,
+	}
+	m.ModuleBase = NewModuleBase(name, m, log.NewLogger(name))
+	return m
+}
+
+func (m *recordModule) GetDependencies() []string {
+	return m.dependencies
+}
+
+func (m *recordModule) Start() error {
+	*m.records = append(*m.records, "start "+m.Name())
+	return nil
+}
+
+func (m *recordModule) Stop() error {
+	*m.records = append(*m.records, "stop "+m.Name())
+	return nil
+}
+
+func Test_RestartModule(t *testing.T) {
+	var records []string
+	_loader = newLoader()
+	Register(newRecordModule("a", nil, &records))
+	Register(newRecordModule("b", []string{"a"}, &records))
+	Register(newRecordModule("c", []string{"b"}, &records))
+	Register(newRecordModule("d", nil, &records))
+
+	err := EnableModules([]string{"a", "b", "c", "d"}, nil, EnableFlagNone)
+	assert.NoError(t, err)
+	assert.Equal(t, ModuleStateRunning, GetModule("c").State())
+
+	records = nil
+	err = RestartModule("a")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"stop c", "stop b", "stop a", "start a", "start b", "start c"}, records)
+
+	records = nil
+	err = StopModule("b")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"stop c", "stop b"}, records)
+	assert.Equal(t, ModuleStateStopped, GetModule("b").State())
+	assert.True(t, GetModule("a").IsEnable())
+
+	records = nil
+	err = StartModule("c")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"start b", "start c"}, records)
+
+	err = StartModule("missing")
+	assert.Equal(t, &EnableError{ModuleName: "missing", Code: ErrorMissingModule}, err)
+}
+
+func Test_EnableModulesIgnoreMissing(t *testing.T) {
+	var records []string
+	_loader = newLoader()
+	Register(newRecordModule("a", []string{"missing"}, &records))
+
+	// 非调试日志级别下也要跳过不存在的依赖
+	assert.NotEqual(t, log.LevelDebug, _loader.log.GetLogLevel())
+	err := EnableModules([]string{"a"}, nil, EnableFlagIgnoreMissingModule)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"start a"}, records)
+
+	err = EnableModules([]string{"a"}, nil, EnableFlagNone)
+	assert.Equal(t, &EnableError{ModuleName: "missing", Code: ErrorMissingModule}, err)
+}
+
+type flakyModule struct {
+	*ModuleBase
+	dependencies []string
+	failures     int
+	stopPanic    bool
+}
+
+func newFlakyModule(name string, dependencies []string, failures int) *flakyModule {
+	m := &flakyModule{
+		dependencies: dependencies,
+		failures:     failures,
+	}
+	m.ModuleBase = NewModuleBase(name, m, log.NewLogger(name))
+	return m
+}
+
+func (m *flakyModule) GetDependencies() []string {
+	return m.dependencies
+}
+
+func (m *flakyModule) Start() error {
+	if m.failures > 0 {
+		m.failures--
+		panic("start failed")
+	}
+	return nil
+}
+
+func (m *flakyModule) Stop() error {
+	if m.stopPanic {
+		panic("stop failed")
+	}
+	return nil
+}
+
+func Test_StopAllRecover(t *testing.T) {
+	_loader = newLoader()
+	m := newFlakyModule("p", nil, 0)
+	m.stopPanic = true
+	Register(m)
+	Register(newFlakyModule("q", nil, 0))
+
+	err := EnableModules([]string{"p", "q"}, nil, EnableFlagNone)
+	assert.NoError(t, err)
+
+	assert.NotPanics(t, StopAll)
+	assert.Equal(t, ModuleStateStopped, GetModule("q").State())
+	assert.Equal(t, ModuleStateFailed, GetModule("p").State())
+	lastErr, ok := GetModule("p").LastError().(*EnableError)
+	assert.True(t, ok)
+	assert.Equal(t, ErrorModulePanic, lastErr.Code)
+}
+
+func Test_Watchdog(t *testing.T) {
+	// 避免单独运行时 getLoader 替换掉测试使用的 loader
+	loaderInitializer.Do(func() {})
+	_loader = newLoader()
+	_loader.watchdog.initOnce.Do(func() {})
+	_loader.watchdog.policy = RestartPolicy{
+		AutoRestart:  true,
+		MaxRetries:   3,
+		InitialDelay: 10 * time.Millisecond,
+		MaxDelay:     50 * time.Millisecond,
+	}
+	// 由测试控制重启的时机
+	var scheduled []func()
+	var delays []time.Duration
+	_loader.watchdog.schedule = func(delay time.Duration, fn func()) {
+		delays = append(delays, delay)
+		scheduled = append(scheduled, fn)
+	}
+	runScheduled := func() {
+		require.Len(t, scheduled, 1)
+		fn := scheduled[0]
+		scheduled = nil
+		fn()
+	}
+	Register(newFlakyModule("p", nil, 2))
+	Register(newFlakyModule("q", []string{"p"}, 0))
+
+	err := EnableModules([]string{"p", "q"}, nil, EnableFlagNone)
+	assert.NoError(t, err)
+	assert.Equal(t, ModuleStateFailed, GetModule("q").State())
+	lastErr, ok := GetModule("p").LastError().(*EnableError)
+	assert.True(t, ok)
+	assert.Equal(t, ErrorModulePanic, lastErr.Code)
+	assert.True(t, _loader.watchdog.isRestarting("p"))
+
+	// 等待自动重启期间不接受手动重启，再次失败也不重复安排重启
+	assert.Error(t, RestartModule("p"))
+	_loader.handleModuleFailure("p", errors.New("crashed again"))
+	assert.Len(t, scheduled, 1)
+
+	// 第一次重启失败，第二次重启成功后启动依赖它的模块
+	runScheduled()
+	assert.Equal(t, ModuleStateFailed, GetModule("p").State())
+	runScheduled()
+	assert.True(t, GetModule("p").IsEnable())
+	assert.True(t, GetModule("q").IsEnable())
+	assert.False(t, _loader.watchdog.isRestarting("p"))
+	assert.Equal(t, []time.Duration{10 * time.Millisecond, 20 * time.Millisecond}, delays)
+	assert.Equal(t, uint32(3), _loader.watchdog.crashCount("p"))
+}
+
+func Test_RestartPolicyDelay(t *testing.T) {
+	policy := RestartPolicy{
+		InitialDelay: time.Second,
+		MaxDelay:     5 * time.Second,
+	}
+	assert.Equal(t, time.Second, policy.delay(1))
+	assert.Equal(t, 2*time.Second, policy.delay(2))
+	assert.Equal(t, 4*time.Second, policy.delay(3))
+	assert.Equal(t, 5*time.Second, policy.delay(4))
+}
@@ -0,0 +1,180 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package loader
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/linuxdeepin/go-lib/dbusutil"
+)
+
+//go:generate dbusutil-gen em -type Manager
+
+const (
+	ManagerDBusPath      = "/org/deepin/dde/Daemon1/Loader1"
+	ManagerDBusInterface = "org.deepin.dde.Daemon1.Loader1"
+)
+
+// ModuleInfo 是 ListModules 和 GetModule 返回的 JSON 中单个模块的信息
+type ModuleInfo struct {
+	Name         string
+	State        string
+	Enabled      bool
+	Dependencies []string
+	// 最近一次成功启动的耗时，单位为毫秒
+	StartDuration int64
+	LastError     string
+	// 模块启动失败或者崩溃的次数
+	CrashCount uint32
+}
+
+// Manager 通过 D-Bus 暴露 loader 中的模块，用于在运行时查看和启停单个模块
+type Manager struct {
+	loader  *Loader
+	service *dbusutil.Service
+
+	//nolint
+	signals *struct {
+		ModuleStateChanged struct {
+			name  string
+			state string
+		}
+
+		ModuleCrashed struct {
+			name       string
+			reason     string
+			crashCount uint32
+		}
+	}
+}
+
+func (*Manager) GetInterfaceName() string {
+	return ManagerDBusInterface
+}
+
+func (m *Manager) newModuleInfo(module Module) ModuleInfo {
+	info := ModuleInfo{
+		Name:          module.Name(),
+		State:         module.State().String(),
+		Enabled:       module.IsEnable(),
+		Dependencies:  module.GetDependencies(),
+		StartDuration: module.StartDuration().Milliseconds(),
+		CrashCount:    m.loader.watchdog.crashCount(module.Name()),
+	}
+	if info.Dependencies == nil {
+		info.Dependencies = []string{}
+	}
+	if err := module.LastError(); err != nil {
+		info.LastError = err.Error()
+	}
+	return info
+}
+
+// checkCaller 只允许与 daemon 同一用户的进程启停模块，
+// 系统总线上即只允许 root。
+func (m *Manager) checkCaller(sender dbus.Sender) error {
+	uid, err := m.service.GetConnUID(string(sender))
+	if err != nil {
+		return err
+	}
+	if int(uid) != os.Getuid() {
+		return errors.New("permission denied")
+	}
+	return nil
+}
+
+func (m *Manager) ListModules() (modules string, busErr *dbus.Error) {
+	list := m.loader.List()
+	infos := make([]ModuleInfo, 0, len(list))
+	for _, module := range list {
+		infos = append(infos, m.newModuleInfo(module))
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+
+	data, err := json.Marshal(infos)
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	return string(data), nil
+}
+
+func (m *Manager) GetModule(name string) (module string, busErr *dbus.Error) {
+	mod := m.loader.GetModule(name)
+	if mod == nil {
+		return "", dbusutil.ToError(&EnableError{ModuleName: name, Code: ErrorMissingModule})
+	}
+
+	data, err := json.Marshal(m.newModuleInfo(mod))
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	return string(data), nil
+}
+
+func (m *Manager) StartModule(sender dbus.Sender, name string) *dbus.Error {
+	err := m.checkCaller(sender)
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+	m.loader.log.Infof("%s request to start module %s", sender, name)
+	return dbusutil.ToError(m.loader.StartModule(name))
+}
+
+func (m *Manager) StopModule(sender dbus.Sender, name string) *dbus.Error {
+	err := m.checkCaller(sender)
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+	m.loader.log.Infof("%s request to stop module %s", sender, name)
+	return dbusutil.ToError(m.loader.StopModule(name))
+}
+
+func (m *Manager) RestartModule(sender dbus.Sender, name string) *dbus.Error {
+	err := m.checkCaller(sender)
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+	m.loader.log.Infof("%s request to restart module %s", sender, name)
+	return dbusutil.ToError(m.loader.RestartModule(name))
+}
+
+func (m *Manager) emitModuleStateChanged(name string, state ModuleState) {
+	err := m.service.Emit(m, "ModuleStateChanged", name, state.String())
+	if err != nil {
+		m.loader.log.Warning(err)
+	}
+}
+
+func (m *Manager) emitModuleCrashed(name string, reason error, crashCount uint32) {
+	err := m.service.Emit(m, "ModuleCrashed", name, reason.Error(), crashCount)
+	if err != nil {
+		m.loader.log.Warning(err)
+	}
+}
+
+// ExportManager 将模块管理接口导出到 SetService 设置的 service 上
+func ExportManager() error {
+	l := getLoader()
+	if l.service == nil {
+		return errors.New("loader service is not set")
+	}
+
+	m := &Manager{
+		loader:  l,
+		service: l.service,
+	}
+	err := l.service.Export(ManagerDBusPath, m)
+	if err != nil {
+		return err
+	}
+	l.manager = m
+	return nil
+}
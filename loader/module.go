@@ -7,6 +7,7 @@ package loader
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/godbus/dbus/v5"
 	ConfigManager "github.com/linuxdeepin/go-dbus-factory/org.desktopspec.ConfigManager"
@@ -22,6 +23,10 @@ type Module interface {
 	SetLogLevel(log.Priority)
 	LogLevel() log.Priority
 	WaitEnable() // TODO: should this function return when modules enable failed?
+	State() ModuleState
+	StartDuration() time.Duration
+	LastError() error
+	markFailed(error)
 	ModuleImpl
 }
 
@@ -32,12 +37,46 @@ type ModuleImpl interface {
 	Stop() error
 }
 
+type ModuleState uint32
+
+const (
+	ModuleStateStopped ModuleState = iota
+	ModuleStateStarting
+	ModuleStateRunning
+	ModuleStateStopping
+	ModuleStateFailed
+)
+
+func (s ModuleState) String() string {
+	switch s {
+	case ModuleStateStopped:
+		return "stopped"
+	case ModuleStateStarting:
+		return "starting"
+	case ModuleStateRunning:
+		return "running"
+	case ModuleStateStopping:
+		return "stopping"
+	case ModuleStateFailed:
+		return "failed"
+	}
+	return fmt.Sprintf("ModuleState(%d)", uint32(s))
+}
+
 type ModuleBase struct {
 	impl    ModuleImpl
 	enabled bool
 	name    string
 	log     *log.Logger
 	wg      sync.WaitGroup
+
+	// 模块可以在运行时被多次启停，以下 Once 保证只在第一次启动成功时执行
+	wgDoneOnce sync.Once
+	dsgLogOnce sync.Once
+	statusMu   sync.Mutex
+	state      ModuleState
+	startCost  time.Duration
+	lastErr    error
 }
 
 const (
@@ -175,34 +214,74 @@ func (d *ModuleBase) setupDSGLogLeveL() {
 	})
 }
 
+func (d *ModuleBase) setState(state ModuleState, err error) {
+	d.statusMu.Lock()
+	changed := d.state != state
+	d.state = state
+	if err != nil {
+		d.lastErr = err
+	}
+	d.statusMu.Unlock()
+
+	if changed {
+		getLoader().notifyStateChanged(d.name, state)
+	}
+}
+
+func (d *ModuleBase) markFailed(err error) {
+	d.setState(ModuleStateFailed, err)
+}
+
 func (d *ModuleBase) doEnable(enable bool) error {
 	if d.impl != nil {
 		fn := d.impl.Stop
+		state := ModuleStateStopping
 		if enable {
 			fn = d.impl.Start
+			state = ModuleStateStarting
 		}
+		d.setState(state, nil)
 
+		startTime := time.Now()
 		if err := fn(); err != nil {
+			if enable {
+				d.setState(ModuleStateFailed, err)
+			} else {
+				d.setState(ModuleStateRunning, err)
+			}
 			return err
 		}
 
 		if enable {
-			d.setupDSGLogLeveL()
-			d.wg.Done()
+			d.statusMu.Lock()
+			d.startCost = time.Since(startTime)
+			d.statusMu.Unlock()
+
+			d.dsgLogOnce.Do(d.setupDSGLogLeveL)
+			d.wgDoneOnce.Do(d.wg.Done)
 		}
 	}
+	d.statusMu.Lock()
 	d.enabled = enable
+	d.statusMu.Unlock()
+	if enable {
+		d.setState(ModuleStateRunning, nil)
+	} else {
+		d.setState(ModuleStateStopped, nil)
+	}
 	return nil
 }
 
 func (d *ModuleBase) Enable(enable bool) error {
-	if d.enabled == enable {
+	if d.IsEnable() == enable {
 		return fmt.Errorf("%s daemon is already started", d.name)
 	}
 	return d.doEnable(enable)
 }
 
 func (d *ModuleBase) IsEnable() bool {
+	d.statusMu.Lock()
+	defer d.statusMu.Unlock()
 	return d.enabled
 }
 
@@ -210,6 +289,26 @@ func (d *ModuleBase) WaitEnable() {
 	d.wg.Wait()
 }
 
+func (d *ModuleBase) State() ModuleState {
+	d.statusMu.Lock()
+	defer d.statusMu.Unlock()
+	return d.state
+}
+
+// StartDuration 返回最近一次成功启动所花费的时间
+func (d *ModuleBase) StartDuration() time.Duration {
+	d.statusMu.Lock()
+	defer d.statusMu.Unlock()
+	return d.startCost
+}
+
+// LastError 返回最近一次启动或停止失败的错误，没有失败过时返回 nil
+func (d *ModuleBase) LastError() error {
+	d.statusMu.Lock()
+	defer d.statusMu.Unlock()
+	return d.lastErr
+}
+
 func (d *ModuleBase) Name() string {
 	return d.name
 }
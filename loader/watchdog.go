@@ -0,0 +1,332 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package loader
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/linuxdeepin/dde-daemon/common/dconfig"
+)
+
+const (
+	dsettingsWatchdogResource = "org.deepin.dde.daemon.watchdog"
+
+	dsettingsKeyAutoRestart         = "autoRestart"
+	dsettingsKeyRestartMaxRetries   = "restartMaxRetries"
+	dsettingsKeyRestartInitialDelay = "restartInitialDelay"
+	dsettingsKeyRestartMaxDelay     = "restartMaxDelay"
+)
+
+// RestartPolicy 描述模块启动失败或崩溃后的自动重启策略，
+// 第 n 次重试前等待 InitialDelay * 2^(n-1)，但不超过 MaxDelay。
+type RestartPolicy struct {
+	AutoRestart  bool
+	MaxRetries   int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+}
+
+var defaultRestartPolicy = RestartPolicy{
+	AutoRestart:  true,
+	MaxRetries:   3,
+	InitialDelay: time.Second,
+	MaxDelay:     30 * time.Second,
+}
+
+func (p RestartPolicy) delay(retries int) time.Duration {
+	d := p.InitialDelay
+	for i := 1; i < retries; i++ {
+		d *= 2
+		if d >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	if d > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return d
+}
+
+type moduleHealth struct {
+	crashes uint32
+	// 自上次成功启动以来连续失败的次数
+	retries int
+	// 是否已经安排了一次重启
+	restarting bool
+}
+
+type watchdog struct {
+	mu       sync.Mutex
+	policy   RestartPolicy
+	health   map[string]*moduleHealth
+	skipped  map[string]struct{}
+	initOnce sync.Once
+
+	// 模块状态变化时广播，用于等待依赖的模块启动完成或失败
+	stateCond *sync.Cond
+	// 延迟 delay 后执行重启，测试中替换以控制重启的时机
+	schedule func(delay time.Duration, fn func())
+}
+
+func newWatchdog() *watchdog {
+	return &watchdog{
+		policy:    defaultRestartPolicy,
+		health:    make(map[string]*moduleHealth),
+		skipped:   make(map[string]struct{}),
+		stateCond: sync.NewCond(&sync.Mutex{}),
+		schedule: func(delay time.Duration, fn func()) {
+			time.AfterFunc(delay, fn)
+		},
+	}
+}
+
+func (w *watchdog) getHealth(name string) *moduleHealth {
+	h, ok := w.health[name]
+	if !ok {
+		h = &moduleHealth{}
+		w.health[name] = h
+	}
+	return h
+}
+
+func (w *watchdog) crashCount(name string) uint32 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.getHealth(name).crashes
+}
+
+// isRestarting 返回模块是否正在等待自动重启
+func (w *watchdog) isRestarting(name string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.getHealth(name).restarting
+}
+
+func (w *watchdog) getPolicy() RestartPolicy {
+	w.initOnce.Do(w.loadPolicy)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.policy
+}
+
+func (w *watchdog) loadPolicy() {
+	dc, err := dconfig.NewDConfig(dsettingsAppID, dsettingsWatchdogResource, "")
+	if err != nil {
+		getLoader().log.Warning(err)
+		return
+	}
+
+	update := func() {
+		policy := defaultRestartPolicy
+		if v, err := dc.GetValueBool(dsettingsKeyAutoRestart); err == nil {
+			policy.AutoRestart = v
+		}
+		if v, err := dc.GetValueInt64(dsettingsKeyRestartMaxRetries); err == nil && v >= 0 {
+			policy.MaxRetries = int(v)
+		}
+		if v, err := dc.GetValueInt64(dsettingsKeyRestartInitialDelay); err == nil && v > 0 {
+			policy.InitialDelay = time.Duration(v) * time.Millisecond
+		}
+		if v, err := dc.GetValueInt64(dsettingsKeyRestartMaxDelay); err == nil && v > 0 {
+			policy.MaxDelay = time.Duration(v) * time.Millisecond
+		}
+		w.mu.Lock()
+		w.policy = policy
+		w.mu.Unlock()
+	}
+	update()
+
+	dc.ConnectValueChanged(func(key string) {
+		switch key {
+		case dsettingsKeyAutoRestart, dsettingsKeyRestartMaxRetries,
+			dsettingsKeyRestartInitialDelay, dsettingsKeyRestartMaxDelay:
+			update()
+		}
+	})
+}
+
+func (w *watchdog) broadcast() {
+	w.stateCond.L.Lock()
+	w.stateCond.Broadcast()
+	w.stateCond.L.Unlock()
+}
+
+// waitModule 阻塞到模块启动成功或者失败，启动成功时返回 true
+func (w *watchdog) waitModule(module Module) bool {
+	w.stateCond.L.Lock()
+	defer w.stateCond.L.Unlock()
+	for {
+		if module.IsEnable() {
+			return true
+		}
+		if module.State() == ModuleStateFailed {
+			return false
+		}
+		w.stateCond.Wait()
+	}
+}
+
+// enableModule 启动模块，并将 Start 中的 panic 转换为 ErrorModulePanic
+func (l *Loader) enableModule(module Module) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &EnableError{
+				ModuleName: module.Name(),
+				Code:       ErrorModulePanic,
+				detail:     fmt.Sprintf("%v\n%s", v, debug.Stack()),
+			}
+			module.markFailed(err)
+		}
+	}()
+	return module.Enable(true)
+}
+
+// disableModule 停止模块，并将 Stop 中的 panic 转换为 ErrorModulePanic，避免影响其他模块的停止
+func (l *Loader) disableModule(module Module) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &EnableError{
+				ModuleName: module.Name(),
+				Code:       ErrorModulePanic,
+				detail:     fmt.Sprintf("%v\n%s", v, debug.Stack()),
+			}
+			l.log.Errorf("stop module %s panic: %v", module.Name(), err)
+			module.markFailed(err)
+		}
+	}()
+	return module.Enable(false)
+}
+
+// waitDependencies 等待 module 的所有依赖启动，返回第一个启动失败的依赖
+func (l *Loader) waitDependencies(module Module) (failed string, ok bool) {
+	for _, dependencyName := range module.GetDependencies() {
+		dependency, exist := l.modules[dependencyName]
+		if !exist {
+			continue
+		}
+		if !l.watchdog.waitModule(dependency) {
+			return dependencyName, false
+		}
+	}
+	return "", true
+}
+
+// markSkipped 将因为依赖失败而无法启动的模块也标记为失败，以便依赖它的模块不再等待，
+// 在依赖重启成功后由 startSkippedModules 启动。
+func (l *Loader) markSkipped(module Module, dependency string) {
+	l.watchdog.mu.Lock()
+	l.watchdog.skipped[module.Name()] = struct{}{}
+	l.watchdog.mu.Unlock()
+	module.markFailed(&EnableError{ModuleName: module.Name(), Code: ErrorNoDependencies, detail: dependency})
+}
+
+// handleModuleFailure 记录模块失败，并根据重启策略安排一次延迟重启，已经安排了重启时不再重复安排
+func (l *Loader) handleModuleFailure(name string, err error) {
+	w := l.watchdog
+	policy := w.getPolicy()
+
+	w.mu.Lock()
+	h := w.getHealth(name)
+	h.crashes++
+	crashes := h.crashes
+	pending := h.restarting
+	if !pending {
+		h.retries++
+		h.restarting = policy.AutoRestart && h.retries <= policy.MaxRetries
+	}
+	retries := h.retries
+	restart := h.restarting
+	w.mu.Unlock()
+
+	l.log.Warningf("module %s failed %d times: %v", name, crashes, err)
+	if l.manager != nil {
+		l.manager.emitModuleCrashed(name, err, crashes)
+	}
+
+	if pending {
+		l.log.Infof("module %s is already waiting to be restarted", name)
+		return
+	}
+	if !restart {
+		l.log.Warningf("give up restarting module %s", name)
+		return
+	}
+	delay := policy.delay(retries)
+	l.log.Infof("restart module %s in %s, retry %d/%d", name, delay, retries, policy.MaxRetries)
+	w.schedule(delay, func() {
+		l.restartFailedModule(name)
+	})
+}
+
+func (l *Loader) restartFailedModule(name string) {
+	l.lock.Lock()
+	module, ok := l.modules[name]
+	// 等待期间模块可能已被移除或者通过其他途径启动
+	if !ok || module.State() != ModuleStateFailed {
+		l.watchdog.mu.Lock()
+		l.watchdog.getHealth(name).restarting = false
+		l.watchdog.mu.Unlock()
+		l.lock.Unlock()
+		return
+	}
+
+	order, err := l.sortedModules()
+	if err == nil {
+		err = l.startModule(order, name)
+	}
+
+	l.watchdog.mu.Lock()
+	h := l.watchdog.getHealth(name)
+	h.restarting = false
+	if err == nil {
+		h.retries = 0
+	}
+	l.watchdog.mu.Unlock()
+
+	if err == nil {
+		l.log.Infof("module %s restarted", name)
+		l.startSkippedModules(order)
+	}
+	l.lock.Unlock()
+
+	if err != nil {
+		l.handleModuleFailure(name, err)
+	}
+}
+
+// startSkippedModules 启动之前因为依赖失败而被跳过、现在依赖已满足的模块
+func (l *Loader) startSkippedModules(order []string) {
+	for _, name := range order {
+		l.watchdog.mu.Lock()
+		_, skipped := l.watchdog.skipped[name]
+		l.watchdog.mu.Unlock()
+		if !skipped {
+			continue
+		}
+
+		ready := true
+		for _, dependency := range l.modules[name].GetDependencies() {
+			if m, ok := l.modules[dependency]; ok && !m.IsEnable() {
+				ready = false
+				break
+			}
+		}
+		if !ready {
+			continue
+		}
+
+		l.watchdog.mu.Lock()
+		delete(l.watchdog.skipped, name)
+		l.watchdog.mu.Unlock()
+
+		err := l.startModule(order, name)
+		if err != nil {
+			l.handleModuleFailure(name, err)
+		}
+	}
+}
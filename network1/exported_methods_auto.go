@@ -20,6 +20,10 @@ func (v *Manager) GetExportedMethods() dbusutil.ExportedMethods {
 			InArgs:  []string{"uuid", "devPath"},
 			OutArgs: []string{"cpath"},
 		},
+		{
+			Name: "ClearIPConflictHistory",
+			Fn:   v.ClearIPConflictHistory,
+		},
 		{
 			Name:   "DeactivateConnection",
 			Fn:     v.DeactivateConnection,
@@ -35,6 +39,11 @@ func (v *Manager) GetExportedMethods() dbusutil.ExportedMethods {
 			Fn:     v.DeleteConnection,
 			InArgs: []string{"uuid"},
 		},
+		{
+			Name:   "DeleteProxyProfile",
+			Fn:     v.DeleteProxyProfile,
+			InArgs: []string{"uuid"},
+		},
 		{
 			Name:   "DisableWirelessHotspotMode",
 			Fn:     v.DisableWirelessHotspotMode,
@@ -55,6 +64,17 @@ func (v *Manager) GetExportedMethods() dbusutil.ExportedMethods {
 			Fn:     v.EnableWirelessHotspotMode,
 			InArgs: []string{"devPath"},
 		},
+		{
+			Name:    "EvaluateProxy",
+			Fn:      v.EvaluateProxy,
+			InArgs:  []string{"url"},
+			OutArgs: []string{"proxy"},
+		},
+		{
+			Name:    "GenerateWireGuardKeyPair",
+			Fn:      v.GenerateWireGuardKeyPair,
+			OutArgs: []string{"privateKey", "publicKey"},
+		},
 		{
 			Name:    "GetAccessPoints",
 			Fn:      v.GetAccessPoints,
@@ -71,6 +91,11 @@ func (v *Manager) GetExportedMethods() dbusutil.ExportedMethods {
 			Fn:      v.GetAutoProxy,
 			OutArgs: []string{"proxyAuto"},
 		},
+		{
+			Name:    "GetIPConflictHistory",
+			Fn:      v.GetIPConflictHistory,
+			OutArgs: []string{"history"},
+		},
 		{
 			Name:    "GetProxy",
 			Fn:      v.GetProxy,
@@ -98,6 +123,18 @@ func (v *Manager) GetExportedMethods() dbusutil.ExportedMethods {
 			Fn:      v.GetSupportedConnectionTypes,
 			OutArgs: []string{"types"},
 		},
+		{
+			Name:    "GetWireGuardPeers",
+			Fn:      v.GetWireGuardPeers,
+			InArgs:  []string{"uuid"},
+			OutArgs: []string{"peers"},
+		},
+		{
+			Name:    "ImportWireGuardConfig",
+			Fn:      v.ImportWireGuardConfig,
+			InArgs:  []string{"file"},
+			OutArgs: []string{"cpath"},
+		},
 		{
 			Name:    "IsDeviceEnabled",
 			Fn:      v.IsDeviceEnabled,
@@ -116,6 +153,21 @@ func (v *Manager) GetExportedMethods() dbusutil.ExportedMethods {
 			InArgs:  []string{"devPath"},
 			OutArgs: []string{"connections"},
 		},
+		{
+			Name:    "ListProxyProfiles",
+			Fn:      v.ListProxyProfiles,
+			OutArgs: []string{"profiles"},
+		},
+		{
+			Name:    "ListWirelessJoinOrder",
+			Fn:      v.ListWirelessJoinOrder,
+			OutArgs: []string{"connections"},
+		},
+		{
+			Name:   "RenewIPConflictLease",
+			Fn:     v.RenewIPConflictLease,
+			InArgs: []string{"ip"},
+		},
 		{
 			Name:   "RequestIPConflictCheck",
 			Fn:     v.RequestIPConflictCheck,
@@ -155,6 +207,53 @@ func (v *Manager) GetExportedMethods() dbusutil.ExportedMethods {
 			Fn:     v.SetProxyMethod,
 			InArgs: []string{"proxyMode"},
 		},
+		{
+			Name:   "SetProxyProfile",
+			Fn:     v.SetProxyProfile,
+			InArgs: []string{"uuid", "profile"},
+		},
+		{
+			Name:   "SetWireGuardPeers",
+			Fn:     v.SetWireGuardPeers,
+			InArgs: []string{"uuid", "peers"},
+		},
+		{
+			Name:    "SetWireGuardPrivateKey",
+			Fn:      v.SetWireGuardPrivateKey,
+			InArgs:  []string{"uuid", "privateKey"},
+			OutArgs: []string{"publicKey"},
+		},
+		{
+			Name:   "SetWirelessAutoJoin",
+			Fn:     v.SetWirelessAutoJoin,
+			InArgs: []string{"uuid", "autoJoin"},
+		},
+		{
+			Name:   "SetWirelessBand",
+			Fn:     v.SetWirelessBand,
+			InArgs: []string{"uuid", "band"},
+		},
+		{
+			Name:   "SetWirelessBssid",
+			Fn:     v.SetWirelessBssid,
+			InArgs: []string{"uuid", "bssid"},
+		},
+		{
+			Name:   "SetWirelessJoinOrder",
+			Fn:     v.SetWirelessJoinOrder,
+			InArgs: []string{"uuids"},
+		},
+		{
+			Name:   "SetWirelessMetered",
+			Fn:     v.SetWirelessMetered,
+			InArgs: []string{"uuid", "metered"},
+		},
+		{
+			Name:    "UseFallbackAddress",
+			Fn:      v.UseFallbackAddress,
+			InArgs:  []string{"ip", "address"},
+			OutArgs: []string{"fallback"},
+		},
 	}
 }
 func (v *SecretAgent) GetExportedMethods() dbusutil.ExportedMethods {
@@ -127,6 +127,16 @@ type Manager struct {
 
 	connectionSettingsLock sync.Mutex
 
+	// update by manager_proxy_profile.go
+	proxyProfilesLock sync.Mutex
+	proxyProfiles     *proxyProfiles
+
+	// update by manager_ip_conflict.go
+	ipConflictsLock   sync.Mutex
+	ipConflicts       *ipConflicts
+	ipConflictCheckCh chan struct{}
+	ipConflictQuit    chan struct{}
+
 	// dsg config : org.deepin.dde.daemon.network
 	protalAuthEnable          bool
 	wifiOSDEnable             bool
@@ -154,6 +164,9 @@ type Manager struct {
 			ip  string
 			mac string
 		}
+		IPConflictChanged struct {
+			info string
+		}
 		ProxyMethodChanged struct {
 			method string
 		}
@@ -329,6 +342,7 @@ func (m *Manager) init() {
 	m.initConnectionManage()
 	m.initDeviceManage()
 	m.initActiveConnectionManage()
+	m.initProxyProfiles()
 	m.initNMObjManager(systemBus)
 	m.stateHandler = newStateHandler(m.sysSigLoop, m)
 	m.initSysNetwork(systemBus)
@@ -562,6 +576,7 @@ func (m *Manager) destroy() {
 	m.clearAccessPoints()
 	m.clearConnections()
 	m.clearActiveConnections()
+	m.stopIPConflictMonitor()
 
 	// reset dbus properties
 	m.setPropNetworkingEnabled(false)
@@ -208,6 +208,7 @@ func (m *Manager) initActiveConnectionManage() {
 
 			if stateChanged && state == nm.NM_ACTIVE_CONNECTION_STATE_ACTIVATED {
 				go m.checkConnectivity()
+				m.triggerIPConflictCheck()
 			}
 		}
 		if strings.HasPrefix(string(sig.Path),
@@ -580,6 +580,34 @@ func (m *Manager) activateConnection(uuid string, devPath dbus.ObjectPath) (cpat
 	return
 }
 
+// getConnectionSettingsForUpdate 返回连接 uuid 的设置，修改后可以用 updateConnectionSettings 保存
+func getConnectionSettingsForUpdate(uuid string) (dbus.ObjectPath, connectionData, error) {
+	cpath, err := nmGetConnectionByUuid(uuid)
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := nmGetConnectionData(cpath)
+	if err != nil {
+		return "", nil, err
+	}
+	// fix ipv6 addresses and routes data structure, interface{}
+	if isSettingIP6ConfigAddressesExists(data) {
+		setSettingIP6ConfigAddresses(data, getSettingIP6ConfigAddresses(data))
+	}
+	if isSettingIP6ConfigRoutesExists(data) {
+		setSettingIP6ConfigRoutes(data, getSettingIP6ConfigRoutes(data))
+	}
+	return cpath, data, nil
+}
+
+func updateConnectionSettings(cpath dbus.ObjectPath, data connectionData) error {
+	conn, err := nmNewSettingsConnection(cpath)
+	if err != nil {
+		return err
+	}
+	return conn.Update(0, data)
+}
+
 // DeactivateConnection deactivate a target connection.
 func (m *Manager) DeactivateConnection(uuid string) *dbus.Error {
 	err := m.deactivateConnection(uuid)
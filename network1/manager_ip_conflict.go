@@ -5,15 +5,242 @@
 package network
 
 import (
+	"bufio"
 	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	dbus "github.com/godbus/dbus/v5"
+	"github.com/linuxdeepin/dde-daemon/network1/nm"
 	ipwatchd "github.com/linuxdeepin/go-dbus-factory/system/org.deepin.dde.ipwatchd1"
 	ofdbus "github.com/linuxdeepin/go-dbus-factory/system/org.freedesktop.dbus"
 	"github.com/linuxdeepin/go-lib/dbusutil"
+	"github.com/linuxdeepin/go-lib/xdg/basedir"
 )
 
+const (
+	ipConflictCheckInterval = 30 * time.Second
+	// 一轮检测没有发现变化时使用更长的间隔，ipwatchd 会主动上报新的冲突
+	ipConflictIdleCheckInterval = 5 * time.Minute
+	// 单个地址和一轮检测中所有地址的探测时限
+	ipConflictCheckTimeout = 3 * time.Second
+	ipConflictHistoryMax   = 100
+	// 续租时开启 NetworkManager 的地址冲突检测，DHCP 分配的地址冲突时会拒绝该地址
+	ipConflictDadTimeout = 3000 // ms
+	ipConflictMaxProbes  = 8
+
+	ipFamilyV4 = "ipv4"
+	ipFamilyV6 = "ipv6"
+
+	ifaFlagDadFailed = 0x08 // IFA_F_DADFAILED
+)
+
+var (
+	ipConflictHistoryFile = filepath.Join(basedir.GetUserConfigDir(), "deepin/dde-daemon/network-ip-conflicts.json")
+	procIfInet6File       = "/proc/net/if_inet6"
+)
+
+// IPConflictRecord IP 冲突记录
+type IPConflictRecord struct {
+	IP             string
+	Family         string
+	Interface      string
+	ConnectionUuid string
+	ConnectionId   string
+	Mac            string // 冲突设备的 MAC 地址，IPv6 重复地址检测失败时为空
+	Active         bool   // 冲突是否仍然存在
+	DetectedAt     int64
+	ResolvedAt     int64 `json:",omitempty"`
+}
+
+type ipConflicts struct {
+	active  map[string]*IPConflictRecord // IP => 未解决的冲突
+	History []*IPConflictRecord
+}
+
+// ipConflictTarget 需要检测冲突的地址
+type ipConflictTarget struct {
+	IPConflictRecord
+	prefix  uint32
+	devPath dbus.ObjectPath
+	apath   dbus.ObjectPath
+}
+
+type inet6Address struct {
+	ip        string
+	ifc       string
+	prefix    uint32
+	dadFailed bool
+}
+
+func newIPConflicts() *ipConflicts {
+	return &ipConflicts{
+		active: make(map[string]*IPConflictRecord),
+	}
+}
+
+func loadIPConflicts(file string) *ipConflicts {
+	c := newIPConflicts()
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warning(err)
+		}
+		return c
+	}
+	err = json.Unmarshal(data, c)
+	if err != nil {
+		logger.Warning(err)
+	}
+	// 上次退出时未解决的冲突，下次检测时地址不存在会被标记为已解决
+	for _, rec := range c.History {
+		if rec.Active {
+			c.active[rec.IP] = rec
+		}
+	}
+	return c
+}
+
+func (c *ipConflicts) save(file string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(file), 0755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0600)
+}
+
+func (c *ipConflicts) resolve(rec *IPConflictRecord, now time.Time) {
+	rec.Active = false
+	rec.ResolvedAt = now.Unix()
+	delete(c.active, rec.IP)
+}
+
+// update 更新地址的冲突状态，状态变化时返回记录的副本，否则返回 nil
+func (c *ipConflicts) update(target *IPConflictRecord, conflict bool, now time.Time) *IPConflictRecord {
+	rec, ok := c.active[target.IP]
+	if !conflict {
+		if !ok {
+			return nil
+		}
+		c.resolve(rec, now)
+		r := *rec
+		return &r
+	}
+	if ok {
+		if rec.Mac == target.Mac {
+			return nil
+		}
+		// 和另一台设备冲突
+		c.resolve(rec, now)
+	}
+
+	rec = &IPConflictRecord{
+		IP:             target.IP,
+		Family:         target.Family,
+		Interface:      target.Interface,
+		ConnectionUuid: target.ConnectionUuid,
+		ConnectionId:   target.ConnectionId,
+		Mac:            target.Mac,
+		Active:         true,
+		DetectedAt:     now.Unix(),
+	}
+	c.active[rec.IP] = rec
+	c.History = append(c.History, rec)
+	if len(c.History) > ipConflictHistoryMax {
+		c.History = c.History[len(c.History)-ipConflictHistoryMax:]
+	}
+	r := *rec
+	return &r
+}
+
+// resolveMissing 地址已不存在的冲突标记为已解决，返回这些记录的副本
+func (c *ipConflicts) resolveMissing(present map[string]bool, now time.Time) (records []*IPConflictRecord) {
+	for ip, rec := range c.active {
+		if present[ip] {
+			continue
+		}
+		c.resolve(rec, now)
+		r := *rec
+		records = append(records, &r)
+	}
+	return
+}
+
+// readInet6Addresses 读取内核中网卡的 IPv6 地址及重复地址检测结果
+func readInet6Addresses(file string) (addrs []inet6Address, err error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// 地址 网卡序号 前缀长度 范围 标志 网卡名
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 {
+			continue
+		}
+		ip, err := hex.DecodeString(fields[0])
+		if err != nil || len(ip) != net.IPv6len {
+			continue
+		}
+		prefix, err := strconv.ParseUint(fields[2], 16, 8)
+		if err != nil {
+			continue
+		}
+		flags, err := strconv.ParseUint(fields[4], 16, 32)
+		if err != nil {
+			continue
+		}
+		addrs = append(addrs, inet6Address{
+			ip:        net.IP(ip).String(),
+			ifc:       fields[5],
+			prefix:    uint32(prefix),
+			dadFailed: flags&ifaFlagDadFailed != 0,
+		})
+	}
+	return addrs, scanner.Err()
+}
+
+// getFallbackAddressCandidates 从网段的末尾开始返回最多 max 个可用作备用静态地址的候选地址
+func getFallbackAddressCandidates(ip string, prefix uint32, exclude []string, max int) []string {
+	ip4 := net.ParseIP(ip).To4()
+	if ip4 == nil || prefix == 0 || prefix >= 31 {
+		return nil
+	}
+	mask := ^uint32(0) << (32 - prefix)
+	network := ipToUint32(ip4.String()) & mask
+	broadcast := network | ^mask
+
+	excluded := make(map[string]bool, len(exclude)+1)
+	excluded[ip] = true
+	for _, e := range exclude {
+		excluded[e] = true
+	}
+	var candidates []string
+	for addr := broadcast - 1; addr > network && len(candidates) < max; addr-- {
+		s := uint32ToIP(addr)
+		if !excluded[s] {
+			candidates = append(candidates, s)
+		}
+	}
+	return candidates
+}
+
 func activateSystemService(sysBus *dbus.Conn, serviceName string) error {
 	sysBusObj := ofdbus.NewDBus(sysBus)
 
@@ -42,35 +269,421 @@ func (m *Manager) initIPConflictManager(sysBus *dbus.Conn) {
 		logger.Warning(err)
 	}
 
+	m.ipConflictsLock.Lock()
+	m.ipConflicts = loadIPConflicts(ipConflictHistoryFile)
+	m.ipConflictsLock.Unlock()
+
 	_, err = m.sysIPWatchD.ConnectIPConflict(func(ip, smac, dmac string) {
 		err := m.service.Emit(manager, "IPConflict", ip, dmac)
 		if err != nil {
 			logger.Warning(err)
 		}
+		go m.handleIPConflictReport(ip, dmac)
 	})
 	if err != nil {
 		logger.Warning(err)
 	}
+
+	m.ipConflictCheckCh = make(chan struct{}, 1)
+	m.ipConflictQuit = make(chan struct{})
+	go m.monitorIPConflicts(m.ipConflictCheckCh, m.ipConflictQuit)
 }
 
-func (m *Manager) RequestIPConflictCheck(ip, ifc string) *dbus.Error {
-	conn, err := dbus.SystemBus()
-	if err != nil {
-		return dbusutil.ToError(err)
+func (m *Manager) stopIPConflictMonitor() {
+	if m.ipConflictQuit != nil {
+		close(m.ipConflictQuit)
+		m.ipConflictQuit = nil
 	}
+}
 
-	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-		defer cancel()
+// triggerIPConflictCheck 连接激活后立即检测一次
+func (m *Manager) triggerIPConflictCheck() {
+	select {
+	case m.ipConflictCheckCh <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Manager) monitorIPConflicts(checkCh, quit chan struct{}) {
+	timer := time.NewTimer(ipConflictCheckInterval)
+	defer timer.Stop()
+	for {
+		interval := ipConflictIdleCheckInterval
+		if m.checkIPConflicts() {
+			interval = ipConflictCheckInterval
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(interval)
+		select {
+		case <-timer.C:
+		case <-checkCh:
+		case <-quit:
+			return
+		}
+	}
+}
+
+// requestIPConflictCheck 通过 ipwatchd 发送 ARP 探测，返回冲突设备的 MAC 地址，没有冲突时为空
+func (m *Manager) requestIPConflictCheck(ip, ifc string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ipConflictCheckTimeout)
+	defer cancel()
+	return m.requestIPConflictCheckContext(ctx, ip, ifc)
+}
 
+func (m *Manager) requestIPConflictCheckContext(ctx context.Context, ip, ifc string) (string, error) {
+	call := m.sysIPWatchD.GoRequestIPConflictCheck(0, make(chan *dbus.Call, 1), ip, ifc)
+	select {
+	case call = <-call.Done:
 		var mac string
-		err = conn.Object("com.deepin.dde.IPWatchD1", "org/deepin/dde/IPWatchD1").CallWithContext(ctx, "org.deepin.dde.IPWatchD1.RequestIPConflictCheck", 0, ip, ifc).Store(&mac)
+		err := call.Store(&mac)
+		return mac, err
+	case <-ctx.Done():
+		return "", fmt.Errorf("check ip conflict of %s timeout", ip)
+	}
+}
+
+type ipConflictProbeResult struct {
+	mac string
+	err error
+}
+
+// probeIPConflicts 并发探测 targets 中的地址，所有探测共用 timeout 时限，返回的结果和 targets 一一对应
+func probeIPConflicts(targets []*ipConflictTarget, timeout time.Duration,
+	probe func(ctx context.Context, ip, ifc string) (string, error)) []ipConflictProbeResult {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	results := make([]ipConflictProbeResult, len(targets))
+	// 限制同时进行的探测数量
+	sem := make(chan struct{}, ipConflictMaxProbes)
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func(i int, t *ipConflictTarget) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				results[i].err = fmt.Errorf("check ip conflict of %s timeout", t.IP)
+				return
+			}
+			defer func() { <-sem }()
+			results[i].mac, results[i].err = probe(ctx, t.IP, t.Interface)
+		}(i, t)
+	}
+	wg.Wait()
+	return results
+}
+
+// getIPConflictTargets 返回已激活连接的 IPv4 地址
+func (m *Manager) getIPConflictTargets() (targets []*ipConflictTarget) {
+	for _, devPath := range nmGetDevices() {
+		if !isDeviceStateActivated(nmGetDeviceState(devPath)) {
+			continue
+		}
+		apath := nmGetDeviceActiveConnection(devPath)
+		nmAConn, err := nmNewActiveConnection(apath)
+		if err != nil {
+			continue
+		}
+		uuid, _ := nmAConn.Uuid().Get(0)
+		id, _ := nmAConn.Id().Get(0)
+		ifc := nmGetDeviceInterface(devPath)
+
+		base := ipConflictTarget{
+			IPConflictRecord: IPConflictRecord{
+				Family:         ipFamilyV4,
+				Interface:      ifc,
+				ConnectionUuid: uuid,
+				ConnectionId:   id,
+			},
+			devPath: devPath,
+			apath:   apath,
+		}
+		ip4Path, _ := nmAConn.Ip4Config().Get(0)
+		if !isNmObjectPathValid(ip4Path) {
+			// 没有 IPv4 地址时也记录网卡，用于关联 IPv6 地址
+			targets = append(targets, &base)
+			continue
+		}
+		for _, addr := range nmGetIp4ConfigInfo(ip4Path).Addresses {
+			t := base
+			t.IP = addr.Address
+			t.prefix = addr.Prefix
+			targets = append(targets, &t)
+		}
+	}
+	return
+}
+
+// checkIPConflicts 检测所有地址的冲突状态，有变化时返回 true
+func (m *Manager) checkIPConflicts() bool {
+	targets := m.getIPConflictTargets()
+	present := make(map[string]bool)
+	devTargets := make(map[string]*ipConflictTarget)
+	var changed []*IPConflictRecord
+
+	var ip4Targets []*ipConflictTarget
+	for _, t := range targets {
+		devTargets[t.Interface] = t
+		if t.IP == "" {
+			continue
+		}
+		present[t.IP] = true
+		ip4Targets = append(ip4Targets, t)
+	}
+	results := probeIPConflicts(ip4Targets, ipConflictCheckTimeout, m.requestIPConflictCheckContext)
+	for i, t := range ip4Targets {
+		mac, err := results[i].mac, results[i].err
+		if err != nil {
+			// 检测失败时保持原来的状态
+			logger.Debug(err)
+			continue
+		}
+		t.Mac = mac
+		m.ipConflictsLock.Lock()
+		rec := m.ipConflicts.update(&t.IPConflictRecord, mac != "", time.Now())
+		m.ipConflictsLock.Unlock()
+		if rec != nil {
+			changed = append(changed, rec)
+		}
+	}
+
+	addrs, err := readInet6Addresses(procIfInet6File)
+	if err != nil {
+		logger.Warning(err)
+	}
+	for _, addr := range addrs {
+		dt, ok := devTargets[addr.ifc]
+		if !ok {
+			continue
+		}
+		present[addr.ip] = true
+		t := dt.IPConflictRecord
+		t.IP = addr.ip
+		t.Family = ipFamilyV6
+		t.Mac = ""
+		m.ipConflictsLock.Lock()
+		rec := m.ipConflicts.update(&t, addr.dadFailed, time.Now())
+		m.ipConflictsLock.Unlock()
+		if rec != nil {
+			changed = append(changed, rec)
+		}
+	}
+
+	m.ipConflictsLock.Lock()
+	changed = append(changed, m.ipConflicts.resolveMissing(present, time.Now())...)
+	m.ipConflictsLock.Unlock()
+	m.emitIPConflictChanged(changed, true)
+	return len(changed) > 0
+}
+
+// handleIPConflictReport 处理 ipwatchd 主动上报和按需检测的结果，调用者已发送 IPConflict 信号
+func (m *Manager) handleIPConflictReport(ip, mac string) {
+	for _, t := range m.getIPConflictTargets() {
+		if t.IP != ip {
+			continue
+		}
+		t.Mac = mac
+		m.ipConflictsLock.Lock()
+		rec := m.ipConflicts.update(&t.IPConflictRecord, mac != "", time.Now())
+		m.ipConflictsLock.Unlock()
+		if rec != nil {
+			m.emitIPConflictChanged([]*IPConflictRecord{rec}, false)
+		}
+		return
+	}
+}
+
+// emitIPConflictChanged 保存冲突记录并发送信号，legacy 为 true 时同时发送 IPConflict 信号
+func (m *Manager) emitIPConflictChanged(records []*IPConflictRecord, legacy bool) {
+	if len(records) == 0 {
+		return
+	}
+	m.ipConflictsLock.Lock()
+	err := m.ipConflicts.save(ipConflictHistoryFile)
+	m.ipConflictsLock.Unlock()
+	if err != nil {
+		logger.Warning(err)
+	}
+
+	for _, rec := range records {
+		logger.Infof("ip conflict of %s changed, mac: %q, active: %v", rec.IP, rec.Mac, rec.Active)
+		info, err := marshalJSON(rec)
+		if err != nil {
+			logger.Warning(err)
+			continue
+		}
+		err = m.service.Emit(manager, "IPConflictChanged", info)
+		if err != nil {
+			logger.Warning(err)
+		}
+		// 兼容只监听 IPConflict 信号的程序，MAC 为空表示冲突已解决
+		if legacy && rec.Family == ipFamilyV4 {
+			mac := rec.Mac
+			if !rec.Active {
+				mac = ""
+			}
+			err = m.service.Emit(manager, "IPConflict", rec.IP, mac)
+			if err != nil {
+				logger.Warning(err)
+			}
+		}
+	}
+}
+
+func (m *Manager) RequestIPConflictCheck(ip, ifc string) *dbus.Error {
+	go func() {
+		mac, err := m.requestIPConflictCheck(ip, ifc)
 		if err != nil {
 			logger.Warning(err)
 		}
 		logger.Debug("send ip conflict check result: ", ip, mac)
 		m.service.Emit(manager, "IPConflict", ip, mac)
+		if err == nil {
+			m.handleIPConflictReport(ip, mac)
+		}
 	}()
 
 	return nil
 }
+
+// GetIPConflictHistory 返回 JSON 格式的 IP 冲突记录，按检测到的时间排序
+func (m *Manager) GetIPConflictHistory() (history string, busErr *dbus.Error) {
+	m.ipConflictsLock.Lock()
+	defer m.ipConflictsLock.Unlock()
+	history, err := marshalJSON(m.ipConflicts.History)
+	return history, dbusutil.ToError(err)
+}
+
+// ClearIPConflictHistory 清除已解决的 IP 冲突记录
+func (m *Manager) ClearIPConflictHistory() *dbus.Error {
+	m.ipConflictsLock.Lock()
+	defer m.ipConflictsLock.Unlock()
+	var history []*IPConflictRecord
+	for _, rec := range m.ipConflicts.History {
+		if rec.Active {
+			history = append(history, rec)
+		}
+	}
+	m.ipConflicts.History = history
+	return dbusutil.ToError(m.ipConflicts.save(ipConflictHistoryFile))
+}
+
+func (m *Manager) getIPConflictTarget(ip string) (*ipConflictTarget, error) {
+	for _, t := range m.getIPConflictTargets() {
+		if t.IP == ip {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("%s is not an address of active connections", ip)
+}
+
+// RenewIPConflictLease 重新激活冲突地址所在的连接以重新获取 DHCP 地址，
+// 同时开启地址冲突检测，避免再次分配到冲突的地址
+func (m *Manager) RenewIPConflictLease(ip string) *dbus.Error {
+	logger.Info("RenewIPConflictLease", ip)
+	t, err := m.getIPConflictTarget(ip)
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+	cpath, data, err := getConnectionSettingsForUpdate(t.ConnectionUuid)
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+	if getSettingIP4ConfigMethod(data) != nm.NM_SETTING_IP4_CONFIG_METHOD_AUTO {
+		return dbusutil.ToError(fmt.Errorf("connection %s does not use DHCP", t.ConnectionId))
+	}
+	if getSettingIP4ConfigDadTimeout(data) <= 0 {
+		setSettingIP4ConfigDadTimeout(data, ipConflictDadTimeout)
+		err = updateConnectionSettings(cpath, data)
+		if err != nil {
+			return dbusutil.ToError(err)
+		}
+	}
+	_, err = nmActivateConnection(cpath, t.devPath)
+	return dbusutil.ToError(err)
+}
+
+// UseFallbackAddress 把冲突地址所在的连接切换为静态地址 address，网关和 DNS 沿用当前配置。
+// address 为空时在同一网段中探测一个没有被使用的地址，返回使用的地址
+func (m *Manager) UseFallbackAddress(ip string, address string) (fallback string, busErr *dbus.Error) {
+	logger.Info("UseFallbackAddress", ip, address)
+	t, err := m.getIPConflictTarget(ip)
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	if t.Family != ipFamilyV4 {
+		return "", dbusutil.ToError(errors.New("only ipv4 addresses are supported"))
+	}
+	nmAConn, err := nmNewActiveConnection(t.apath)
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	ip4Path, _ := nmAConn.Ip4Config().Get(0)
+	ip4Config, err := nmNewIP4Config(ip4Path)
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	gateway, _ := ip4Config.Gateway().Get(0)
+	nameservers, _ := ip4Config.Nameservers().Get(0)
+
+	if address == "" {
+		address, err = m.probeFallbackAddress(t, gateway)
+		if err != nil {
+			return "", dbusutil.ToError(err)
+		}
+	} else if net.ParseIP(address).To4() == nil {
+		return "", dbusutil.ToError(fmt.Errorf("invalid ipv4 address %q", address))
+	}
+
+	cpath, data, err := getConnectionSettingsForUpdate(t.ConnectionUuid)
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	setSettingIP4ConfigMethod(data, nm.NM_SETTING_IP4_CONFIG_METHOD_MANUAL)
+	// address-data 优先于 addresses，需要删除
+	delete(data[nm.NM_SETTING_IP4_CONFIG_SETTING_NAME], "address-data")
+	var gw uint32
+	if gateway != "" {
+		gw = htonl(ipToUint32(gateway))
+		setSettingIP4ConfigGateway(data, gateway)
+	}
+	setSettingIP4ConfigAddresses(data, [][]uint32{{htonl(ipToUint32(address)), t.prefix, gw}})
+	if len(getSettingIP4ConfigDns(data)) == 0 && len(nameservers) > 0 {
+		setSettingIP4ConfigDns(data, nameservers)
+	}
+	err = updateConnectionSettings(cpath, data)
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	_, err = nmActivateConnection(cpath, t.devPath)
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	return address, nil
+}
+
+// probeFallbackAddress 在冲突地址所在网段中找一个没有设备应答 ARP 的地址
+func (m *Manager) probeFallbackAddress(t *ipConflictTarget, gateway string) (string, error) {
+	exclude := []string{gateway}
+	for _, other := range m.getIPConflictTargets() {
+		exclude = append(exclude, other.IP)
+	}
+	for _, candidate := range getFallbackAddressCandidates(t.IP, t.prefix, exclude, ipConflictMaxProbes) {
+		mac, err := m.requestIPConflictCheck(candidate, t.Interface)
+		if err != nil {
+			return "", err
+		}
+		if mac == "" {
+			return candidate, nil
+		}
+		logger.Debugf("fallback address %s is used by %s", candidate, mac)
+	}
+	return "", fmt.Errorf("no free address found in the network of %s", t.IP)
+}
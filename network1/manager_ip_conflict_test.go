@@ -0,0 +1,149 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package network
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIPConflictsUpdate(t *testing.T) {
+	c := newIPConflicts()
+	now := time.Unix(1000, 0)
+	target := &IPConflictRecord{IP: "192.168.1.100", Family: ipFamilyV4, Interface: "eth0", Mac: "00:11:22:33:44:55"}
+
+	assert.Nil(t, c.update(target, false, now))
+	rec := c.update(target, true, now)
+	require.NotNil(t, rec)
+	assert.True(t, rec.Active)
+	assert.Equal(t, int64(1000), rec.DetectedAt)
+	// 状态没有变化
+	assert.Nil(t, c.update(target, true, now.Add(time.Second)))
+
+	// 和另一台设备冲突
+	other := *target
+	other.Mac = "66:77:88:99:aa:bb"
+	rec = c.update(&other, true, now.Add(2*time.Second))
+	require.NotNil(t, rec)
+	assert.Equal(t, other.Mac, rec.Mac)
+	require.Len(t, c.History, 2)
+	assert.False(t, c.History[0].Active)
+	assert.Equal(t, int64(1002), c.History[0].ResolvedAt)
+
+	rec = c.update(&other, false, now.Add(3*time.Second))
+	require.NotNil(t, rec)
+	assert.False(t, rec.Active)
+	assert.Equal(t, int64(1003), rec.ResolvedAt)
+	assert.Empty(t, c.active)
+}
+
+func TestIPConflictsResolveMissing(t *testing.T) {
+	c := newIPConflicts()
+	now := time.Now()
+	c.update(&IPConflictRecord{IP: "192.168.1.100", Mac: "00:11:22:33:44:55"}, true, now)
+	c.update(&IPConflictRecord{IP: "fe80::1", Family: ipFamilyV6}, true, now)
+
+	records := c.resolveMissing(map[string]bool{"fe80::1": true}, now)
+	require.Len(t, records, 1)
+	assert.Equal(t, "192.168.1.100", records[0].IP)
+	assert.False(t, records[0].Active)
+	assert.Len(t, c.active, 1)
+}
+
+func TestIPConflictsHistoryMax(t *testing.T) {
+	c := newIPConflicts()
+	now := time.Now()
+	for i := 0; i < ipConflictHistoryMax+10; i++ {
+		target := &IPConflictRecord{IP: uint32ToIP(uint32(0x0a000000 + i)), Mac: "00:11:22:33:44:55"}
+		c.update(target, true, now)
+		c.update(target, false, now)
+	}
+	require.Len(t, c.History, ipConflictHistoryMax)
+	assert.Equal(t, "10.0.0.10", c.History[0].IP)
+}
+
+func TestIPConflictsSaveLoad(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "deepin", "network-ip-conflicts.json")
+	c := loadIPConflicts(file)
+	now := time.Unix(1000, 0)
+	c.update(&IPConflictRecord{IP: "192.168.1.100", Mac: "00:11:22:33:44:55"}, true, now)
+	c.update(&IPConflictRecord{IP: "192.168.1.101", Mac: "00:11:22:33:44:66"}, true, now)
+	c.update(&IPConflictRecord{IP: "192.168.1.101"}, false, now)
+	require.NoError(t, c.save(file))
+
+	c2 := loadIPConflicts(file)
+	assert.Equal(t, c.History, c2.History)
+	require.Len(t, c2.active, 1)
+	assert.Contains(t, c2.active, "192.168.1.100")
+}
+
+func TestReadInet6Addresses(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "if_inet6")
+	content := `fe800000000000000211 02 40 20 80     eth0
+fe80000000000000021122fffe334455 02 40 20 80     eth0
+20010db8000000000000000000000001 02 40 00 88     eth0
+00000000000000000000000000000001 01 80 10 80       lo
+`
+	require.NoError(t, os.WriteFile(file, []byte(content), 0644))
+
+	addrs, err := readInet6Addresses(file)
+	require.NoError(t, err)
+	assert.Equal(t, []inet6Address{
+		{ip: "fe80::211:22ff:fe33:4455", ifc: "eth0", prefix: 64},
+		{ip: "2001:db8::1", ifc: "eth0", prefix: 64, dadFailed: true},
+		{ip: "::1", ifc: "lo", prefix: 128},
+	}, addrs)
+
+	_, err = readInet6Addresses(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
+
+func TestGetFallbackAddressCandidates(t *testing.T) {
+	candidates := getFallbackAddressCandidates("192.168.1.100", 24, []string{"192.168.1.253", ""}, 3)
+	assert.Equal(t, []string{"192.168.1.254", "192.168.1.252", "192.168.1.251"}, candidates)
+
+	candidates = getFallbackAddressCandidates("10.0.0.1", 30, []string{"10.0.0.2"}, 8)
+	assert.Empty(t, candidates)
+
+	assert.Nil(t, getFallbackAddressCandidates("10.0.0.1", 31, nil, 8))
+	assert.Nil(t, getFallbackAddressCandidates("fe80::1", 64, nil, 8))
+}
+
+func TestProbeIPConflicts(t *testing.T) {
+	var targets []*ipConflictTarget
+	for _, ip := range []string{"192.168.1.2", "192.168.1.3", "192.168.1.4"} {
+		target := &ipConflictTarget{}
+		target.IP = ip
+		target.Interface = "eth0"
+		targets = append(targets, target)
+	}
+
+	start := time.Now()
+	results := probeIPConflicts(targets, 200*time.Millisecond, func(ctx context.Context, ip, ifc string) (string, error) {
+		switch ip {
+		case "192.168.1.2":
+			return "00:11:22:33:44:55", nil
+		case "192.168.1.3":
+			<-ctx.Done()
+			return "", errors.New("timeout")
+		}
+		// 探测并发进行，不会被上面阻塞的探测拖慢
+		return "", nil
+	})
+	assert.Less(t, time.Since(start), time.Second)
+	require.Len(t, results, 3)
+	assert.Equal(t, "00:11:22:33:44:55", results[0].mac)
+	assert.NoError(t, results[0].err)
+	assert.Error(t, results[1].err)
+	assert.Equal(t, "", results[2].mac)
+	assert.NoError(t, results[2].err)
+}
@@ -0,0 +1,433 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package network
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/linuxdeepin/go-lib/dbusutil"
+	"github.com/linuxdeepin/go-lib/xdg/basedir"
+)
+
+const (
+	// pacparser 提供的命令行工具，用于执行 PAC 文件
+	pacTesterCmd      = "pactester"
+	pacFetchTimeout   = 10 * time.Second
+	pacMaxFileSize    = 1 << 20
+	proxyResultDirect = "DIRECT"
+)
+
+var errPacUnavailable = errors.New("PAC evaluation unavailable: " + pacTesterCmd + " from pacparser is not installed")
+
+var proxyProfilesFile = filepath.Join(basedir.GetUserConfigDir(), "deepin/dde-daemon/network-proxy-profiles.json")
+
+type ProxyServer struct {
+	Host string
+	Port int32
+}
+
+// ProxyProfile 代理配置，和 gsettings 中的全局代理配置对应
+type ProxyProfile struct {
+	Method      string
+	AutoProxy   string                 `json:",omitempty"`
+	IgnoreHosts []string               `json:",omitempty"`
+	Proxies     map[string]ProxyServer `json:",omitempty"` // 代理类型 => 代理服务器
+}
+
+type proxyProfiles struct {
+	Connections map[string]*ProxyProfile // 连接 uuid => 代理配置
+	// 切换到连接的代理配置前的全局代理配置，切换到没有代理配置的连接时恢复
+	Default *ProxyProfile `json:",omitempty"`
+	// 当前生效的代理配置对应的连接 uuid，为空时使用全局代理配置
+	Active string `json:",omitempty"`
+}
+
+func (p *ProxyProfile) check() error {
+	err := checkProxyMethod(p.Method)
+	if err != nil {
+		return err
+	}
+	if p.Method == proxyModeAuto && p.AutoProxy == "" {
+		return errors.New("auto proxy URL is empty")
+	}
+	for proxyType, server := range p.Proxies {
+		switch proxyType {
+		case proxyTypeHttp, proxyTypeHttps, proxyTypeFtp, proxyTypeSocks:
+		default:
+			return fmt.Errorf("not a valid proxy type: %s", proxyType)
+		}
+		if server.Port < 0 || server.Port > 65535 {
+			return fmt.Errorf("invalid port %d of %s proxy", server.Port, proxyType)
+		}
+	}
+	return nil
+}
+
+func newProxyProfiles() *proxyProfiles {
+	return &proxyProfiles{
+		Connections: make(map[string]*ProxyProfile),
+	}
+}
+
+func loadProxyProfiles(file string) *proxyProfiles {
+	profiles := newProxyProfiles()
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warning(err)
+		}
+		return profiles
+	}
+	err = json.Unmarshal(data, profiles)
+	if err != nil {
+		logger.Warning(err)
+	}
+	if profiles.Connections == nil {
+		profiles.Connections = make(map[string]*ProxyProfile)
+	}
+	return profiles
+}
+
+func (p *proxyProfiles) save(file string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(file), 0755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0600)
+}
+
+// switchTo 切换到连接 uuid 的代理配置，返回需要应用的代理配置，不需要切换时返回 nil。
+// current 返回当前的全局代理配置，第一次切换到连接的代理配置时保存下来
+func (p *proxyProfiles) switchTo(uuid string, current func() *ProxyProfile) *ProxyProfile {
+	if profile, ok := p.Connections[uuid]; ok {
+		if p.Active == uuid {
+			return nil
+		}
+		if p.Active == "" {
+			p.Default = current()
+		}
+		p.Active = uuid
+		return profile
+	}
+
+	if p.Active == "" {
+		return nil
+	}
+	p.Active = ""
+	profile := p.Default
+	p.Default = nil
+	if profile == nil {
+		profile = &ProxyProfile{Method: proxyModeNone}
+	}
+	return profile
+}
+
+// getCurrentProxyProfile 从 gsettings 读取当前的全局代理配置
+func getCurrentProxyProfile() *ProxyProfile {
+	profile := &ProxyProfile{
+		Method:      proxySettings.GetString(gkeyProxyMode),
+		AutoProxy:   proxySettings.GetString(gkeyProxyAuto),
+		IgnoreHosts: proxySettings.GetStrv(gkeyProxyIgnoreHosts),
+		Proxies:     make(map[string]ProxyServer),
+	}
+	for _, proxyType := range []string{proxyTypeHttp, proxyTypeHttps, proxyTypeFtp, proxyTypeSocks} {
+		childSettings, err := getProxyChildSettings(proxyType)
+		if err != nil {
+			continue
+		}
+		host := childSettings.GetString(gkeyProxyHost)
+		if host == "" {
+			continue
+		}
+		profile.Proxies[proxyType] = ProxyServer{
+			Host: host,
+			Port: childSettings.GetInt(gkeyProxyPort),
+		}
+	}
+	return profile
+}
+
+// applyProxyProfile 把代理配置写入 gsettings
+func (m *Manager) applyProxyProfile(profile *ProxyProfile) {
+	logger.Debugf("apply proxy profile %+v", *profile)
+	if !proxySettings.SetString(gkeyProxyAuto, profile.AutoProxy) {
+		logger.Warning("set autoconfig-url proxy through gsettings failed")
+	}
+	if !proxySettings.SetStrv(gkeyProxyIgnoreHosts, profile.IgnoreHosts) {
+		logger.Warning("set ignore-hosts proxy through gsettings failed")
+	}
+	for _, proxyType := range []string{proxyTypeHttp, proxyTypeHttps, proxyTypeFtp, proxyTypeSocks} {
+		server := profile.Proxies[proxyType]
+		err := m.setProxy(proxyType, server.Host, strconv.Itoa(int(server.Port)))
+		if err != nil {
+			logger.Warning(err)
+		}
+	}
+	err := m.setProxyMethod(profile.Method)
+	if err != nil {
+		logger.Warning(err)
+	}
+}
+
+func (m *Manager) initProxyProfiles() {
+	m.proxyProfilesLock.Lock()
+	m.proxyProfiles = loadProxyProfiles(proxyProfilesFile)
+	m.proxyProfilesLock.Unlock()
+
+	err := nmManager.PrimaryConnection().ConnectChanged(func(hasValue bool, value dbus.ObjectPath) {
+		if !hasValue {
+			return
+		}
+		go m.updateProxyProfile(value)
+	})
+	if err != nil {
+		logger.Warning(err)
+	}
+	m.updateProxyProfile(nmGetPrimaryConnection())
+}
+
+func getActiveConnectionUuid(apath dbus.ObjectPath) string {
+	if apath == "" || apath == "/" {
+		return ""
+	}
+	aconn, err := nmNewActiveConnection(apath)
+	if err != nil {
+		return ""
+	}
+	uuid, err := aconn.Uuid().Get(0)
+	if err != nil {
+		logger.Warning(err)
+	}
+	return uuid
+}
+
+// updateProxyProfile 主连接变化时切换到主连接的代理配置
+func (m *Manager) updateProxyProfile(primaryConn dbus.ObjectPath) {
+	uuid := getActiveConnectionUuid(primaryConn)
+
+	m.proxyProfilesLock.Lock()
+	defer m.proxyProfilesLock.Unlock()
+	m.doSwitchProxyProfile(uuid)
+}
+
+// doSwitchProxyProfile 需要持有 proxyProfilesLock
+func (m *Manager) doSwitchProxyProfile(uuid string) {
+	profile := m.proxyProfiles.switchTo(uuid, getCurrentProxyProfile)
+	if profile == nil {
+		return
+	}
+	logger.Infof("switch proxy profile for connection %q", uuid)
+	m.applyProxyProfile(profile)
+	err := m.proxyProfiles.save(proxyProfilesFile)
+	if err != nil {
+		logger.Warning(err)
+	}
+}
+
+// ListProxyProfiles 返回 JSON 格式的连接代理配置，键为连接 uuid
+func (m *Manager) ListProxyProfiles() (profiles string, busErr *dbus.Error) {
+	m.proxyProfilesLock.Lock()
+	defer m.proxyProfilesLock.Unlock()
+	profiles, err := marshalJSON(m.proxyProfiles.Connections)
+	return profiles, dbusutil.ToError(err)
+}
+
+// SetProxyProfile 设置连接 uuid 的代理配置，profile 为 JSON 格式，连接为主连接时立即生效
+func (m *Manager) SetProxyProfile(uuid string, profile string) *dbus.Error {
+	logger.Infof("SetProxyProfile uuid: %q, profile: %s", uuid, profile)
+	if uuid == "" {
+		return dbusutil.ToError(errors.New("connection uuid is empty"))
+	}
+	var p ProxyProfile
+	err := json.Unmarshal([]byte(profile), &p)
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+	err = p.check()
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+
+	primaryUuid := getActiveConnectionUuid(nmGetPrimaryConnection())
+
+	m.proxyProfilesLock.Lock()
+	defer m.proxyProfilesLock.Unlock()
+	m.proxyProfiles.Connections[uuid] = &p
+	if m.proxyProfiles.Active == uuid {
+		m.applyProxyProfile(&p)
+	} else if primaryUuid == uuid {
+		m.doSwitchProxyProfile(uuid)
+		return nil
+	}
+	return dbusutil.ToError(m.proxyProfiles.save(proxyProfilesFile))
+}
+
+// DeleteProxyProfile 删除连接 uuid 的代理配置，配置正在生效时恢复全局代理配置
+func (m *Manager) DeleteProxyProfile(uuid string) *dbus.Error {
+	logger.Infof("DeleteProxyProfile uuid: %q", uuid)
+	m.proxyProfilesLock.Lock()
+	defer m.proxyProfilesLock.Unlock()
+	if _, ok := m.proxyProfiles.Connections[uuid]; !ok {
+		return dbusutil.ToError(fmt.Errorf("proxy profile of connection %q not found", uuid))
+	}
+	delete(m.proxyProfiles.Connections, uuid)
+	if m.proxyProfiles.Active == uuid {
+		m.doSwitchProxyProfile("")
+		return nil
+	}
+	return dbusutil.ToError(m.proxyProfiles.save(proxyProfilesFile))
+}
+
+// matchIgnoreHost 判断主机是否匹配 ignore-hosts 中的一项，支持域名后缀、通配符和 CIDR
+func matchIgnoreHost(host string, ignoreHost string) bool {
+	ignoreHost = strings.TrimSpace(ignoreHost)
+	if ignoreHost == "" {
+		return false
+	}
+	if _, ipNet, err := net.ParseCIDR(ignoreHost); err == nil {
+		ip := net.ParseIP(host)
+		return ip != nil && ipNet.Contains(ip)
+	}
+	host = strings.ToLower(host)
+	ignoreHost = strings.ToLower(ignoreHost)
+	suffix := strings.TrimPrefix(ignoreHost, "*")
+	if strings.HasPrefix(suffix, ".") {
+		return strings.HasSuffix(host, suffix) || host == suffix[1:]
+	}
+	return host == ignoreHost
+}
+
+// evaluateManualProxy 返回手动代理模式下访问 rawURL 使用的代理，格式和 PAC 的返回值相同
+func evaluateManualProxy(rawURL string, profile *ProxyProfile) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("invalid url %q", rawURL)
+	}
+	for _, ignoreHost := range profile.IgnoreHosts {
+		if matchIgnoreHost(u.Hostname(), ignoreHost) {
+			return proxyResultDirect, nil
+		}
+	}
+
+	var proxyType string
+	switch u.Scheme {
+	case "http":
+		proxyType = proxyTypeHttp
+	case "https":
+		proxyType = proxyTypeHttps
+	case "ftp":
+		proxyType = proxyTypeFtp
+	}
+	if server, ok := profile.Proxies[proxyType]; ok && server.Host != "" {
+		return fmt.Sprintf("PROXY %s", net.JoinHostPort(server.Host, strconv.Itoa(int(server.Port)))), nil
+	}
+	if server, ok := profile.Proxies[proxyTypeSocks]; ok && server.Host != "" {
+		return fmt.Sprintf("SOCKS %s", net.JoinHostPort(server.Host, strconv.Itoa(int(server.Port)))), nil
+	}
+	return proxyResultDirect, nil
+}
+
+// fetchPacFile 下载或读取 PAC 文件，返回保存 PAC 内容的临时文件
+func fetchPacFile(pacURL string) (string, error) {
+	u, err := url.Parse(pacURL)
+	if err != nil {
+		return "", err
+	}
+
+	var data []byte
+	switch u.Scheme {
+	case "http", "https":
+		client := &http.Client{Timeout: pacFetchTimeout}
+		resp, err := client.Get(pacURL)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("failed to fetch PAC file %s: %s", pacURL, resp.Status)
+		}
+		data, err = io.ReadAll(io.LimitReader(resp.Body, pacMaxFileSize))
+		if err != nil {
+			return "", err
+		}
+	case "file", "":
+		data, err = os.ReadFile(u.Path)
+		if err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("unsupported PAC url %q", pacURL)
+	}
+
+	f, err := os.CreateTemp("", "dde-network-pac-")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	_, err = f.Write(data)
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+func evaluatePac(pacURL string, rawURL string) (string, error) {
+	pacTester, err := exec.LookPath(pacTesterCmd)
+	if err != nil {
+		return "", errPacUnavailable
+	}
+	pacFile, err := fetchPacFile(pacURL)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(pacFile)
+
+	out, err := exec.Command(pacTester, "-p", pacFile, "-u", rawURL).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to evaluate PAC file: %v %s", err, out)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// EvaluateProxy 返回按照当前代理配置访问 url 时使用的代理，格式和 PAC 的返回值相同，例如 "PROXY host:port; DIRECT"
+func (m *Manager) EvaluateProxy(url string) (proxy string, busErr *dbus.Error) {
+	logger.Debug("EvaluateProxy", url)
+	profile := getCurrentProxyProfile()
+	var err error
+	switch profile.Method {
+	case proxyModeAuto:
+		proxy, err = evaluatePac(profile.AutoProxy, url)
+	case proxyModeManual:
+		proxy, err = evaluateManualProxy(url, profile)
+	default:
+		proxy = proxyResultDirect
+	}
+	if err != nil {
+		logger.Warning(err)
+		return "", dbusutil.ToError(err)
+	}
+	return proxy, nil
+}
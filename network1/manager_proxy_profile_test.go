@@ -0,0 +1,145 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package network
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxyProfileCheck(t *testing.T) {
+	valid := []*ProxyProfile{
+		{Method: proxyModeNone},
+		{Method: proxyModeAuto, AutoProxy: "http://wpad/wpad.dat"},
+		{Method: proxyModeManual, Proxies: map[string]ProxyServer{proxyTypeHttp: {Host: "10.0.0.1", Port: 8080}}},
+	}
+	for _, p := range valid {
+		assert.NoError(t, p.check(), "%+v", p)
+	}
+
+	invalid := []*ProxyProfile{
+		{Method: "pac"},
+		{Method: proxyModeAuto},
+		{Method: proxyModeManual, Proxies: map[string]ProxyServer{"gopher": {Host: "10.0.0.1", Port: 70}}},
+		{Method: proxyModeManual, Proxies: map[string]ProxyServer{proxyTypeSocks: {Host: "10.0.0.1", Port: 70000}}},
+	}
+	for _, p := range invalid {
+		assert.Error(t, p.check(), "%+v", p)
+	}
+}
+
+func TestProxyProfilesSwitchTo(t *testing.T) {
+	office := &ProxyProfile{Method: proxyModeAuto, AutoProxy: "http://wpad/wpad.dat"}
+	global := &ProxyProfile{Method: proxyModeManual, Proxies: map[string]ProxyServer{proxyTypeHttp: {Host: "10.0.0.1", Port: 8080}}}
+	current := func() *ProxyProfile { return global }
+
+	profiles := newProxyProfiles()
+	profiles.Connections["office"] = office
+
+	// 没有代理配置的连接不切换
+	assert.Nil(t, profiles.switchTo("home", current))
+	assert.Equal(t, office, profiles.switchTo("office", current))
+	assert.Equal(t, "office", profiles.Active)
+	assert.Equal(t, global, profiles.Default)
+	assert.Nil(t, profiles.switchTo("office", current))
+
+	// 切换到没有代理配置的连接时恢复全局代理配置
+	assert.Equal(t, global, profiles.switchTo("home", current))
+	assert.Empty(t, profiles.Active)
+	assert.Nil(t, profiles.Default)
+	assert.Nil(t, profiles.switchTo("", current))
+
+	profiles.Active = "deleted"
+	assert.Equal(t, &ProxyProfile{Method: proxyModeNone}, profiles.switchTo("", current))
+}
+
+func TestProxyProfilesSaveLoad(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "deepin", "network-proxy-profiles.json")
+	profiles := loadProxyProfiles(file)
+	require.NotNil(t, profiles.Connections)
+
+	profiles.Connections["office"] = &ProxyProfile{Method: proxyModeAuto, AutoProxy: "http://wpad/wpad.dat"}
+	profiles.Default = &ProxyProfile{Method: proxyModeNone}
+	profiles.Active = "office"
+	require.NoError(t, profiles.save(file))
+
+	assert.Equal(t, profiles, loadProxyProfiles(file))
+}
+
+func TestMatchIgnoreHost(t *testing.T) {
+	tests := []struct {
+		host       string
+		ignoreHost string
+		want       bool
+	}{
+		{"localhost", "localhost", true},
+		{"www.example.com", "*.example.com", true},
+		{"example.com", ".example.com", true},
+		{"badexample.com", ".example.com", false},
+		{"192.168.1.20", "192.168.0.0/16", true},
+		{"10.0.0.1", "192.168.0.0/16", false},
+		{"example.org", "example.com", false},
+		{"example.org", "", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, matchIgnoreHost(tt.host, tt.ignoreHost), "%s %s", tt.host, tt.ignoreHost)
+	}
+}
+
+func TestEvaluateManualProxy(t *testing.T) {
+	profile := &ProxyProfile{
+		Method:      proxyModeManual,
+		IgnoreHosts: []string{"localhost", "*.corp.example.com"},
+		Proxies: map[string]ProxyServer{
+			proxyTypeHttp:  {Host: "10.0.0.1", Port: 8080},
+			proxyTypeSocks: {Host: "10.0.0.2", Port: 1080},
+		},
+	}
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"http://www.example.com/", "PROXY 10.0.0.1:8080"},
+		{"https://www.example.com/", "SOCKS 10.0.0.2:1080"},
+		{"http://git.corp.example.com/", "DIRECT"},
+		{"http://localhost:8000/", "DIRECT"},
+	}
+	for _, tt := range tests {
+		got, err := evaluateManualProxy(tt.url, profile)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, tt.url)
+	}
+
+	_, err := evaluateManualProxy("not a url", profile)
+	assert.Error(t, err)
+}
+
+func TestFetchPacFile(t *testing.T) {
+	pac := filepath.Join(t.TempDir(), "proxy.pac")
+	content := `function FindProxyForURL(url, host) { return "DIRECT"; }`
+	require.NoError(t, os.WriteFile(pac, []byte(content), 0644))
+
+	for _, pacURL := range []string{pac, "file://" + pac} {
+		file, err := fetchPacFile(pacURL)
+		require.NoError(t, err)
+		data, err := os.ReadFile(file)
+		os.Remove(file)
+		require.NoError(t, err)
+		assert.Equal(t, content, string(data))
+	}
+
+	_, err := fetchPacFile("ftp://example.com/proxy.pac")
+	assert.Error(t, err)
+}
+
+func TestEvaluatePacUnavailable(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	_, err := evaluatePac("/nonexistent/proxy.pac", "http://example.com")
+	assert.Equal(t, errPacUnavailable, err)
+}
@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	dbus "github.com/godbus/dbus/v5"
+	"github.com/linuxdeepin/dde-daemon/network1/nm"
+	"github.com/linuxdeepin/go-lib/dbusutil"
+	"github.com/linuxdeepin/go-lib/utils"
+)
+
+// updateWireGuardConnection 修改 WireGuard 连接 uuid 的设置并保存
+func updateWireGuardConnection(uuid string, fn func(data connectionData) error) error {
+	cpath, data, err := getConnectionSettingsForUpdate(uuid)
+	if err != nil {
+		return err
+	}
+	if getSettingConnectionType(data) != nm.NM_SETTING_WIREGUARD_SETTING_NAME {
+		return fmt.Errorf("%s is not a wireguard connection", uuid)
+	}
+	err = fn(data)
+	if err != nil {
+		return err
+	}
+	return updateConnectionSettings(cpath, data)
+}
+
+// GenerateWireGuardKeyPair 生成 base64 编码的 WireGuard 密钥对
+func (m *Manager) GenerateWireGuardKeyPair() (privateKey, publicKey string, busErr *dbus.Error) {
+	privateKey, publicKey, err := generateWireGuardKeyPair()
+	return privateKey, publicKey, dbusutil.ToError(err)
+}
+
+// ImportWireGuardConfig 导入 wg-quick 格式的配置文件，文件名作为连接名和网卡名
+func (m *Manager) ImportWireGuardConfig(file string) (cpath dbus.ObjectPath, busErr *dbus.Error) {
+	logger.Info("ImportWireGuardConfig", file)
+	cpath, err := m.importWireGuardConfig(file)
+	return cpath, dbusutil.ToError(err)
+}
+
+func (m *Manager) importWireGuardConfig(file string) (cpath dbus.ObjectPath, err error) {
+	ifc := strings.TrimSuffix(filepath.Base(file), ".conf")
+	if !wireGuardIfcNameRegexp.MatchString(ifc) {
+		return "", fmt.Errorf("invalid interface name %q", ifc)
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	cfg, err := parseWgQuickConfig(f)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s: %v", file, err)
+	}
+	data := newWireGuardConnectionDataFromConfig(ifc, utils.GenUuid(), ifc, cfg)
+	return nmAddConnection(data)
+}
+
+// GetWireGuardPeers 返回 JSON 格式的对端列表，由 SecretAgent 保存的预共享密钥不会返回
+func (m *Manager) GetWireGuardPeers(uuid string) (peers string, busErr *dbus.Error) {
+	_, data, err := getConnectionSettingsForUpdate(uuid)
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	if getSettingConnectionType(data) != nm.NM_SETTING_WIREGUARD_SETTING_NAME {
+		return "", dbusutil.ToError(fmt.Errorf("%s is not a wireguard connection", uuid))
+	}
+	peers, err = marshalJSON(getSettingWireGuardPeers(data))
+	return peers, dbusutil.ToError(err)
+}
+
+// SetWireGuardPeers 设置 JSON 格式的对端列表，新的预共享密钥由 SecretAgent 保存
+func (m *Manager) SetWireGuardPeers(uuid string, peers string) *dbus.Error {
+	logger.Info("SetWireGuardPeers", uuid)
+	var list []wireGuardPeer
+	err := json.Unmarshal([]byte(peers), &list)
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+	err = checkWireGuardPeers(list)
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+	for i := range list {
+		if list[i].PresharedKey != "" {
+			list[i].PresharedKeyFlags = nm.NM_SETTING_SECRET_FLAG_AGENT_OWNED
+		}
+	}
+	err = updateWireGuardConnection(uuid, func(data connectionData) error {
+		setSettingWireGuardPeers(data, list)
+		return nil
+	})
+	return dbusutil.ToError(err)
+}
+
+// SetWireGuardPrivateKey 设置连接的私钥并返回对应的公钥，私钥为空时生成新的密钥对
+func (m *Manager) SetWireGuardPrivateKey(uuid string, privateKey string) (publicKey string, busErr *dbus.Error) {
+	logger.Info("SetWireGuardPrivateKey", uuid)
+	var err error
+	if privateKey == "" {
+		privateKey, publicKey, err = generateWireGuardKeyPair()
+	} else {
+		publicKey, err = getWireGuardPublicKey(privateKey)
+	}
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	err = updateWireGuardConnection(uuid, func(data connectionData) error {
+		setSettingWireGuardPrivateKey(data, privateKey)
+		setSettingWireGuardPrivateKeyFlags(data, nm.NM_SETTING_SECRET_FLAG_AGENT_OWNED)
+		return nil
+	})
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	return publicKey, nil
+}
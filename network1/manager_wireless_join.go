@@ -0,0 +1,236 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package network
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	dbus "github.com/godbus/dbus/v5"
+	"github.com/linuxdeepin/dde-daemon/network1/nm"
+	nmdbus "github.com/linuxdeepin/go-dbus-factory/system/org.freedesktop.networkmanager"
+	"github.com/linuxdeepin/go-lib/dbusutil"
+)
+
+// NetworkManager 允许的最大自动连接优先级
+const maxAutoconnectPriority = 999
+
+// wirelessJoinInfo 已保存的无线连接的自动连接策略
+type wirelessJoinInfo struct {
+	Uuid        string
+	Id          string
+	Ssid        string
+	AutoConnect bool
+	Priority    int32
+	Metered     int32
+	Band        string   // 限定的频段，a 为 5GHz，bg 为 2.4GHz，为空时不限定
+	Bssid       string   // 限定的接入点，为空时不限定
+	SeenBssids  []string // 连接过的接入点
+	Timestamp   uint64   // 最后一次连接成功的时间
+}
+
+func newWirelessJoinInfo(data connectionData) *wirelessJoinInfo {
+	info := &wirelessJoinInfo{
+		Uuid:        getSettingConnectionUuid(data),
+		Id:          getSettingConnectionId(data),
+		Ssid:        decodeSsid(getSettingWirelessSsid(data)),
+		AutoConnect: getSettingConnectionAutoconnect(data),
+		Priority:    getSettingConnectionAutoconnectPriority(data),
+		Metered:     getSettingConnectionMetered(data),
+		Band:        getSettingWirelessBand(data),
+		SeenBssids:  getSettingWirelessSeenBssids(data),
+		Timestamp:   getSettingConnectionTimestamp(data),
+	}
+	if isSettingWirelessBssidExists(data) {
+		info.Bssid = convertMacAddressToString(getSettingWirelessBssid(data))
+	}
+	return info
+}
+
+// sortWirelessJoinOrder 按 NetworkManager 选择自动连接的顺序排序：
+// 允许自动连接的在前，然后按优先级从高到低，优先级相同时最近连接过的在前
+func sortWirelessJoinOrder(infos []*wirelessJoinInfo) {
+	sort.SliceStable(infos, func(i, j int) bool {
+		a, b := infos[i], infos[j]
+		if a.AutoConnect != b.AutoConnect {
+			return a.AutoConnect
+		}
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		if a.Timestamp != b.Timestamp {
+			return a.Timestamp > b.Timestamp
+		}
+		return a.Id < b.Id
+	})
+}
+
+// getWirelessJoinPriorities 计算按 order 排列时各连接的新优先级，只返回需要修改的连接。
+// order 中的连接优先级从高到低排列，其余优先级大于 0 的连接降为 0，排在它们后面
+func getWirelessJoinPriorities(order []string, infos []*wirelessJoinInfo) (map[string]int32, error) {
+	if len(order) > maxAutoconnectPriority {
+		return nil, fmt.Errorf("too many connections: %d", len(order))
+	}
+	current := make(map[string]int32, len(infos))
+	for _, info := range infos {
+		current[info.Uuid] = info.Priority
+	}
+
+	priorities := make(map[string]int32)
+	listed := make(map[string]bool, len(order))
+	for i, uuid := range order {
+		if _, ok := current[uuid]; !ok {
+			return nil, fmt.Errorf("%s is not a saved wireless connection", uuid)
+		}
+		if listed[uuid] {
+			return nil, fmt.Errorf("duplicate connection %s", uuid)
+		}
+		listed[uuid] = true
+		priority := int32(len(order) - i)
+		if current[uuid] != priority {
+			priorities[uuid] = priority
+		}
+	}
+	for uuid, priority := range current {
+		if !listed[uuid] && priority > 0 {
+			priorities[uuid] = 0
+		}
+	}
+	return priorities, nil
+}
+
+func (m *Manager) getWirelessJoinInfos() (infos []*wirelessJoinInfo) {
+	m.connectionsLock.Lock()
+	nmConns := make([]nmdbus.ConnectionSettings, 0, len(m.connections[connectionWireless]))
+	for _, conn := range m.connections[connectionWireless] {
+		nmConns = append(nmConns, conn.nmConn)
+	}
+	m.connectionsLock.Unlock()
+
+	for _, nmConn := range nmConns {
+		data, err := nmConn.GetSettings(0)
+		if err != nil {
+			logger.Warning(err)
+			continue
+		}
+		infos = append(infos, newWirelessJoinInfo(data))
+	}
+	sortWirelessJoinOrder(infos)
+	return
+}
+
+// getWirelessConnectionSettings 返回无线连接 uuid 用于修改的设置
+func getWirelessConnectionSettings(uuid string) (cpath dbus.ObjectPath, data connectionData, err error) {
+	cpath, data, err = getConnectionSettingsForUpdate(uuid)
+	if err != nil {
+		return "", nil, err
+	}
+	if getSettingConnectionType(data) != nm.NM_SETTING_WIRELESS_SETTING_NAME {
+		return "", nil, fmt.Errorf("%s is not a wireless connection", uuid)
+	}
+	return cpath, data, nil
+}
+
+// updateWirelessConnection 修改无线连接 uuid 的设置并保存
+func updateWirelessConnection(uuid string, fn func(data connectionData) error) error {
+	cpath, data, err := getWirelessConnectionSettings(uuid)
+	if err != nil {
+		return err
+	}
+	err = fn(data)
+	if err != nil {
+		return err
+	}
+	return updateConnectionSettings(cpath, data)
+}
+
+// ListWirelessJoinOrder 返回 JSON 格式的已保存无线连接，按自动连接的顺序排列
+func (m *Manager) ListWirelessJoinOrder() (connections string, busErr *dbus.Error) {
+	connections, err := marshalJSON(m.getWirelessJoinInfos())
+	return connections, dbusutil.ToError(err)
+}
+
+// SetWirelessJoinOrder 按 uuids 的顺序设置无线连接的自动连接优先级，排在前面的优先连接
+func (m *Manager) SetWirelessJoinOrder(uuids []string) *dbus.Error {
+	logger.Info("SetWirelessJoinOrder", uuids)
+	priorities, err := getWirelessJoinPriorities(uuids, m.getWirelessJoinInfos())
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+	uuidList := make([]string, 0, len(priorities))
+	for uuid := range priorities {
+		uuidList = append(uuidList, uuid)
+	}
+	sort.Strings(uuidList)
+
+	// 先获取所有连接的设置，有连接无法修改时不做任何修改
+	cpaths := make([]dbus.ObjectPath, len(uuidList))
+	settings := make([]connectionData, len(uuidList))
+	for i, uuid := range uuidList {
+		cpaths[i], settings[i], err = getWirelessConnectionSettings(uuid)
+		if err != nil {
+			return dbusutil.ToError(err)
+		}
+		setSettingConnectionAutoconnectPriority(settings[i], priorities[uuid])
+	}
+
+	// 单个连接保存失败时继续修改其他连接，最后返回失败的连接
+	var failed []string
+	for i, uuid := range uuidList {
+		err = updateConnectionSettings(cpaths[i], settings[i])
+		if err != nil {
+			logger.Warningf("failed to set autoconnect priority of %s: %v", uuid, err)
+			failed = append(failed, uuid)
+		}
+	}
+	if len(failed) > 0 {
+		return dbusutil.ToError(fmt.Errorf("failed to set autoconnect priority of %s", strings.Join(failed, ", ")))
+	}
+	return nil
+}
+
+// SetWirelessBand 限定无线连接使用的频段，a 为 5GHz，bg 为 2.4GHz，为空时不限定
+func (m *Manager) SetWirelessBand(uuid string, band string) *dbus.Error {
+	logger.Info("SetWirelessBand", uuid, band)
+	err := updateWirelessConnection(uuid, func(data connectionData) error {
+		return logicSetSettingWirelessBand(data, band)
+	})
+	return dbusutil.ToError(err)
+}
+
+// SetWirelessBssid 限定无线连接只使用 BSSID 对应的接入点，为空时不限定
+func (m *Manager) SetWirelessBssid(uuid string, bssid string) *dbus.Error {
+	logger.Info("SetWirelessBssid", uuid, bssid)
+	err := updateWirelessConnection(uuid, func(data connectionData) error {
+		return logicSetSettingWirelessBssid(data, bssid)
+	})
+	return dbusutil.ToError(err)
+}
+
+// SetWirelessAutoJoin 设置无线连接是否自动连接
+func (m *Manager) SetWirelessAutoJoin(uuid string, autoJoin bool) *dbus.Error {
+	logger.Info("SetWirelessAutoJoin", uuid, autoJoin)
+	err := updateWirelessConnection(uuid, func(data connectionData) error {
+		setSettingConnectionAutoconnect(data, autoJoin)
+		return nil
+	})
+	return dbusutil.ToError(err)
+}
+
+// SetWirelessMetered 设置无线连接是否按流量计费，取值同 NetworkManager 的 NMMetered
+func (m *Manager) SetWirelessMetered(uuid string, metered int32) *dbus.Error {
+	logger.Info("SetWirelessMetered", uuid, metered)
+	switch metered {
+	case nm.NM_METERED_UNKNOWN, nm.NM_METERED_YES, nm.NM_METERED_NO:
+	default:
+		return dbusutil.ToError(fmt.Errorf("invalid metered value %d", metered))
+	}
+	err := updateWirelessConnection(uuid, func(data connectionData) error {
+		setSettingConnectionMetered(data, metered)
+		return nil
+	})
+	return dbusutil.ToError(err)
+}
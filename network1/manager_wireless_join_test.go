@@ -0,0 +1,96 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package network
+
+import (
+	"testing"
+
+	"github.com/linuxdeepin/dde-daemon/network1/nm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWirelessData(id, ssid string) connectionData {
+	data := make(connectionData)
+	addSetting(data, nm.NM_SETTING_CONNECTION_SETTING_NAME)
+	setSettingConnectionId(data, id)
+	setSettingConnectionUuid(data, id+"-uuid")
+	setSettingConnectionType(data, nm.NM_SETTING_WIRELESS_SETTING_NAME)
+	addSetting(data, nm.NM_SETTING_WIRELESS_SETTING_NAME)
+	setSettingWirelessSsid(data, []byte(ssid))
+	return data
+}
+
+func TestNewWirelessJoinInfo(t *testing.T) {
+	data := newTestWirelessData("home", "HomeWiFi")
+	info := newWirelessJoinInfo(data)
+	assert.Equal(t, "home-uuid", info.Uuid)
+	assert.Equal(t, "HomeWiFi", info.Ssid)
+	assert.True(t, info.AutoConnect)
+	assert.Zero(t, info.Priority)
+	assert.Empty(t, info.Bssid)
+
+	setSettingConnectionAutoconnectPriority(data, 5)
+	setSettingConnectionMetered(data, nm.NM_METERED_YES)
+	require.NoError(t, logicSetSettingWirelessBand(data, "a"))
+	require.NoError(t, logicSetSettingWirelessBssid(data, "00:11:22:AA:BB:CC"))
+	info = newWirelessJoinInfo(data)
+	assert.Equal(t, int32(5), info.Priority)
+	assert.Equal(t, int32(nm.NM_METERED_YES), info.Metered)
+	assert.Equal(t, "a", info.Band)
+	assert.Equal(t, "00:11:22:AA:BB:CC", info.Bssid)
+}
+
+func TestLogicSetSettingWirelessBandBssid(t *testing.T) {
+	data := newTestWirelessData("office", "Office")
+	setSettingWirelessChannel(data, 6)
+
+	require.NoError(t, logicSetSettingWirelessBand(data, "bg"))
+	assert.Equal(t, "bg", getSettingWirelessBand(data))
+	assert.False(t, isSettingWirelessChannelExists(data))
+	require.NoError(t, logicSetSettingWirelessBand(data, ""))
+	assert.False(t, isSettingWirelessBandExists(data))
+	assert.Error(t, logicSetSettingWirelessBand(data, "6GHz"))
+
+	require.NoError(t, logicSetSettingWirelessBssid(data, "00:11:22:33:44:55"))
+	assert.True(t, isSettingWirelessBssidExists(data))
+	require.NoError(t, logicSetSettingWirelessBssid(data, ""))
+	assert.False(t, isSettingWirelessBssidExists(data))
+	assert.Error(t, logicSetSettingWirelessBssid(data, "00:11:22"))
+}
+
+func TestSortWirelessJoinOrder(t *testing.T) {
+	infos := []*wirelessJoinInfo{
+		{Id: "manual", AutoConnect: false, Priority: 100},
+		{Id: "old", AutoConnect: true, Timestamp: 100},
+		{Id: "recent", AutoConnect: true, Timestamp: 200},
+		{Id: "preferred", AutoConnect: true, Priority: 10},
+		{Id: "a-never", AutoConnect: true},
+		{Id: "b-never", AutoConnect: true},
+	}
+	sortWirelessJoinOrder(infos)
+	var ids []string
+	for _, info := range infos {
+		ids = append(ids, info.Id)
+	}
+	assert.Equal(t, []string{"preferred", "recent", "old", "a-never", "b-never", "manual"}, ids)
+}
+
+func TestGetWirelessJoinPriorities(t *testing.T) {
+	infos := []*wirelessJoinInfo{
+		{Uuid: "a", Priority: 3},
+		{Uuid: "b", Priority: 0},
+		{Uuid: "c", Priority: 5},
+		{Uuid: "d", Priority: -10},
+	}
+	priorities, err := getWirelessJoinPriorities([]string{"b", "a"}, infos)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int32{"b": 2, "a": 1, "c": 0}, priorities)
+
+	_, err = getWirelessJoinPriorities([]string{"a", "a"}, infos)
+	assert.Error(t, err)
+	_, err = getWirelessJoinPriorities([]string{"x"}, infos)
+	assert.Error(t, err)
+}
@@ -1758,6 +1758,19 @@ const (
 	NM_SETTING_WIMAX_NETWORK_NAME = "network-name"
 )
 
+// Setting SettingWireGuard
+const NM_SETTING_WIREGUARD_SETTING_NAME = "wireguard"
+const (
+	NM_SETTING_WIREGUARD_FWMARK                 = "fwmark"
+	NM_SETTING_WIREGUARD_IP4_AUTO_DEFAULT_ROUTE = "ip4-auto-default-route"
+	NM_SETTING_WIREGUARD_IP6_AUTO_DEFAULT_ROUTE = "ip6-auto-default-route"
+	NM_SETTING_WIREGUARD_LISTEN_PORT            = "listen-port"
+	NM_SETTING_WIREGUARD_MTU                    = "mtu"
+	NM_SETTING_WIREGUARD_PEER_ROUTES            = "peer-routes"
+	NM_SETTING_WIREGUARD_PRIVATE_KEY            = "private-key"
+	NM_SETTING_WIREGUARD_PRIVATE_KEY_FLAGS      = "private-key-flags"
+)
+
 // Setting SettingWired
 const NM_SETTING_WIRED_SETTING_NAME = "802-3-ethernet"
 const (
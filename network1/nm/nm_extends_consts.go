@@ -144,3 +144,15 @@ const (
 	NM_VPNC_SECRET_FLAG_ASK    = 3
 	NM_VPNC_SECRET_FLAG_UNUSED = 5
 )
+
+// WireGuard，peers 的类型为 aa{sv}，不在生成的键值中
+const (
+	NM_SETTING_WIREGUARD_PEERS = "peers"
+
+	NM_WIREGUARD_PEER_ATTR_PUBLIC_KEY           = "public-key"
+	NM_WIREGUARD_PEER_ATTR_ENDPOINT             = "endpoint"
+	NM_WIREGUARD_PEER_ATTR_ALLOWED_IPS          = "allowed-ips"
+	NM_WIREGUARD_PEER_ATTR_PERSISTENT_KEEPALIVE = "persistent-keepalive"
+	NM_WIREGUARD_PEER_ATTR_PRESHARED_KEY        = "preshared-key"
+	NM_WIREGUARD_PEER_ATTR_PRESHARED_KEY_FLAGS  = "preshared-key-flags"
+)
@@ -90,6 +90,7 @@ const (
 	connectionVpnStrongswan   = "vpn-strongswan"
 	connectionVpnPptp         = "vpn-pptp"
 	connectionVpnVpnc         = "vpn-vpnc"
+	connectionWireGuard       = "wireguard"
 )
 
 // wrapper for custom connection types
@@ -114,6 +115,7 @@ var supportedConnectionTypes = []string{
 	connectionVpnPptp,
 	connectionVpnStrongswan,
 	connectionVpnVpnc,
+	connectionWireGuard,
 }
 
 // return custom connection type, and the wrapper types will be ignored, e.g. connectionMobile.
@@ -156,6 +158,8 @@ func getCustomConnectionType(data connectionData) (connType string) {
 		case nm.NM_DBUS_SERVICE_VPNC:
 			connType = connectionVpnVpnc
 		}
+	case nm.NM_SETTING_WIREGUARD_SETTING_NAME:
+		connType = connectionWireGuard
 	}
 	if len(connType) == 0 {
 		connType = connectionUnknown
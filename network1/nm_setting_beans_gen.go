@@ -773,6 +773,27 @@ func generalGetSettingDefaultValue(setting, key string) (defvalue interface{}) {
 		case "network-name":
 			defvalue = ""
 		}
+	case "wireguard":
+		switch key {
+		default:
+			logger.Error("invalid key:", setting, key)
+		case "fwmark":
+			defvalue = uint32(0x0)
+		case "ip4-auto-default-route":
+			defvalue = int32(-1)
+		case "ip6-auto-default-route":
+			defvalue = int32(-1)
+		case "listen-port":
+			defvalue = uint32(0x0)
+		case "mtu":
+			defvalue = uint32(0x0)
+		case "peer-routes":
+			defvalue = true
+		case "private-key":
+			defvalue = ""
+		case "private-key-flags":
+			defvalue = uint32(0x0)
+		}
 	case "802-3-ethernet":
 		switch key {
 		default:
@@ -2098,6 +2119,30 @@ func isSettingWimaxMacAddressExists(data connectionData) bool {
 func isSettingWimaxNetworkNameExists(data connectionData) bool {
 	return isSettingKeyExists(data, "wimax", "network-name")
 }
+func isSettingWireGuardFwmarkExists(data connectionData) bool {
+	return isSettingKeyExists(data, "wireguard", "fwmark")
+}
+func isSettingWireGuardIp4AutoDefaultRouteExists(data connectionData) bool {
+	return isSettingKeyExists(data, "wireguard", "ip4-auto-default-route")
+}
+func isSettingWireGuardIp6AutoDefaultRouteExists(data connectionData) bool {
+	return isSettingKeyExists(data, "wireguard", "ip6-auto-default-route")
+}
+func isSettingWireGuardListenPortExists(data connectionData) bool {
+	return isSettingKeyExists(data, "wireguard", "listen-port")
+}
+func isSettingWireGuardMtuExists(data connectionData) bool {
+	return isSettingKeyExists(data, "wireguard", "mtu")
+}
+func isSettingWireGuardPeerRoutesExists(data connectionData) bool {
+	return isSettingKeyExists(data, "wireguard", "peer-routes")
+}
+func isSettingWireGuardPrivateKeyExists(data connectionData) bool {
+	return isSettingKeyExists(data, "wireguard", "private-key")
+}
+func isSettingWireGuardPrivateKeyFlagsExists(data connectionData) bool {
+	return isSettingKeyExists(data, "wireguard", "private-key-flags")
+}
 func isSettingWiredAutoNegotiateExists(data connectionData) bool {
 	return isSettingKeyExists(data, "802-3-ethernet", "auto-negotiate")
 }
@@ -4099,6 +4144,46 @@ func getSettingWimaxNetworkName(data connectionData) (value string) {
 	value = interfaceToString(ivalue)
 	return
 }
+func getSettingWireGuardFwmark(data connectionData) (value uint32) {
+	ivalue := getSettingKey(data, "wireguard", "fwmark")
+	value = interfaceToUint32(ivalue)
+	return
+}
+func getSettingWireGuardIp4AutoDefaultRoute(data connectionData) (value int32) {
+	ivalue := getSettingKey(data, "wireguard", "ip4-auto-default-route")
+	value = interfaceToInt32(ivalue)
+	return
+}
+func getSettingWireGuardIp6AutoDefaultRoute(data connectionData) (value int32) {
+	ivalue := getSettingKey(data, "wireguard", "ip6-auto-default-route")
+	value = interfaceToInt32(ivalue)
+	return
+}
+func getSettingWireGuardListenPort(data connectionData) (value uint32) {
+	ivalue := getSettingKey(data, "wireguard", "listen-port")
+	value = interfaceToUint32(ivalue)
+	return
+}
+func getSettingWireGuardMtu(data connectionData) (value uint32) {
+	ivalue := getSettingKey(data, "wireguard", "mtu")
+	value = interfaceToUint32(ivalue)
+	return
+}
+func getSettingWireGuardPeerRoutes(data connectionData) (value bool) {
+	ivalue := getSettingKey(data, "wireguard", "peer-routes")
+	value = interfaceToBoolean(ivalue)
+	return
+}
+func getSettingWireGuardPrivateKey(data connectionData) (value string) {
+	ivalue := getSettingKey(data, "wireguard", "private-key")
+	value = interfaceToString(ivalue)
+	return
+}
+func getSettingWireGuardPrivateKeyFlags(data connectionData) (value uint32) {
+	ivalue := getSettingKey(data, "wireguard", "private-key-flags")
+	value = interfaceToUint32(ivalue)
+	return
+}
 func getSettingWiredAutoNegotiate(data connectionData) (value bool) {
 	ivalue := getSettingKey(data, "802-3-ethernet", "auto-negotiate")
 	value = interfaceToBoolean(ivalue)
@@ -5870,6 +5955,30 @@ func setSettingWimaxMacAddress(data connectionData, value []byte) {
 func setSettingWimaxNetworkName(data connectionData, value string) {
 	setSettingKey(data, "wimax", "network-name", value)
 }
+func setSettingWireGuardFwmark(data connectionData, value uint32) {
+	setSettingKey(data, "wireguard", "fwmark", value)
+}
+func setSettingWireGuardIp4AutoDefaultRoute(data connectionData, value int32) {
+	setSettingKey(data, "wireguard", "ip4-auto-default-route", value)
+}
+func setSettingWireGuardIp6AutoDefaultRoute(data connectionData, value int32) {
+	setSettingKey(data, "wireguard", "ip6-auto-default-route", value)
+}
+func setSettingWireGuardListenPort(data connectionData, value uint32) {
+	setSettingKey(data, "wireguard", "listen-port", value)
+}
+func setSettingWireGuardMtu(data connectionData, value uint32) {
+	setSettingKey(data, "wireguard", "mtu", value)
+}
+func setSettingWireGuardPeerRoutes(data connectionData, value bool) {
+	setSettingKey(data, "wireguard", "peer-routes", value)
+}
+func setSettingWireGuardPrivateKey(data connectionData, value string) {
+	setSettingKey(data, "wireguard", "private-key", value)
+}
+func setSettingWireGuardPrivateKeyFlags(data connectionData, value uint32) {
+	setSettingKey(data, "wireguard", "private-key-flags", value)
+}
 func setSettingWiredAutoNegotiate(data connectionData, value bool) {
 	setSettingKey(data, "802-3-ethernet", "auto-negotiate", value)
 }
@@ -7285,6 +7394,30 @@ func removeSettingWimaxMacAddress(data connectionData) {
 func removeSettingWimaxNetworkName(data connectionData) {
 	removeSettingKey(data, "wimax", "network-name")
 }
+func removeSettingWireGuardFwmark(data connectionData) {
+	removeSettingKey(data, "wireguard", "fwmark")
+}
+func removeSettingWireGuardIp4AutoDefaultRoute(data connectionData) {
+	removeSettingKey(data, "wireguard", "ip4-auto-default-route")
+}
+func removeSettingWireGuardIp6AutoDefaultRoute(data connectionData) {
+	removeSettingKey(data, "wireguard", "ip6-auto-default-route")
+}
+func removeSettingWireGuardListenPort(data connectionData) {
+	removeSettingKey(data, "wireguard", "listen-port")
+}
+func removeSettingWireGuardMtu(data connectionData) {
+	removeSettingKey(data, "wireguard", "mtu")
+}
+func removeSettingWireGuardPeerRoutes(data connectionData) {
+	removeSettingKey(data, "wireguard", "peer-routes")
+}
+func removeSettingWireGuardPrivateKey(data connectionData) {
+	removeSettingKey(data, "wireguard", "private-key")
+}
+func removeSettingWireGuardPrivateKeyFlags(data connectionData) {
+	removeSettingKey(data, "wireguard", "private-key-flags")
+}
 func removeSettingWiredAutoNegotiate(data connectionData) {
 	removeSettingKey(data, "802-3-ethernet", "auto-negotiate")
 }
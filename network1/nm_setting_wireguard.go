@@ -0,0 +1,412 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package network
+
+import (
+	"bufio"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"regexp"
+	"strconv"
+	"strings"
+
+	dbus "github.com/godbus/dbus/v5"
+	"github.com/linuxdeepin/dde-daemon/network1/nm"
+)
+
+const wireGuardKeyLen = 32
+
+// 和 wg-quick 对网卡名的要求一致
+var wireGuardIfcNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_=+.-]{1,15}$`)
+
+type wireGuardPeer struct {
+	PublicKey           string
+	Endpoint            string   `json:",omitempty"`
+	AllowedIPs          []string `json:",omitempty"`
+	PersistentKeepalive uint32   `json:",omitempty"`
+	PresharedKey        string   `json:",omitempty"`
+	PresharedKeyFlags   uint32
+}
+
+// wgQuickConfig wg-quick 配置文件中 NetworkManager 支持的部分
+type wgQuickConfig struct {
+	PrivateKey string
+	ListenPort uint32
+	FwMark     uint32
+	Mtu        uint32
+	Addresses  []netip.Prefix
+	Dns        []netip.Addr
+	DnsSearch  []string
+	Peers      []wireGuardPeer
+}
+
+func checkWireGuardKey(key string) error {
+	data, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(data) != wireGuardKeyLen {
+		return fmt.Errorf("invalid wireguard key %q", key)
+	}
+	return nil
+}
+
+// getWireGuardPublicKey 根据私钥计算公钥
+func getWireGuardPublicKey(privateKey string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(privateKey)
+	if err != nil || len(data) != wireGuardKeyLen {
+		return "", fmt.Errorf("invalid wireguard private key")
+	}
+	key, err := ecdh.X25519().NewPrivateKey(data)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key.PublicKey().Bytes()), nil
+}
+
+// generateWireGuardKeyPair 生成密钥对，和 wg genkey 一样对私钥做 clamp 处理
+func generateWireGuardKeyPair() (privateKey, publicKey string, err error) {
+	data := make([]byte, wireGuardKeyLen)
+	_, err = rand.Read(data)
+	if err != nil {
+		return
+	}
+	data[0] &= 248
+	data[31] = (data[31] & 127) | 64
+	privateKey = base64.StdEncoding.EncodeToString(data)
+	publicKey, err = getWireGuardPublicKey(privateKey)
+	return
+}
+
+func checkWireGuardPeers(peers []wireGuardPeer) error {
+	publicKeys := make(map[string]bool, len(peers))
+	for _, peer := range peers {
+		err := checkWireGuardKey(peer.PublicKey)
+		if err != nil {
+			return err
+		}
+		if publicKeys[peer.PublicKey] {
+			return fmt.Errorf("duplicate peer %s", peer.PublicKey)
+		}
+		publicKeys[peer.PublicKey] = true
+		if peer.PresharedKey != "" {
+			err = checkWireGuardKey(peer.PresharedKey)
+			if err != nil {
+				return err
+			}
+		}
+		if peer.Endpoint != "" {
+			_, _, err = net.SplitHostPort(peer.Endpoint)
+			if err != nil {
+				return fmt.Errorf("invalid endpoint %q: %v", peer.Endpoint, err)
+			}
+		}
+		for _, allowedIP := range peer.AllowedIPs {
+			_, err = parseWireGuardPrefix(allowedIP)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseWireGuardPrefix 解析 CIDR 格式的地址，没有前缀长度时表示单个地址
+func parseWireGuardPrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		return netip.ParsePrefix(s)
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+func splitWgQuickList(value string) (list []string) {
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			list = append(list, item)
+		}
+	}
+	return
+}
+
+// parseWgQuickUint32 解析数字，off 表示 0
+func parseWgQuickUint32(value string) (uint32, error) {
+	if value == "off" {
+		return 0, nil
+	}
+	v, err := strconv.ParseUint(value, 0, 32)
+	return uint32(v), err
+}
+
+// parseWgQuickConfig 解析 wg-quick 的配置文件，忽略 NetworkManager 不支持的 PostUp 等脚本
+func parseWgQuickConfig(r io.Reader) (*wgQuickConfig, error) {
+	cfg := &wgQuickConfig{}
+	var section string
+	var peer *wireGuardPeer
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx != -1 {
+			line = line[:idx]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(line[1 : len(line)-1])
+			switch section {
+			case "interface":
+			case "peer":
+				cfg.Peers = append(cfg.Peers, wireGuardPeer{})
+				peer = &cfg.Peers[len(cfg.Peers)-1]
+			default:
+				return nil, fmt.Errorf("line %d: unknown section %q", lineNum, line)
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: invalid line %q", lineNum, line)
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		var err error
+		switch section + "." + key {
+		case "interface.privatekey":
+			cfg.PrivateKey = value
+		case "interface.listenport":
+			cfg.ListenPort, err = parseWgQuickUint32(value)
+		case "interface.fwmark":
+			cfg.FwMark, err = parseWgQuickUint32(value)
+		case "interface.mtu":
+			cfg.Mtu, err = parseWgQuickUint32(value)
+		case "interface.address":
+			for _, item := range splitWgQuickList(value) {
+				var prefix netip.Prefix
+				prefix, err = parseWireGuardPrefix(item)
+				if err != nil {
+					break
+				}
+				cfg.Addresses = append(cfg.Addresses, prefix)
+			}
+		case "interface.dns":
+			for _, item := range splitWgQuickList(value) {
+				addr, err := netip.ParseAddr(item)
+				if err != nil {
+					// 不是地址的作为搜索域
+					cfg.DnsSearch = append(cfg.DnsSearch, item)
+					continue
+				}
+				cfg.Dns = append(cfg.Dns, addr)
+			}
+		case "interface.table", "interface.preup", "interface.postup",
+			"interface.predown", "interface.postdown", "interface.saveconfig":
+			logger.Warningf("ignore unsupported wg-quick key %q", key)
+		case "peer.publickey":
+			peer.PublicKey = value
+		case "peer.presharedkey":
+			peer.PresharedKey = value
+		case "peer.allowedips":
+			peer.AllowedIPs = append(peer.AllowedIPs, splitWgQuickList(value)...)
+		case "peer.endpoint":
+			peer.Endpoint = value
+		case "peer.persistentkeepalive":
+			peer.PersistentKeepalive, err = parseWgQuickUint32(value)
+		default:
+			return nil, fmt.Errorf("line %d: unknown key %q", lineNum, key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid value of %s: %v", lineNum, key, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	err := checkWireGuardKey(cfg.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	err = checkWireGuardPeers(cfg.Peers)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Logic getter and setter for peers, the type of which is aa{sv}
+func getSettingWireGuardPeers(data connectionData) (peers []wireGuardPeer) {
+	if !isSettingKeyExists(data, nm.NM_SETTING_WIREGUARD_SETTING_NAME, nm.NM_SETTING_WIREGUARD_PEERS) {
+		return
+	}
+	value := doGetSettingKey(data, nm.NM_SETTING_WIREGUARD_SETTING_NAME, nm.NM_SETTING_WIREGUARD_PEERS)
+	list, ok := value.([]map[string]dbus.Variant)
+	if !ok {
+		logger.Errorf("getSettingWireGuardPeers() failed: %#v", value)
+		return
+	}
+	for _, item := range list {
+		var peer wireGuardPeer
+		peer.PublicKey, _ = item[nm.NM_WIREGUARD_PEER_ATTR_PUBLIC_KEY].Value().(string)
+		peer.Endpoint, _ = item[nm.NM_WIREGUARD_PEER_ATTR_ENDPOINT].Value().(string)
+		peer.AllowedIPs, _ = item[nm.NM_WIREGUARD_PEER_ATTR_ALLOWED_IPS].Value().([]string)
+		peer.PersistentKeepalive, _ = item[nm.NM_WIREGUARD_PEER_ATTR_PERSISTENT_KEEPALIVE].Value().(uint32)
+		peer.PresharedKey, _ = item[nm.NM_WIREGUARD_PEER_ATTR_PRESHARED_KEY].Value().(string)
+		peer.PresharedKeyFlags, _ = item[nm.NM_WIREGUARD_PEER_ATTR_PRESHARED_KEY_FLAGS].Value().(uint32)
+		peers = append(peers, peer)
+	}
+	return
+}
+
+func setSettingWireGuardPeers(data connectionData, peers []wireGuardPeer) {
+	if len(peers) == 0 {
+		removeSettingKey(data, nm.NM_SETTING_WIREGUARD_SETTING_NAME, nm.NM_SETTING_WIREGUARD_PEERS)
+		return
+	}
+	list := make([]map[string]dbus.Variant, 0, len(peers))
+	for _, peer := range peers {
+		item := map[string]dbus.Variant{
+			nm.NM_WIREGUARD_PEER_ATTR_PUBLIC_KEY:          dbus.MakeVariant(peer.PublicKey),
+			nm.NM_WIREGUARD_PEER_ATTR_PRESHARED_KEY_FLAGS: dbus.MakeVariant(peer.PresharedKeyFlags),
+		}
+		if peer.Endpoint != "" {
+			item[nm.NM_WIREGUARD_PEER_ATTR_ENDPOINT] = dbus.MakeVariant(peer.Endpoint)
+		}
+		if len(peer.AllowedIPs) > 0 {
+			item[nm.NM_WIREGUARD_PEER_ATTR_ALLOWED_IPS] = dbus.MakeVariant(peer.AllowedIPs)
+		}
+		if peer.PersistentKeepalive != 0 {
+			item[nm.NM_WIREGUARD_PEER_ATTR_PERSISTENT_KEEPALIVE] = dbus.MakeVariant(peer.PersistentKeepalive)
+		}
+		if peer.PresharedKey != "" {
+			item[nm.NM_WIREGUARD_PEER_ATTR_PRESHARED_KEY] = dbus.MakeVariant(peer.PresharedKey)
+		}
+		list = append(list, item)
+	}
+	setSettingKey(data, nm.NM_SETTING_WIREGUARD_SETTING_NAME, nm.NM_SETTING_WIREGUARD_PEERS, list)
+}
+
+// getWireGuardPeerSecretKey 返回对端预共享密钥在 keyring 中的键名，和 NetworkManager 请求密钥时的 hint 一致
+func getWireGuardPeerSecretKey(publicKey string) string {
+	return nm.NM_SETTING_WIREGUARD_PEERS + "." + publicKey + "." + nm.NM_WIREGUARD_PEER_ATTR_PRESHARED_KEY
+}
+
+// getWireGuardPeerSecrets 返回 keyring 中保存的对端预共享密钥，格式和 peers 属性一致
+func getWireGuardPeerSecrets(data connectionData, saved map[string]string) (peers []map[string]dbus.Variant) {
+	for _, peer := range getSettingWireGuardPeers(data) {
+		if peer.PresharedKeyFlags != nm.NM_SETTING_SECRET_FLAG_AGENT_OWNED {
+			continue
+		}
+		psk := saved[getWireGuardPeerSecretKey(peer.PublicKey)]
+		if psk == "" {
+			continue
+		}
+		peers = append(peers, map[string]dbus.Variant{
+			nm.NM_WIREGUARD_PEER_ATTR_PUBLIC_KEY:    dbus.MakeVariant(peer.PublicKey),
+			nm.NM_WIREGUARD_PEER_ATTR_PRESHARED_KEY: dbus.MakeVariant(psk),
+		})
+	}
+	return
+}
+
+// new connection data
+func newWireGuardConnectionData(id, uuid, ifc string) (data connectionData) {
+	data = make(connectionData)
+
+	addSetting(data, nm.NM_SETTING_CONNECTION_SETTING_NAME)
+	setSettingConnectionId(data, id)
+	setSettingConnectionUuid(data, uuid)
+	setSettingConnectionType(data, nm.NM_SETTING_WIREGUARD_SETTING_NAME)
+	setSettingConnectionInterfaceName(data, ifc)
+	setSettingConnectionAutoconnect(data, false)
+
+	addSetting(data, nm.NM_SETTING_WIREGUARD_SETTING_NAME)
+	// 私钥由 SecretAgent 保存到 keyring
+	setSettingWireGuardPrivateKeyFlags(data, nm.NM_SETTING_SECRET_FLAG_AGENT_OWNED)
+
+	// 隧道地址需要手动配置
+	addSetting(data, nm.NM_SETTING_IP4_CONFIG_SETTING_NAME)
+	setSettingIP4ConfigMethod(data, nm.NM_SETTING_IP4_CONFIG_METHOD_DISABLED)
+	addSetting(data, nm.NM_SETTING_IP6_CONFIG_SETTING_NAME)
+	setSettingIP6ConfigMethod(data, nm.NM_SETTING_IP6_CONFIG_METHOD_IGNORE)
+	return
+}
+
+func newWireGuardConnectionDataFromConfig(id, uuid, ifc string, cfg *wgQuickConfig) (data connectionData) {
+	data = newWireGuardConnectionData(id, uuid, ifc)
+	setSettingWireGuardPrivateKey(data, cfg.PrivateKey)
+	if cfg.ListenPort != 0 {
+		setSettingWireGuardListenPort(data, cfg.ListenPort)
+	}
+	if cfg.FwMark != 0 {
+		setSettingWireGuardFwmark(data, cfg.FwMark)
+	}
+	if cfg.Mtu != 0 {
+		setSettingWireGuardMtu(data, cfg.Mtu)
+	}
+	peers := make([]wireGuardPeer, len(cfg.Peers))
+	copy(peers, cfg.Peers)
+	for i := range peers {
+		if peers[i].PresharedKey != "" {
+			peers[i].PresharedKeyFlags = nm.NM_SETTING_SECRET_FLAG_AGENT_OWNED
+		}
+	}
+	setSettingWireGuardPeers(data, peers)
+
+	var ip4Addresses [][]uint32
+	var ip6Addresses ipv6Addresses
+	for _, prefix := range cfg.Addresses {
+		addr := prefix.Addr()
+		if addr.Is4() {
+			ip4Addresses = append(ip4Addresses, []uint32{htonl(ipToUint32(addr.String())), uint32(prefix.Bits()), 0})
+		} else {
+			ip6Addresses = append(ip6Addresses, ipv6Address{
+				Address: addr.AsSlice(),
+				Prefix:  uint32(prefix.Bits()),
+				Gateway: make([]byte, net.IPv6len),
+			})
+		}
+	}
+	var ip4Dns []uint32
+	var ip6Dns [][]byte
+	for _, addr := range cfg.Dns {
+		if addr.Is4() {
+			ip4Dns = append(ip4Dns, htonl(ipToUint32(addr.String())))
+		} else {
+			ip6Dns = append(ip6Dns, addr.AsSlice())
+		}
+	}
+
+	// 没有地址时 disabled 和 ignore 方式不允许设置 DNS
+	if len(ip4Addresses) > 0 {
+		setSettingIP4ConfigMethod(data, nm.NM_SETTING_IP4_CONFIG_METHOD_MANUAL)
+		setSettingIP4ConfigAddresses(data, ip4Addresses)
+		if len(ip4Dns) > 0 {
+			setSettingIP4ConfigDns(data, ip4Dns)
+		}
+		if len(cfg.DnsSearch) > 0 {
+			setSettingIP4ConfigDnsSearch(data, cfg.DnsSearch)
+		}
+	}
+	if len(ip6Addresses) > 0 {
+		setSettingIP6ConfigMethod(data, nm.NM_SETTING_IP6_CONFIG_METHOD_MANUAL)
+		setSettingIP6ConfigAddresses(data, ip6Addresses)
+		if len(ip6Dns) > 0 {
+			setSettingIP6ConfigDns(data, ip6Dns)
+		}
+		if len(cfg.DnsSearch) > 0 && len(ip4Addresses) == 0 {
+			setSettingIP6ConfigDnsSearch(data, cfg.DnsSearch)
+		}
+	}
+	return
+}
@@ -0,0 +1,173 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package network
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/linuxdeepin/dde-daemon/network1/nm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RFC 7748 中的 X25519 测试向量
+const (
+	testWireGuardPrivateKey = "dwdtCnMYpX08FsFyUbJmRd9ML4frwJkqsXf7pR25LCo="
+	testWireGuardPublicKey  = "hSDwCYkwp1R0i33ctD73Wg2/Og0mOBr066SpjqqbTmo="
+	testWireGuardPeerKey    = "3p7bfXt9wbTTW2HC7OQ1Nz+DQ8hbeGdNrfx+FG+IK08="
+)
+
+const testWgQuickConfig = `
+[Interface]
+# home tunnel
+PrivateKey = dwdtCnMYpX08FsFyUbJmRd9ML4frwJkqsXf7pR25LCo=
+Address = 10.8.0.2/24, fd00::2/64
+DNS = 10.8.0.1, fd00::1, home.lan
+ListenPort = 51820
+MTU = 1420
+PostUp = iptables -A FORWARD -i %i -j ACCEPT
+
+[Peer]
+PublicKey = 3p7bfXt9wbTTW2HC7OQ1Nz+DQ8hbeGdNrfx+FG+IK08=
+PresharedKey = hSDwCYkwp1R0i33ctD73Wg2/Og0mOBr066SpjqqbTmo=
+AllowedIPs = 0.0.0.0/0, ::/0
+Endpoint = vpn.example.com:51820
+PersistentKeepalive = 25
+`
+
+func TestWireGuardKeys(t *testing.T) {
+	publicKey, err := getWireGuardPublicKey(testWireGuardPrivateKey)
+	require.NoError(t, err)
+	assert.Equal(t, testWireGuardPublicKey, publicKey)
+
+	privateKey, publicKey, err := generateWireGuardKeyPair()
+	require.NoError(t, err)
+	assert.NoError(t, checkWireGuardKey(privateKey))
+	assert.NoError(t, checkWireGuardKey(publicKey))
+	derived, err := getWireGuardPublicKey(privateKey)
+	require.NoError(t, err)
+	assert.Equal(t, publicKey, derived)
+
+	assert.Error(t, checkWireGuardKey(""))
+	assert.Error(t, checkWireGuardKey("not-base64"))
+	assert.Error(t, checkWireGuardKey("AAAA"))
+}
+
+func TestParseWgQuickConfig(t *testing.T) {
+	cfg, err := parseWgQuickConfig(strings.NewReader(testWgQuickConfig))
+	require.NoError(t, err)
+	assert.Equal(t, testWireGuardPrivateKey, cfg.PrivateKey)
+	assert.Equal(t, uint32(51820), cfg.ListenPort)
+	assert.Equal(t, uint32(1420), cfg.Mtu)
+	require.Len(t, cfg.Addresses, 2)
+	assert.Equal(t, "10.8.0.2/24", cfg.Addresses[0].String())
+	assert.Equal(t, "fd00::2/64", cfg.Addresses[1].String())
+	require.Len(t, cfg.Dns, 2)
+	assert.Equal(t, []string{"home.lan"}, cfg.DnsSearch)
+
+	require.Len(t, cfg.Peers, 1)
+	peer := cfg.Peers[0]
+	assert.Equal(t, testWireGuardPeerKey, peer.PublicKey)
+	assert.Equal(t, testWireGuardPublicKey, peer.PresharedKey)
+	assert.Equal(t, []string{"0.0.0.0/0", "::/0"}, peer.AllowedIPs)
+	assert.Equal(t, "vpn.example.com:51820", peer.Endpoint)
+	assert.Equal(t, uint32(25), peer.PersistentKeepalive)
+}
+
+func TestParseWgQuickConfigInvalid(t *testing.T) {
+	for _, content := range []string{
+		"[Interface]\nPrivateKey = AAAA\n",
+		"[Interface]\nPrivateKey = " + testWireGuardPrivateKey + "\nFoo = bar\n",
+		"[Interface]\nPrivateKey = " + testWireGuardPrivateKey + "\n[Unknown]\n",
+		"[Interface]\nPrivateKey = " + testWireGuardPrivateKey + "\nListenPort = abc\n",
+		"[Interface]\nPrivateKey = " + testWireGuardPrivateKey + "\n[Peer]\nPublicKey = bad\n",
+		"[Interface]\nPrivateKey = " + testWireGuardPrivateKey +
+			"\n[Peer]\nPublicKey = " + testWireGuardPeerKey + "\nEndpoint = example.com\n",
+	} {
+		_, err := parseWgQuickConfig(strings.NewReader(content))
+		assert.Error(t, err, content)
+	}
+}
+
+func TestNewWireGuardConnectionDataFromConfig(t *testing.T) {
+	cfg, err := parseWgQuickConfig(strings.NewReader(testWgQuickConfig))
+	require.NoError(t, err)
+	data := newWireGuardConnectionDataFromConfig("wg0", "wg0-uuid", "wg0", cfg)
+
+	assert.Equal(t, connectionWireGuard, getCustomConnectionType(data))
+	assert.Equal(t, "wg0", getSettingConnectionInterfaceName(data))
+	assert.False(t, getSettingConnectionAutoconnect(data))
+	assert.Equal(t, testWireGuardPrivateKey, getSettingWireGuardPrivateKey(data))
+	assert.Equal(t, uint32(nm.NM_SETTING_SECRET_FLAG_AGENT_OWNED), getSettingWireGuardPrivateKeyFlags(data))
+	assert.Equal(t, uint32(51820), getSettingWireGuardListenPort(data))
+	assert.Equal(t, uint32(1420), getSettingWireGuardMtu(data))
+
+	assert.Equal(t, nm.NM_SETTING_IP4_CONFIG_METHOD_MANUAL, getSettingIP4ConfigMethod(data))
+	assert.Equal(t, [][]uint32{{htonl(ipToUint32("10.8.0.2")), 24, 0}}, getSettingIP4ConfigAddresses(data))
+	assert.Equal(t, []uint32{htonl(ipToUint32("10.8.0.1"))}, getSettingIP4ConfigDns(data))
+	assert.Equal(t, []string{"home.lan"}, getSettingIP4ConfigDnsSearch(data))
+	assert.Equal(t, nm.NM_SETTING_IP6_CONFIG_METHOD_MANUAL, getSettingIP6ConfigMethod(data))
+	addresses := getSettingIP6ConfigAddresses(data)
+	require.Len(t, addresses, 1)
+	assert.Equal(t, uint32(64), addresses[0].Prefix)
+	assert.Len(t, getSettingIP6ConfigDns(data), 1)
+
+	peers := getSettingWireGuardPeers(data)
+	require.Len(t, peers, 1)
+	assert.Equal(t, uint32(nm.NM_SETTING_SECRET_FLAG_AGENT_OWNED), peers[0].PresharedKeyFlags)
+	// 解析结果不应被修改
+	assert.Zero(t, cfg.Peers[0].PresharedKeyFlags)
+}
+
+func TestNewWireGuardConnectionDataWithoutAddress(t *testing.T) {
+	cfg := &wgQuickConfig{PrivateKey: testWireGuardPrivateKey}
+	data := newWireGuardConnectionDataFromConfig("wg1", "wg1-uuid", "wg1", cfg)
+	assert.Equal(t, nm.NM_SETTING_IP4_CONFIG_METHOD_DISABLED, getSettingIP4ConfigMethod(data))
+	assert.Equal(t, nm.NM_SETTING_IP6_CONFIG_METHOD_IGNORE, getSettingIP6ConfigMethod(data))
+	assert.False(t, isSettingKeyExists(data, nm.NM_SETTING_WIREGUARD_SETTING_NAME, nm.NM_SETTING_WIREGUARD_PEERS))
+}
+
+func TestWireGuardPeers(t *testing.T) {
+	data := newWireGuardConnectionData("wg0", "wg0-uuid", "wg0")
+	peers := []wireGuardPeer{
+		{
+			PublicKey:           testWireGuardPeerKey,
+			Endpoint:            "[fd00::1]:51820",
+			AllowedIPs:          []string{"10.8.0.0/24"},
+			PersistentKeepalive: 25,
+		},
+		{
+			PublicKey:         testWireGuardPublicKey,
+			PresharedKey:      testWireGuardPeerKey,
+			PresharedKeyFlags: nm.NM_SETTING_SECRET_FLAG_AGENT_OWNED,
+		},
+	}
+	require.NoError(t, checkWireGuardPeers(peers))
+	setSettingWireGuardPeers(data, peers)
+	assert.Equal(t, peers, getSettingWireGuardPeers(data))
+
+	saved := map[string]string{
+		getWireGuardPeerSecretKey(testWireGuardPublicKey): testWireGuardPeerKey,
+	}
+	secrets := getWireGuardPeerSecrets(data, saved)
+	require.Len(t, secrets, 1)
+	assert.Equal(t, testWireGuardPublicKey, secrets[0][nm.NM_WIREGUARD_PEER_ATTR_PUBLIC_KEY].Value())
+	assert.Equal(t, "peers."+testWireGuardPublicKey+".preshared-key", getWireGuardPeerSecretKey(testWireGuardPublicKey))
+
+	assert.Error(t, checkWireGuardPeers(append(peers, peers[0])))
+	assert.Error(t, checkWireGuardPeers([]wireGuardPeer{{PublicKey: testWireGuardPeerKey, AllowedIPs: []string{"10.8.0.0/33"}}}))
+
+	setSettingWireGuardPeers(data, nil)
+	assert.Empty(t, getSettingWireGuardPeers(data))
+}
+
+func TestWireGuardIfcName(t *testing.T) {
+	assert.True(t, wireGuardIfcNameRegexp.MatchString("wg0"))
+	assert.True(t, wireGuardIfcNameRegexp.MatchString("home-vpn.1"))
+	assert.False(t, wireGuardIfcNameRegexp.MatchString("a-very-long-interface"))
+	assert.False(t, wireGuardIfcNameRegexp.MatchString("wg 0"))
+	assert.False(t, wireGuardIfcNameRegexp.MatchString(""))
+}
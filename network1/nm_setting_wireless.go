@@ -5,6 +5,7 @@
 package network
 
 import (
+	"fmt"
 	"os"
 
 	dbus "github.com/godbus/dbus/v5"
@@ -87,3 +88,32 @@ func logicSetSettingWirelessMode(data connectionData, value string) (err error)
 	setSettingWirelessMode(data, value)
 	return
 }
+
+// logicSetSettingWirelessBand 限定连接使用的频段，为空时不限定。
+// 信道必须和频段一致，所以同时清除信道
+func logicSetSettingWirelessBand(data connectionData, value string) (err error) {
+	switch value {
+	case "":
+		removeSettingWirelessBand(data)
+	case "a", "bg":
+		setSettingWirelessBand(data, value)
+	default:
+		return fmt.Errorf("invalid wireless band %q", value)
+	}
+	removeSettingWirelessChannel(data)
+	return
+}
+
+// logicSetSettingWirelessBssid 限定连接只使用 BSSID 对应的接入点，为空时不限定
+func logicSetSettingWirelessBssid(data connectionData, value string) (err error) {
+	if value == "" {
+		removeSettingWirelessBssid(data)
+		return
+	}
+	bssid, err := convertMacAddressToArrayByteCheck(value)
+	if err != nil {
+		return
+	}
+	setSettingWirelessBssid(data, bssid)
+	return
+}
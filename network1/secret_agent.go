@@ -633,6 +633,10 @@ func isMustAsk(data connectionData, settingName, secretKey string) bool {
 			}
 		}
 
+	case nm.NM_SETTING_WIREGUARD_SETTING_NAME:
+		if secretKey == nm.NM_SETTING_WIREGUARD_PRIVATE_KEY {
+			return true
+		}
 	}
 
 	return false
@@ -847,6 +851,12 @@ func (sa *SecretAgent) getSecrets(connectionData map[string]map[string]dbus.Vari
 				setting[key] = dbus.MakeVariant(value)
 			}
 		}
+		if settingName == nm.NM_SETTING_WIREGUARD_SETTING_NAME {
+			peers := getWireGuardPeerSecrets(connectionData, resultSaved)
+			if len(peers) > 0 {
+				setting[nm.NM_SETTING_WIREGUARD_PEERS] = dbus.MakeVariant(peers)
+			}
+		}
 	}
 	return
 }
@@ -1071,9 +1081,10 @@ var secretSettingKeys = map[string][]string{
 		"client-cert-password", "phase2-ca-cert-password", "phase2-client-cert-password",
 		"private-key-password", "phase2-private-key-password", "pin"},
 	// temporarily not supported password-raw
-	"pppoe": {"password"},
-	"gsm":   {"password", "pin"},
-	"cdma":  {"password"},
+	"pppoe":     {"password"},
+	"gsm":       {"password", "pin"},
+	"cdma":      {"password"},
+	"wireguard": {"private-key"},
 }
 
 var vpnSecretKeys = []string{
@@ -1171,6 +1182,18 @@ func (sa *SecretAgent) saveSecrets(connectionData map[string]map[string]dbus.Var
 				}
 			}
 		}
+
+		if settingName == nm.NM_SETTING_WIREGUARD_SETTING_NAME {
+			for _, peer := range getSettingWireGuardPeers(connectionData) {
+				if peer.PresharedKeyFlags == secretFlagAgentOwned && peer.PresharedKey != "" {
+					arr = append(arr, settingItem{
+						settingName: settingName,
+						settingKey:  getWireGuardPeerSecretKey(peer.PublicKey),
+						value:       peer.PresharedKey,
+					})
+				}
+			}
+		}
 	}
 
 	for _, item := range arr {
@@ -1204,6 +1227,17 @@ func (sa *SecretAgent) saveSecrets(connectionData map[string]map[string]dbus.Var
 		}
 	}
 
+	for _, peer := range getSettingWireGuardPeers(connectionData) {
+		if peer.PresharedKeyFlags != secretFlagAgentOwned {
+			err := sa.delete(connUUID, nm.NM_SETTING_WIREGUARD_SETTING_NAME,
+				getWireGuardPeerSecretKey(peer.PublicKey))
+			if err != nil {
+				logger.Debug("failed to delete secret")
+				return err
+			}
+		}
+	}
+
 	vpnData, ok := getConnectionData(connectionData, "vpn", "data")
 	if ok {
 		vpnDataMap, ok := vpnData.(map[string]string)
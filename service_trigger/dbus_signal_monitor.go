@@ -5,6 +5,7 @@
 package service_trigger
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/godbus/dbus/v5"
@@ -68,18 +69,40 @@ func (sigMonitor *DBusSignalMonitor) findMatchedServices(signal *dbus.Signal) []
 		if dbusField.Sender == sender &&
 			signal.Name == dbusField.Interface+"."+dbusField.Signal {
 
-			if dbusField.Path != "" {
-				if dbusField.Path == string(signal.Path) {
-					matched = append(matched, service)
-				}
-			} else {
-				matched = append(matched, service)
+			if dbusField.Path != "" && dbusField.Path != string(signal.Path) {
+				continue
 			}
+			if !matchSignalArgs(dbusField.Args, signal.Body) {
+				continue
+			}
+			matched = append(matched, service)
 		}
 	}
 	return matched
 }
 
+func matchSignalArgs(args map[int]string, body []interface{}) bool {
+	for idx, value := range args {
+		if idx >= len(body) || fmt.Sprintf("%v", body[idx]) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// getSignalVars 返回 Exec 中可以使用的变量：arg0, arg1 ..., sender, path, signal
+func getSignalVars(signal *dbus.Signal) map[string]string {
+	vars := map[string]string{
+		"sender": signal.Sender,
+		"path":   string(signal.Path),
+		"signal": signal.Name,
+	}
+	for idx, item := range signal.Body {
+		vars[fmt.Sprintf("arg%d", idx)] = fmt.Sprintf("%v", item)
+	}
+	return vars
+}
+
 const ruleNameOwnerChanged = "type='signal'" +
 	",sender='org.freedesktop.DBus',path='/org/freedesktop/DBus'" +
 	",interface='org.freedesktop.DBus',member='NameOwnerChanged'"
@@ -193,7 +216,7 @@ func (sigMonitor *DBusSignalMonitor) signalLoop(m *Manager) {
 		services := sigMonitor.findMatchedServices(signal)
 		for _, service := range services {
 			logger.Debug("exec service", service)
-			go m.execService(service, signal)
+			go m.execService(service, getSignalVars(signal))
 		}
 	}
 	logger.Debug("signalLoop return", sigMonitor.Type)
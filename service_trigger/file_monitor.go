@@ -0,0 +1,266 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package service_trigger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// fileMonitor 使用 inotify 监视文件变化，文件可能还不存在，所以监视的是文件所在的目录，
+// 目录也不存在时监视最近的已存在的上级目录，目录创建后再监视目录本身。
+type fileMonitor struct {
+	services []*Service
+	watcher  *fsnotify.Watcher
+	exec     func(service *Service, vars map[string]string)
+
+	mu      sync.Mutex
+	timers  map[*Service]*time.Timer
+	stopped bool
+	// 需要监视的目录 -> 实际监视的目录
+	dirs map[string]string
+}
+
+func newFileMonitor() *fileMonitor {
+	return &fileMonitor{
+		timers: make(map[*Service]*time.Timer),
+		dirs:   make(map[string]string),
+	}
+}
+
+func (fm *fileMonitor) appendService(service *Service) {
+	fm.services = append(fm.services, service)
+}
+
+// getWatchDir 返回 p 需要监视的目录，p 为目录时监视 p 本身
+func getWatchDir(p string) string {
+	info, err := os.Stat(p)
+	if err == nil && info.IsDir() {
+		return filepath.Clean(p)
+	}
+	return filepath.Dir(p)
+}
+
+// getNearestExistingDir 返回 dir 或者 dir 最近的已存在的上级目录
+func getNearestExistingDir(dir string) string {
+	for {
+		info, err := os.Stat(dir)
+		if err == nil && info.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		dir = parent
+	}
+}
+
+func (fm *fileMonitor) start(m *Manager) error {
+	if len(fm.services) == 0 {
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	fm.watcher = watcher
+	fm.exec = m.execService
+
+	fm.mu.Lock()
+	for _, service := range fm.services {
+		for _, p := range service.Monitor.File.Paths {
+			fm.dirs[getWatchDir(p)] = ""
+		}
+	}
+	fm.updateWatchesLocked()
+	fm.mu.Unlock()
+
+	go fm.loop()
+	return nil
+}
+
+// updateWatchesLocked 监视还没有被直接监视的目录，目录不存在时监视最近的已存在的上级目录
+func (fm *fileMonitor) updateWatchesLocked() {
+	for dir, watched := range fm.dirs {
+		if watched == dir {
+			continue
+		}
+		target := getNearestExistingDir(dir)
+		if target == watched {
+			continue
+		}
+		err := fm.watcher.Add(target)
+		if err != nil {
+			logger.Warningf("failed to watch %q: %v", target, err)
+			continue
+		}
+		if target != dir {
+			logger.Debugf("%q does not exist, watch %q instead", dir, target)
+		}
+		fm.dirs[dir] = target
+		fm.removeUnusedWatchLocked(watched)
+	}
+}
+
+// removeUnusedWatchLocked 移除不再需要的上级目录的监视
+func (fm *fileMonitor) removeUnusedWatchLocked(watched string) {
+	if watched == "" {
+		return
+	}
+	for _, v := range fm.dirs {
+		if v == watched {
+			return
+		}
+	}
+	err := fm.watcher.Remove(watched)
+	if err != nil {
+		logger.Debugf("failed to remove watch %q: %v", watched, err)
+	}
+}
+
+// handleDirChanged 在目录被创建或者删除后更新监视的目录
+func (fm *fileMonitor) handleDirChanged(ev fsnotify.Event) {
+	if ev.Op&(fsnotify.Create|fsnotify.Remove|fsnotify.Rename) == 0 {
+		return
+	}
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+	if fm.stopped {
+		return
+	}
+	changed := false
+	for dir, watched := range fm.dirs {
+		if ev.Op&fsnotify.Create != 0 && watched != dir &&
+			(dir == ev.Name || strings.HasPrefix(dir, ev.Name+"/")) {
+			changed = true
+		}
+		// 被删除的目录的监视已被自动移除
+		if ev.Op&(fsnotify.Remove|fsnotify.Rename) != 0 && watched == ev.Name {
+			fm.dirs[dir] = ""
+			changed = true
+		}
+	}
+	if changed {
+		fm.updateWatchesLocked()
+	}
+}
+
+func (fm *fileMonitor) loop() {
+	for {
+		select {
+		case ev, ok := <-fm.watcher.Events:
+			if !ok {
+				return
+			}
+			fm.handleDirChanged(ev)
+			fm.handleEvent(ev)
+		case err, ok := <-fm.watcher.Errors:
+			if !ok {
+				return
+			}
+			logger.Warning(err)
+		}
+	}
+}
+
+// matchFilePath 判断事件的文件 name 是否匹配 pattern，pattern 为目录时匹配目录下的所有文件
+func matchFilePath(pattern, name string) bool {
+	pattern = filepath.Clean(pattern)
+	if pattern == name || filepath.Dir(name) == pattern {
+		return true
+	}
+	if filepath.Dir(name) != filepath.Dir(pattern) {
+		return false
+	}
+	matched, _ := filepath.Match(filepath.Base(pattern), filepath.Base(name))
+	return matched
+}
+
+func (fm *fileMonitor) findMatchedServices(ev fsnotify.Event) []*Service {
+	var matched []*Service
+	for _, service := range fm.services {
+		fileField := service.Monitor.File
+		if ev.Op&fileField.ops == 0 {
+			continue
+		}
+		for _, p := range fileField.Paths {
+			if matchFilePath(p, ev.Name) {
+				matched = append(matched, service)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// getFileEventVars 返回 Exec 中可以使用的变量：path, name, event
+func getFileEventVars(ev fsnotify.Event) map[string]string {
+	return map[string]string{
+		"path":  ev.Name,
+		"name":  filepath.Base(ev.Name),
+		"event": strings.ToLower(ev.Op.String()),
+	}
+}
+
+func (fm *fileMonitor) handleEvent(ev fsnotify.Event) {
+	for _, service := range fm.findMatchedServices(ev) {
+		vars := getFileEventVars(ev)
+		delay := service.Monitor.File.delay
+		service := service
+		fm.mu.Lock()
+		if fm.stopped {
+			fm.mu.Unlock()
+			return
+		}
+		if delay == 0 {
+			fm.mu.Unlock()
+			logger.Debug("exec service", service)
+			go fm.exec(service, vars)
+			continue
+		}
+
+		// 延迟执行，期间的新事件会推迟执行的时间，执行时使用最后一个事件的变量
+		if timer, ok := fm.timers[service]; ok {
+			timer.Stop()
+		}
+		var timer *time.Timer
+		timer = time.AfterFunc(delay, func() {
+			fm.mu.Lock()
+			// 已经停止，或者 Stop 时回调已经开始执行而被新的定时器取代
+			if fm.stopped || fm.timers[service] != timer {
+				fm.mu.Unlock()
+				return
+			}
+			delete(fm.timers, service)
+			fm.mu.Unlock()
+			logger.Debug("exec service", service)
+			fm.exec(service, vars)
+		})
+		fm.timers[service] = timer
+		fm.mu.Unlock()
+	}
+}
+
+func (fm *fileMonitor) stop() error {
+	fm.mu.Lock()
+	fm.stopped = true
+	for service, timer := range fm.timers {
+		timer.Stop()
+		delete(fm.timers, service)
+	}
+	fm.mu.Unlock()
+
+	if fm.watcher != nil {
+		return fm.watcher.Close()
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
@@ -30,6 +31,9 @@ type Manager struct {
 
 	systemSigMonitor  *DBusSignalMonitor
 	sessionSigMonitor *DBusSignalMonitor
+	fileMonitor       *fileMonitor
+	udevMonitor       *udevMonitor
+	timerMonitor      *timerMonitor
 	sysSigLoop        *dbusutil.SignalLoop
 	agents            map[string]*agent
 }
@@ -39,6 +43,9 @@ func newManager(service *dbusutil.Service) *Manager {
 		service:           service,
 		systemSigMonitor:  newDBusSignalMonitor(busTypeSystem),
 		sessionSigMonitor: newDBusSignalMonitor(busTypeSession),
+		fileMonitor:       newFileMonitor(),
+		udevMonitor:       newUdevMonitor(),
+		timerMonitor:      newTimerMonitor(),
 		agents:            make(map[string]*agent),
 	}
 	return m
@@ -87,13 +94,33 @@ func (m *Manager) start() error {
 
 	m.systemSigMonitor.init()
 	go m.systemSigMonitor.signalLoop(m)
+
+	err = m.fileMonitor.start(m)
+	if err != nil {
+		logger.Warning("failed to start file monitor:", err)
+	}
+	err = m.udevMonitor.start(m)
+	if err != nil {
+		logger.Warning("failed to start udev monitor:", err)
+	}
+	m.timerMonitor.start(m)
 	return nil
 }
 
 func (m *Manager) stop() error {
 	m.sysSigLoop.Stop()
+	m.timerMonitor.stop()
+
+	err := m.fileMonitor.stop()
+	if err != nil {
+		logger.Warning(err)
+	}
+	err = m.udevMonitor.stop()
+	if err != nil {
+		logger.Warning(err)
+	}
 
-	err := m.sessionSigMonitor.stop()
+	err = m.sessionSigMonitor.stop()
 	if err != nil {
 		return err
 	}
@@ -195,6 +222,12 @@ func (m *Manager) loadServices() {
 			} else if dbusField.BusType == busTypeSessionStr {
 				m.sessionSigMonitor.appendService(service)
 			}
+		case typeFile:
+			m.fileMonitor.appendService(service)
+		case typeUdev:
+			m.udevMonitor.appendService(service)
+		case typeTimer:
+			m.timerMonitor.appendService(service)
 		}
 	}
 }
@@ -202,6 +235,9 @@ func (m *Manager) loadServices() {
 const (
 	serviceFileExt    = ".service.json"
 	typeDBus          = "DBus"
+	typeFile          = "File"
+	typeUdev          = "Udev"
+	typeTimer         = "Timer"
 	busTypeSystemStr  = "System"
 	busTypeSessionStr = "Session"
 )
@@ -245,21 +281,27 @@ func getNameOwner(conn *dbus.Conn, name string) (string, error) {
 	return owner, err
 }
 
-func newReplacer(signal *dbus.Signal) *strings.Replacer {
-	var oldNewSlice []string
-	for idx, item := range signal.Body {
-		oldStr := fmt.Sprintf("%%{arg%d}", idx)
-		newStr := fmt.Sprintf("%v", item)
-		logger.Debugf("old %q => new %q", oldStr, newStr)
-		oldNewSlice = append(oldNewSlice, oldStr, newStr)
-	}
-	return strings.NewReplacer(oldNewSlice...)
+// execArgRegexp 匹配 Exec 参数中的 %{name} 和 %{name:-default}
+var execArgRegexp = regexp.MustCompile(`%\{([A-Za-z0-9_.]+)(:-([^}]*))?\}`)
+
+// expandExecArg 将 arg 中的变量替换为 vars 中的值，变量不存在时使用默认值，
+// 没有默认值时保持原样。
+func expandExecArg(arg string, vars map[string]string) string {
+	return execArgRegexp.ReplaceAllStringFunc(arg, func(s string) string {
+		match := execArgRegexp.FindStringSubmatch(s)
+		if value, ok := vars[match[1]]; ok {
+			return value
+		}
+		if match[2] != "" {
+			return match[3]
+		}
+		return s
+	})
 }
 
-func (m *Manager) execService(service *Service,
-	signal *dbus.Signal) {
+func (m *Manager) execService(service *Service, vars map[string]string) {
 	if service.execFn != nil {
-		service.execFn(signal)
+		service.execFn(vars)
 		return
 	}
 
@@ -277,9 +319,8 @@ func (m *Manager) execService(service *Service,
 		}
 	}
 
-	replacer := newReplacer(signal)
 	for _, arg := range execArgs {
-		args = append(args, replacer.Replace(arg))
+		args = append(args, expandExecArg(arg, vars))
 	}
 
 	logger.Debugf("run cmd %q %#v", service.Exec[0], args)
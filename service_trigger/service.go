@@ -11,13 +11,18 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/godbus/dbus/v5"
 )
 
 type ServiceMonitor struct {
-	Type string
-	DBus *ServiceMonitorDBus
+	Type  string
+	DBus  *ServiceMonitorDBus
+	File  *ServiceMonitorFile
+	Udev  *ServiceMonitorUdev
+	Timer *ServiceMonitorTimer
 }
 
 // ServiceMonitorDBus type DBus
@@ -27,6 +32,41 @@ type ServiceMonitorDBus struct {
 	Interface string
 	Signal    string
 	Path      string // optional
+	// optional, key 为参数的序号，参数格式化为字符串后需要与 value 相等
+	Args map[int]string
+}
+
+// ServiceMonitorFile type File
+type ServiceMonitorFile struct {
+	// 文件或者目录的绝对路径，文件名部分支持通配符，为目录时匹配目录下的所有文件
+	Paths []string
+	// optional, Create, Write, Remove, Rename, Chmod，为空时匹配所有事件
+	Events []string
+	// optional, 例如 "500ms"，在最后一个事件之后等待一段时间再执行，用于合并连续的事件
+	Delay string
+
+	ops   fsnotify.Op
+	delay time.Duration
+}
+
+// ServiceMonitorUdev type Udev
+type ServiceMonitorUdev struct {
+	Subsystem string
+	// optional, add, remove, change 等，为空时匹配所有动作
+	Actions []string
+	// optional, 设备属性需要与 value 相等
+	Properties map[string]string
+}
+
+// ServiceMonitorTimer type Timer, Interval 和 OnCalendar 只能设置其中一个
+type ServiceMonitorTimer struct {
+	// 例如 "30m"
+	Interval string
+	// 例如 "daily", "hourly", "Mon..Fri 09:30", "*:0,30"
+	OnCalendar string
+
+	interval time.Duration
+	calendar *calendarSpec
 }
 
 type Service struct {
@@ -37,7 +77,7 @@ type Service struct {
 	Name        string
 	Description string
 	Exec        []string
-	execFn      func(signal *dbus.Signal)
+	execFn      func(vars map[string]string)
 }
 
 func (service *Service) getDBusMatchRule() string {
@@ -55,15 +95,21 @@ func (service *Service) getDBusMatchRule() string {
 }
 
 func (service *Service) check() error {
-	if service.Monitor.Type != "DBus" {
-		return fmt.Errorf("unknown Monitor.Type %q" + service.Monitor.Type)
+	var err error
+	switch service.Monitor.Type {
+	case typeDBus:
+		err = service.checkDBus()
+	case typeFile:
+		err = service.checkFile()
+	case typeUdev:
+		err = service.checkUdev()
+	case typeTimer:
+		err = service.checkTimer()
+	default:
+		return fmt.Errorf("unknown Monitor.Type %q", service.Monitor.Type)
 	}
-
-	if service.Monitor.Type == "DBus" {
-		err := service.checkDBus()
-		if err != nil {
-			return err
-		}
+	if err != nil {
+		return err
 	}
 
 	if service.Name == "" {
@@ -104,6 +150,105 @@ func (service *Service) checkDBus() error {
 	if dbusField.Signal == "" {
 		return errors.New("field Monitor.DBus.Signal is empty")
 	}
+
+	for idx := range dbusField.Args {
+		if idx < 0 {
+			return fmt.Errorf("field Monitor.DBus.Args has invalid index %d", idx)
+		}
+	}
+	return nil
+}
+
+var fileEventOps = map[string]fsnotify.Op{
+	"create": fsnotify.Create,
+	"write":  fsnotify.Write,
+	"remove": fsnotify.Remove,
+	"rename": fsnotify.Rename,
+	"chmod":  fsnotify.Chmod,
+}
+
+func (service *Service) checkFile() error {
+	fileField := service.Monitor.File
+	if fileField == nil {
+		return errors.New("field Monitor.File is nil")
+	}
+
+	if len(fileField.Paths) == 0 {
+		return errors.New("field Monitor.File.Paths is empty")
+	}
+	for _, p := range fileField.Paths {
+		if !filepath.IsAbs(p) {
+			return fmt.Errorf("field Monitor.File.Paths has relative path %q", p)
+		}
+		_, err := filepath.Match(filepath.Base(p), "")
+		if err != nil {
+			return fmt.Errorf("field Monitor.File.Paths has invalid pattern %q", p)
+		}
+		if strings.ContainsAny(filepath.Dir(p), "*?[") {
+			return fmt.Errorf("field Monitor.File.Paths has pattern in directory %q", p)
+		}
+	}
+
+	fileField.ops = 0
+	for _, event := range fileField.Events {
+		op, ok := fileEventOps[strings.ToLower(event)]
+		if !ok {
+			return fmt.Errorf("field Monitor.File.Events has unknown event %q", event)
+		}
+		fileField.ops |= op
+	}
+	if fileField.ops == 0 {
+		for _, op := range fileEventOps {
+			fileField.ops |= op
+		}
+	}
+
+	if fileField.Delay != "" {
+		delay, err := time.ParseDuration(fileField.Delay)
+		if err != nil || delay < 0 {
+			return fmt.Errorf("field Monitor.File.Delay %q is invalid", fileField.Delay)
+		}
+		fileField.delay = delay
+	}
+	return nil
+}
+
+func (service *Service) checkUdev() error {
+	udevField := service.Monitor.Udev
+	if udevField == nil {
+		return errors.New("field Monitor.Udev is nil")
+	}
+
+	if udevField.Subsystem == "" {
+		return errors.New("field Monitor.Udev.Subsystem is empty")
+	}
+	return nil
+}
+
+func (service *Service) checkTimer() error {
+	timerField := service.Monitor.Timer
+	if timerField == nil {
+		return errors.New("field Monitor.Timer is nil")
+	}
+
+	if (timerField.Interval == "") == (timerField.OnCalendar == "") {
+		return errors.New("only one of field Monitor.Timer.Interval and Monitor.Timer.OnCalendar should be set")
+	}
+
+	if timerField.Interval != "" {
+		interval, err := time.ParseDuration(timerField.Interval)
+		if err != nil || interval < time.Second {
+			return fmt.Errorf("field Monitor.Timer.Interval %q is invalid", timerField.Interval)
+		}
+		timerField.interval = interval
+		return nil
+	}
+
+	calendar, err := parseCalendarSpec(timerField.OnCalendar)
+	if err != nil {
+		return fmt.Errorf("field Monitor.Timer.OnCalendar is invalid: %v", err)
+	}
+	timerField.calendar = calendar
 	return nil
 }
 
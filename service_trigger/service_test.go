@@ -0,0 +1,231 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package service_trigger
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceCheck(t *testing.T) {
+	valid := []string{
+		`{"Name": "a", "Exec": ["true"], "Monitor": {"Type": "DBus", "DBus": {"BusType": "System",
+			"Sender": "org.a", "Interface": "org.a", "Signal": "S", "Args": {"1": "x"}}}}`,
+		`{"Name": "a", "Exec": ["true"], "Monitor": {"Type": "File", "File": {"Paths": ["/etc/*.conf"],
+			"Events": ["Create", "write"], "Delay": "500ms"}}}`,
+		`{"Name": "a", "Exec": ["true"], "Monitor": {"Type": "Udev", "Udev": {"Subsystem": "usb"}}}`,
+		`{"Name": "a", "Exec": ["true"], "Monitor": {"Type": "Timer", "Timer": {"Interval": "10m"}}}`,
+		`{"Name": "a", "Exec": ["true"], "Monitor": {"Type": "Timer", "Timer": {"OnCalendar": "daily"}}}`,
+	}
+	for _, str := range valid {
+		var service Service
+		require.NoError(t, json.Unmarshal([]byte(str), &service))
+		assert.NoError(t, service.check(), str)
+	}
+
+	invalid := []string{
+		`{"Name": "a", "Exec": ["true"], "Monitor": {"Type": "Unknown"}}`,
+		`{"Name": "a", "Exec": ["true"], "Monitor": {"Type": "File", "File": {"Paths": ["etc/a"]}}}`,
+		`{"Name": "a", "Exec": ["true"], "Monitor": {"Type": "File", "File": {"Paths": ["/etc/*/a"]}}}`,
+		`{"Name": "a", "Exec": ["true"], "Monitor": {"Type": "File", "File": {"Paths": ["/a"], "Events": ["Open"]}}}`,
+		`{"Name": "a", "Exec": ["true"], "Monitor": {"Type": "Udev", "Udev": {}}}`,
+		`{"Name": "a", "Exec": ["true"], "Monitor": {"Type": "Timer", "Timer": {}}}`,
+		`{"Name": "a", "Exec": ["true"], "Monitor": {"Type": "Timer", "Timer": {"Interval": "1m", "OnCalendar": "daily"}}}`,
+		`{"Name": "a", "Exec": ["true"], "Monitor": {"Type": "Timer", "Timer": {"OnCalendar": "Mon 25:00"}}}`,
+	}
+	for _, str := range invalid {
+		var service Service
+		require.NoError(t, json.Unmarshal([]byte(str), &service))
+		assert.Error(t, service.check(), str)
+	}
+}
+
+func TestExpandExecArg(t *testing.T) {
+	vars := map[string]string{"arg0": "foo", "ACTION": "add"}
+	assert.Equal(t, "foo", expandExecArg("%{arg0}", vars))
+	assert.Equal(t, "x-add-foo", expandExecArg("x-%{ACTION}-%{arg0}", vars))
+	assert.Equal(t, "none", expandExecArg("%{DEVNAME:-none}", vars))
+	assert.Equal(t, "", expandExecArg("%{DEVNAME:-}", vars))
+	assert.Equal(t, "%{arg1}", expandExecArg("%{arg1}", vars))
+}
+
+func TestMatchSignalArgs(t *testing.T) {
+	body := []interface{}{"a", uint32(1), true}
+	assert.True(t, matchSignalArgs(nil, body))
+	assert.True(t, matchSignalArgs(map[int]string{0: "a", 1: "1", 2: "true"}, body))
+	assert.False(t, matchSignalArgs(map[int]string{1: "2"}, body))
+	assert.False(t, matchSignalArgs(map[int]string{3: ""}, body))
+
+	vars := getSignalVars(&dbus.Signal{Sender: ":1.2", Path: "/a", Name: "org.a.S", Body: body})
+	assert.Equal(t, "1", vars["arg1"])
+	assert.Equal(t, "/a", vars["path"])
+}
+
+func TestMatchFilePath(t *testing.T) {
+	assert.True(t, matchFilePath("/etc/a.conf", "/etc/a.conf"))
+	assert.True(t, matchFilePath("/etc/*.conf", "/etc/b.conf"))
+	assert.False(t, matchFilePath("/etc/*.conf", "/etc/b.txt"))
+	assert.False(t, matchFilePath("/etc/*.conf", "/etc/x/b.conf"))
+	assert.True(t, matchFilePath("/etc/x/", "/etc/x/b"))
+
+	fm := newFileMonitor()
+	service := &Service{Monitor: ServiceMonitor{Type: typeFile, File: &ServiceMonitorFile{
+		Paths: []string{"/etc/a.conf"}, Events: []string{"Write"}}}}
+	require.NoError(t, service.checkFile())
+	fm.appendService(service)
+	assert.Len(t, fm.findMatchedServices(fsnotify.Event{Name: "/etc/a.conf", Op: fsnotify.Write}), 1)
+	assert.Len(t, fm.findMatchedServices(fsnotify.Event{Name: "/etc/a.conf", Op: fsnotify.Remove}), 0)
+}
+
+func TestFileMonitorDelay(t *testing.T) {
+	fm := newFileMonitor()
+	service := &Service{Monitor: ServiceMonitor{Type: typeFile, File: &ServiceMonitorFile{
+		Paths: []string{"/etc/a.conf"}, Events: []string{"Write"}, Delay: "50ms"}}}
+	require.NoError(t, service.checkFile())
+	fm.appendService(service)
+	executed := make(chan string, 10)
+	fm.exec = func(service *Service, vars map[string]string) {
+		executed <- vars["event"]
+	}
+
+	// 延迟期间的多个事件只执行一次
+	fm.handleEvent(fsnotify.Event{Name: "/etc/a.conf", Op: fsnotify.Write})
+	fm.handleEvent(fsnotify.Event{Name: "/etc/a.conf", Op: fsnotify.Write})
+	select {
+	case <-executed:
+	case <-time.After(time.Second):
+		t.Fatal("service is not executed")
+	}
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(t, executed, 0)
+
+	// 停止后不再执行
+	fm.handleEvent(fsnotify.Event{Name: "/etc/a.conf", Op: fsnotify.Write})
+	require.NoError(t, fm.stop())
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(t, executed, 0)
+}
+
+func TestFileMonitorMissingDir(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "a", "b")
+	assert.Equal(t, root, getNearestExistingDir(dir))
+
+	fm := newFileMonitor()
+	service := &Service{Monitor: ServiceMonitor{Type: typeFile, File: &ServiceMonitorFile{
+		Paths: []string{filepath.Join(dir, "c.conf")}, Events: []string{"Write"}}}}
+	require.NoError(t, service.checkFile())
+	fm.appendService(service)
+	require.NoError(t, fm.start(&Manager{}))
+	defer fm.stop()
+
+	getWatched := func() string {
+		fm.mu.Lock()
+		defer fm.mu.Unlock()
+		return fm.dirs[dir]
+	}
+	assert.Equal(t, root, getWatched())
+
+	// 目录创建后监视目录本身
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	assert.Eventually(t, func() bool {
+		return getWatched() == dir
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestParseUdevMsg(t *testing.T) {
+	props := []byte("ACTION=add\x00SUBSYSTEM=usb\x00DEVNAME=/dev/bus/usb/001/002\x00ID_VENDOR_ID=1234\x00")
+	header := make([]byte, 40)
+	copy(header, udevMsgPrefix)
+	header[8], header[9], header[10], header[11] = 0xfe, 0xed, 0xca, 0xfe
+	header[12] = 40
+	header[16] = 40
+	header[20] = byte(len(props))
+	msg := append(header, props...)
+
+	result, err := parseUdevMsg(msg)
+	require.NoError(t, err)
+	assert.Equal(t, "add", result["ACTION"])
+	assert.Equal(t, "/dev/bus/usb/001/002", result["DEVNAME"])
+
+	// 不接受内核格式的消息
+	_, err = parseUdevMsg([]byte("remove@/devices/usb1\x00ACTION=remove\x00SUBSYSTEM=usb\x00"))
+	assert.Error(t, err)
+
+	_, err = parseUdevMsg([]byte("garbage"))
+	assert.Error(t, err)
+
+	udevField := &ServiceMonitorUdev{Subsystem: "usb", Actions: []string{"add"},
+		Properties: map[string]string{"ID_VENDOR_ID": "1234"}}
+	props2, _ := parseUdevMsg(msg)
+	assert.True(t, matchUdevEvent(udevField, props2))
+	udevField.Actions = []string{"remove"}
+	assert.False(t, matchUdevEvent(udevField, props2))
+}
+
+func TestCheckUdevSender(t *testing.T) {
+	getName := func(pid int32) string {
+		if pid == 100 {
+			return "systemd-udevd"
+		}
+		return "evil"
+	}
+	cred := func(pid int32, uid uint32) []byte {
+		return syscall.UnixCredentials(&syscall.Ucred{Pid: pid, Uid: uid})
+	}
+	udevd := &syscall.SockaddrNetlink{Groups: udevMonitorGroupUdev, Pid: 100}
+	assert.NoError(t, checkUdevSender(udevd, cred(100, 0), getName))
+
+	// 普通用户发送的消息
+	assert.Error(t, checkUdevSender(udevd, cred(100, 1000), getName))
+	// root 用户的其他进程
+	assert.Error(t, checkUdevSender(&syscall.SockaddrNetlink{Groups: udevMonitorGroupUdev, Pid: 200},
+		cred(200, 0), getName))
+	// 单播消息
+	assert.Error(t, checkUdevSender(&syscall.SockaddrNetlink{Pid: 100}, cred(100, 0), getName))
+	// 没有 credentials
+	assert.Error(t, checkUdevSender(udevd, nil, getName))
+}
+
+func TestCalendarSpec(t *testing.T) {
+	loc := time.UTC
+	// 2024-01-01 是星期一
+	now := time.Date(2024, 1, 1, 10, 15, 30, 0, loc)
+
+	spec, err := parseCalendarSpec("daily")
+	require.NoError(t, err)
+	next, err := spec.next(now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, loc), next)
+
+	spec, err = parseCalendarSpec("*:0,30")
+	require.NoError(t, err)
+	next, _ = spec.next(now)
+	assert.Equal(t, time.Date(2024, 1, 1, 10, 30, 0, 0, loc), next)
+
+	spec, err = parseCalendarSpec("Sat..Sun 09:00:10")
+	require.NoError(t, err)
+	next, _ = spec.next(now)
+	assert.Equal(t, time.Date(2024, 1, 6, 9, 0, 10, 0, loc), next)
+
+	spec, err = parseCalendarSpec("10:15:40")
+	require.NoError(t, err)
+	next, _ = spec.next(now)
+	assert.Equal(t, time.Date(2024, 1, 1, 10, 15, 40, 0, loc), next)
+
+	for _, invalid := range []string{"", "Foo 10:00", "10", "10:60", "a b c"} {
+		_, err = parseCalendarSpec(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,251 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package service_trigger
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// calendarSpec 是 OnCalendar 的解析结果，格式为 "[WEEKDAYS] HOURS:MINUTES[:SECOND]"，
+// WEEKDAYS 例如 "Mon,Wed" 或 "Mon..Fri"，HOURS 和 MINUTES 为 "*" 或逗号分隔的数字，
+// 另外支持 minutely, hourly, daily, weekly。
+type calendarSpec struct {
+	// 按 time.Weekday 的位掩码，0 表示每天
+	weekdays uint8
+	// nil 表示任意值
+	hours   []int
+	minutes []int
+	second  int
+}
+
+var calendarShorthands = map[string]string{
+	"minutely": "*:*",
+	"hourly":   "*:00",
+	"daily":    "00:00",
+	"weekly":   "Mon 00:00",
+}
+
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+func parseWeekday(name string) (int, error) {
+	name = strings.ToLower(name)
+	for idx, weekday := range weekdayNames {
+		if name == weekday {
+			return idx, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid weekday %q", name)
+}
+
+func parseWeekdays(str string) (uint8, error) {
+	var mask uint8
+	for _, item := range strings.Split(str, ",") {
+		begin, end, isRange := strings.Cut(item, "..")
+		first, err := parseWeekday(begin)
+		if err != nil {
+			return 0, err
+		}
+		last := first
+		if isRange {
+			last, err = parseWeekday(end)
+			if err != nil {
+				return 0, err
+			}
+		}
+		// 支持跨周的范围，例如 Sat..Mon
+		for day := first; ; day = (day + 1) % 7 {
+			mask |= 1 << uint(day)
+			if day == last {
+				break
+			}
+		}
+	}
+	return mask, nil
+}
+
+// parseCalendarValues 解析 "*" 或者逗号分隔的数字，"*" 返回 nil
+func parseCalendarValues(str string, max int) ([]int, error) {
+	if str == "*" {
+		return nil, nil
+	}
+	var values []int
+	for _, item := range strings.Split(str, ",") {
+		v, err := strconv.Atoi(item)
+		if err != nil || v < 0 || v > max {
+			return nil, fmt.Errorf("invalid value %q", item)
+		}
+		values = append(values, v)
+	}
+	sort.Ints(values)
+	return values, nil
+}
+
+func parseCalendarSpec(str string) (*calendarSpec, error) {
+	str = strings.TrimSpace(str)
+	if shorthand, ok := calendarShorthands[strings.ToLower(str)]; ok {
+		str = shorthand
+	}
+
+	fields := strings.Fields(str)
+	if len(fields) == 0 || len(fields) > 2 {
+		return nil, fmt.Errorf("invalid calendar %q", str)
+	}
+
+	spec := &calendarSpec{}
+	var err error
+	if len(fields) == 2 {
+		spec.weekdays, err = parseWeekdays(fields[0])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	parts := strings.Split(fields[len(fields)-1], ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("invalid time %q", fields[len(fields)-1])
+	}
+	spec.hours, err = parseCalendarValues(parts[0], 23)
+	if err != nil {
+		return nil, err
+	}
+	spec.minutes, err = parseCalendarValues(parts[1], 59)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) == 3 {
+		spec.second, err = strconv.Atoi(parts[2])
+		if err != nil || spec.second < 0 || spec.second > 59 {
+			return nil, fmt.Errorf("invalid second %q", parts[2])
+		}
+	}
+	return spec, nil
+}
+
+func containsValue(values []int, v int) bool {
+	if values == nil {
+		return true
+	}
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func (spec *calendarSpec) matchMinute(t time.Time) bool {
+	if spec.weekdays != 0 && spec.weekdays&(1<<uint(t.Weekday())) == 0 {
+		return false
+	}
+	return containsValue(spec.hours, t.Hour()) && containsValue(spec.minutes, t.Minute())
+}
+
+// next 返回 t 之后第一个匹配的时间，按分钟查找，最多查找 8 天
+func (spec *calendarSpec) next(t time.Time) (time.Time, error) {
+	start := t.Truncate(time.Minute)
+	const maxMinutes = 8 * 24 * 60
+	for i := 0; i <= maxMinutes; i++ {
+		minute := start.Add(time.Duration(i) * time.Minute)
+		if !spec.matchMinute(minute) {
+			continue
+		}
+		candidate := minute.Add(time.Duration(spec.second) * time.Second)
+		if candidate.After(t) {
+			return candidate, nil
+		}
+	}
+	return time.Time{}, errors.New("no matched time")
+}
+
+type timerMonitor struct {
+	services []*Service
+	quit     chan struct{}
+}
+
+func newTimerMonitor() *timerMonitor {
+	return &timerMonitor{}
+}
+
+func (tm *timerMonitor) appendService(service *Service) {
+	tm.services = append(tm.services, service)
+}
+
+func (tm *timerMonitor) start(m *Manager) {
+	if len(tm.services) == 0 {
+		return
+	}
+	tm.quit = make(chan struct{})
+	for _, service := range tm.services {
+		if service.Monitor.Timer.calendar != nil {
+			go tm.runCalendar(m, service)
+		} else {
+			go tm.runInterval(m, service)
+		}
+	}
+}
+
+// getTimerVars 返回 Exec 中可以使用的变量：time, timestamp
+func getTimerVars(t time.Time) map[string]string {
+	return map[string]string{
+		"time":      t.Format(time.RFC3339),
+		"timestamp": strconv.FormatInt(t.Unix(), 10),
+	}
+}
+
+func (tm *timerMonitor) runInterval(m *Manager, service *Service) {
+	ticker := time.NewTicker(service.Monitor.Timer.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-tm.quit:
+			return
+		case t := <-ticker.C:
+			logger.Debug("exec service", service)
+			go m.execService(service, getTimerVars(t))
+		}
+	}
+}
+
+// runCalendar 按墙上时间执行，最长每分钟检查一次，避免休眠或者修改时间后错过执行
+func (tm *timerMonitor) runCalendar(m *Manager, service *Service) {
+	calendar := service.Monitor.Timer.calendar
+	for {
+		next, err := calendar.next(time.Now())
+		if err != nil {
+			logger.Warningf("%v: %v", service, err)
+			return
+		}
+
+		for {
+			wait := time.Until(next)
+			if wait <= 0 {
+				break
+			}
+			if wait > time.Minute {
+				wait = time.Minute
+			}
+			select {
+			case <-tm.quit:
+				return
+			case <-time.After(wait):
+			}
+		}
+
+		logger.Debug("exec service", service)
+		go m.execService(service, getTimerVars(next))
+	}
+}
+
+func (tm *timerMonitor) stop() {
+	if tm.quit != nil {
+		close(tm.quit)
+		tm.quit = nil
+	}
+}
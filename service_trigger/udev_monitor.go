@@ -0,0 +1,235 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package service_trigger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+)
+
+const (
+	// udevd 处理完规则后广播事件的 netlink 组，内核原始事件的组为 1
+	udevMonitorGroupUdev = 2
+	udevMonitorMagic     = 0xfeedcafe
+	udevMsgBufSize       = 64 * 1024
+)
+
+var udevMsgPrefix = []byte("libudev\x00")
+
+// udevd 及其 worker 进程的名称，新版本的 systemd 由 worker 广播事件
+var udevdProcessNames = []string{"systemd-udevd", "udevd", "(udev-worker)"}
+
+// udevMonitor 通过 NETLINK_KOBJECT_UEVENT 接收 udevd 广播的设备事件。
+// 普通进程也可以向该组发送消息，所以和 libudev 一样通过 SCM_CREDENTIALS 校验发送者。
+type udevMonitor struct {
+	services []*Service
+	file     *os.File
+}
+
+func newUdevMonitor() *udevMonitor {
+	return &udevMonitor{}
+}
+
+func (um *udevMonitor) appendService(service *Service) {
+	um.services = append(um.services, service)
+}
+
+func (um *udevMonitor) start(m *Manager) error {
+	if len(um.services) == 0 {
+		return nil
+	}
+
+	fd, err := syscall.Socket(syscall.AF_NETLINK,
+		syscall.SOCK_RAW|syscall.SOCK_CLOEXEC|syscall.SOCK_NONBLOCK,
+		syscall.NETLINK_KOBJECT_UEVENT)
+	if err != nil {
+		return err
+	}
+	err = syscall.SetsockoptInt(fd, syscall.SOL_SOCKET, syscall.SO_PASSCRED, 1)
+	if err != nil {
+		_ = syscall.Close(fd)
+		return err
+	}
+	err = syscall.Bind(fd, &syscall.SockaddrNetlink{
+		Family: syscall.AF_NETLINK,
+		Groups: udevMonitorGroupUdev,
+	})
+	if err != nil {
+		_ = syscall.Close(fd)
+		return err
+	}
+	// 非阻塞的 fd 由 runtime 的 poller 管理，Close 时 recvmsg 会返回
+	um.file = os.NewFile(uintptr(fd), "udev-monitor")
+
+	go um.loop(m)
+	return nil
+}
+
+func (um *udevMonitor) loop(m *Manager) {
+	rawConn, err := um.file.SyscallConn()
+	if err != nil {
+		logger.Warning(err)
+		return
+	}
+	buf := make([]byte, udevMsgBufSize)
+	oob := make([]byte, syscall.CmsgSpace(syscall.SizeofUcred))
+	for {
+		var n, oobn int
+		var from syscall.Sockaddr
+		var recvErr error
+		err = rawConn.Read(func(fd uintptr) bool {
+			n, oobn, _, from, recvErr = syscall.Recvmsg(int(fd), buf, oob, 0)
+			return recvErr != syscall.EAGAIN
+		})
+		if err == nil {
+			err = recvErr
+		}
+		if err != nil {
+			if !errors.Is(err, os.ErrClosed) {
+				logger.Warning(err)
+			}
+			return
+		}
+
+		err = checkUdevSender(from, oob[:oobn], getProcessName)
+		if err != nil {
+			logger.Debug("drop udev message:", err)
+			continue
+		}
+		props, err := parseUdevMsg(buf[:n])
+		if err != nil {
+			logger.Debug("invalid udev message:", err)
+			continue
+		}
+		for _, service := range um.findMatchedServices(props) {
+			logger.Debug("exec service", service)
+			go m.execService(service, props)
+		}
+	}
+}
+
+// checkUdevSender 校验消息的发送者，只接受 root 用户的 udevd 发送的组播消息
+func checkUdevSender(from syscall.Sockaddr, oob []byte, getName func(pid int32) string) error {
+	sa, ok := from.(*syscall.SockaddrNetlink)
+	if !ok {
+		return errors.New("not a netlink message")
+	}
+	if sa.Groups&udevMonitorGroupUdev == 0 {
+		return fmt.Errorf("unicast message from %d", sa.Pid)
+	}
+	msgs, err := syscall.ParseSocketControlMessage(oob)
+	if err != nil {
+		return err
+	}
+	if len(msgs) == 0 {
+		return errors.New("no sender credentials")
+	}
+	cred, err := syscall.ParseUnixCredentials(&msgs[0])
+	if err != nil {
+		return err
+	}
+	if cred.Uid != 0 {
+		return fmt.Errorf("sender uid %d is not root", cred.Uid)
+	}
+	if sa.Pid != 0 {
+		name := getName(cred.Pid)
+		for _, udevdName := range udevdProcessNames {
+			if name == udevdName {
+				return nil
+			}
+		}
+		return fmt.Errorf("sender %d %q is not udevd", cred.Pid, name)
+	}
+	return nil
+}
+
+func getProcessName(pid int32) string {
+	content, err := os.ReadFile(fmt.Sprintf("/proc/%d/comm", pid))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(content))
+}
+
+// parseUdevMsg 解析 udevd 广播的消息，返回设备属性，例如 ACTION, SUBSYSTEM, DEVPATH, DEVNAME。
+// 和 libudev 一样只接受 udevd 的消息格式，不接受内核格式的消息。
+// 消息头中除 magic 外的字段是主机字节序，支持的架构都是小端序。
+func parseUdevMsg(data []byte) (map[string]string, error) {
+	if !bytes.HasPrefix(data, udevMsgPrefix) {
+		return nil, errors.New("not a libudev message")
+	}
+	// struct udev_monitor_netlink_header
+	if len(data) < 24 {
+		return nil, errors.New("message is too short")
+	}
+	if binary.BigEndian.Uint32(data[8:12]) != udevMonitorMagic {
+		return nil, errors.New("invalid magic")
+	}
+	propOff := binary.LittleEndian.Uint32(data[16:20])
+	propLen := binary.LittleEndian.Uint32(data[20:24])
+	if uint64(propOff)+uint64(propLen) > uint64(len(data)) {
+		return nil, errors.New("invalid properties offset")
+	}
+	propData := data[propOff : propOff+propLen]
+
+	props := make(map[string]string)
+	for _, item := range bytes.Split(propData, []byte{0}) {
+		kv := bytes.SplitN(item, []byte("="), 2)
+		if len(kv) != 2 || len(kv[0]) == 0 {
+			continue
+		}
+		props[string(kv[0])] = string(kv[1])
+	}
+	if props["ACTION"] == "" || props["SUBSYSTEM"] == "" {
+		return nil, errors.New("ACTION or SUBSYSTEM is missing")
+	}
+	return props, nil
+}
+
+func (um *udevMonitor) findMatchedServices(props map[string]string) []*Service {
+	var matched []*Service
+	for _, service := range um.services {
+		if matchUdevEvent(service.Monitor.Udev, props) {
+			matched = append(matched, service)
+		}
+	}
+	return matched
+}
+
+func matchUdevEvent(udevField *ServiceMonitorUdev, props map[string]string) bool {
+	if udevField.Subsystem != props["SUBSYSTEM"] {
+		return false
+	}
+	if len(udevField.Actions) > 0 {
+		found := false
+		for _, action := range udevField.Actions {
+			if action == props["ACTION"] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for key, value := range udevField.Properties {
+		if props[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func (um *udevMonitor) stop() error {
+	if um.file != nil {
+		return um.file.Close()
+	}
+	return nil
+}
@@ -18,15 +18,22 @@
 #include "_cgo_export.h"
 
 #define ALARM_TIMEOUT_DEFAULT 700 // 700ms
+#define HOLD_DURATION_DEFAULT 800 // 800ms, 按住超过这个时间为 hold 手势
 #define LONG_PRESS_MAX_DISTANCE 3
+#define PINCH_PROGRESS_MIN_FINGERS 3 // 双指缩放由应用处理，不发送进度
+#define ROTATE_MIN_ANGLE 30 // 旋转超过 30 度才是旋转手势
+#define ROTATE_MAX_SCALE_DELTA 0.3 // 旋转时缩放的变化不能超过这个值
 #define SCREEN_WIDTH 100
 #define SCREEN_HEIGHT 100
 
 struct raw_multitouch_event {
     double dx_unaccel, dy_unaccel;
     double scale;
+    double last_scale; // libinput 给出的相对开始时的缩放
+    double angle; // 累计旋转的角度，顺时针为正
     int fingers;
     uint64_t t_start_tap;
+    uint64_t t_hold_begin;
     guint tap_id;
     bool dblclick;
     bool ignore;
@@ -169,8 +176,11 @@ raw_event_reset(struct raw_multitouch_event *event, bool reset_dblclick)
     event->dx_unaccel = 0.0;
     event->dy_unaccel = 0.0;
     event->scale = 0.0;
+    event->last_scale = 1.0;
+    event->angle = 0.0;
     event->fingers = 0;
     event->t_start_tap = 0;
+    event->t_hold_begin = 0;
     event->tap_id = 0;
     if (reset_dblclick)
         event->dblclick = false;
@@ -338,6 +348,10 @@ handle_tap_delay()
  * Pinch: (begin -> end)
  *     _scale += 1.0 - scale;
  *     if _scale != 0: _scale >= 0 ? 'in':'out'
+ *
+ * Rotate: (pinch begin -> end)
+ *     _angle += angle_delta;
+ *     if abs(_angle) >= 30 and abs(1.0 - scale) < 0.3: _angle >= 0 ? 'clockwise':'counterclockwise'
  **/
 static void
 handle_gesture_events(struct libinput_event *ev, int type)
@@ -374,6 +388,13 @@ handle_gesture_events(struct libinput_event *ev, int type)
     case LIBINPUT_EVENT_GESTURE_PINCH_UPDATE:{
         double scale = libinput_event_gesture_get_scale(gesture);
         raw->scale += 1.0-scale;
+        raw->last_scale = scale;
+        raw->angle += libinput_event_gesture_get_angle_delta(gesture);
+
+        int fingers = libinput_event_gesture_get_finger_count(gesture);
+        if (fingers >= PINCH_PROGRESS_MIN_FINGERS) {
+            handlePinchMoving(fingers, scale, raw->angle);
+        }
         break;
     }
     case LIBINPUT_EVENT_GESTURE_SWIPE_UPDATE:{
@@ -391,13 +412,32 @@ handle_gesture_events(struct libinput_event *ev, int type)
         break;
     }
     case LIBINPUT_EVENT_GESTURE_PINCH_END:{
+        raw->fingers = libinput_event_gesture_get_finger_count(gesture);
+        if (raw->fingers >= PINCH_PROGRESS_MIN_FINGERS) {
+            handlePinchStop(raw->fingers, libinput_event_gesture_get_cancelled(gesture));
+        }
+        if (libinput_event_gesture_get_cancelled(gesture)) {
+            raw_event_reset(raw, true);
+            break;
+        }
+
+        if (fabs(raw->angle) >= ROTATE_MIN_ANGLE
+        && fabs(1.0 - raw->last_scale) < ROTATE_MAX_SCALE_DELTA) {
+            g_debug("[Rotate] direction: %s, angle: %f, fingers: %d",
+                    raw->angle >= 0?"clockwise":"counterclockwise", raw->angle, raw->fingers);
+            handleGestureEvent(GESTURE_TYPE_ROTATE,
+                               (raw->angle >= 0?GESTURE_DIRECTION_CLOCKWISE:GESTURE_DIRECTION_COUNTERCLOCKWISE),
+                               raw->fingers);
+            raw_event_reset(raw, true);
+            break;
+        }
+
         // filter small scale threshold
         if (fabs(raw->scale) < 1) {
             raw_event_reset(raw, true);
             break;
         }
 
-        raw->fingers = libinput_event_gesture_get_finger_count(gesture);
         g_debug("[Pinch] direction: %s, fingers: %d",
                 raw->scale>= 0?"in":"out", raw->fingers);
         handleGestureEvent(GESTURE_TYPE_PINCH,
@@ -451,6 +491,8 @@ handle_gesture_events(struct libinput_event *ev, int type)
             handle_tap_stop();
             raw_event_reset(raw, true);
             raw->dblclick = true;
+        } else {
+            raw->t_hold_begin = libinput_event_gesture_get_time_usec(gesture);
         }
         break;
     case LIBINPUT_EVENT_GESTURE_HOLD_END:
@@ -461,6 +503,13 @@ handle_gesture_events(struct libinput_event *ev, int type)
 
         if (!raw->dblclick) {
             raw->fingers = libinput_event_gesture_get_finger_count(gesture);
+            if (raw->t_hold_begin > 0
+            && (libinput_event_gesture_get_time_usec(gesture) - raw->t_hold_begin) / 1000 >= HOLD_DURATION_DEFAULT) {
+                g_debug("[Hold] fingers: %d", raw->fingers);
+                handleGestureEvent(GESTURE_TYPE_HOLD, GESTURE_DIRECTION_NONE, raw->fingers);
+                raw_event_reset(raw, true);
+                break;
+            }
             raw->t_start_tap = libinput_event_gesture_get_time_usec(gesture);
             handle_tap_delay();
         } else {
@@ -16,8 +16,10 @@
 #define GESTURE_TYPE_SWIPE 100
 #define GESTURE_TYPE_PINCH 101
 #define GESTURE_TYPE_TAP 102
+#define GESTURE_TYPE_HOLD 103
+#define GESTURE_TYPE_ROTATE 104
 
-// tap
+// tap, hold
 #define GESTURE_DIRECTION_NONE 0
 // swipe
 #define GESTURE_DIRECTION_UP 10
@@ -27,6 +29,9 @@
 // pinch
 #define GESTURE_DIRECTION_IN 14
 #define GESTURE_DIRECTION_OUT 15
+// rotate
+#define GESTURE_DIRECTION_CLOCKWISE 16
+#define GESTURE_DIRECTION_COUNTERCLOCKWISE 17
 
 int start_loop(int verbose, double distance);
 void quit_loop(void);
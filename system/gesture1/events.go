@@ -0,0 +1,26 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package gesture1
+
+import (
+	"encoding/json"
+
+	dbus "github.com/godbus/dbus/v5"
+	"github.com/linuxdeepin/dde-daemon/common/gestureevent"
+	"github.com/linuxdeepin/go-lib/dbusutil"
+)
+
+// ListSupportedEvents 返回 deviceType 设备支持的手势事件，deviceType 为 touchpad 或 touchscreen，返回 JSON 格式
+func (*Manager) ListSupportedEvents(deviceType string) (events string, busErr *dbus.Error) {
+	list, err := gestureevent.List(deviceType)
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	data, err := json.Marshal(list)
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	return string(data), nil
+}
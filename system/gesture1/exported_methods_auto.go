@@ -8,6 +8,12 @@ import (
 
 func (v *Manager) GetExportedMethods() dbusutil.ExportedMethods {
 	return dbusutil.ExportedMethods{
+		{
+			Name:    "ListSupportedEvents",
+			Fn:      v.ListSupportedEvents,
+			InArgs:  []string{"deviceType"},
+			OutArgs: []string{"events"},
+		},
 		{
 			Name:   "SetEdgeMoveStopDuration",
 			Fn:     v.SetEdgeMoveStopDuration,
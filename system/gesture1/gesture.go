@@ -31,9 +31,11 @@ type TouchType int32
 type TouchDirection int32
 
 var (
-	GestureTypeSwipe = GestureType(C.GESTURE_TYPE_SWIPE)
-	GestureTypePinch = GestureType(C.GESTURE_TYPE_PINCH)
-	GestureTypeTap   = GestureType(C.GESTURE_TYPE_TAP)
+	GestureTypeSwipe  = GestureType(C.GESTURE_TYPE_SWIPE)
+	GestureTypePinch  = GestureType(C.GESTURE_TYPE_PINCH)
+	GestureTypeTap    = GestureType(C.GESTURE_TYPE_TAP)
+	GestureTypeHold   = GestureType(C.GESTURE_TYPE_HOLD)
+	GestureTypeRotate = GestureType(C.GESTURE_TYPE_ROTATE)
 
 	GestureDirectionNone  = GestureType(C.GESTURE_DIRECTION_NONE)
 	GestureDirectionUp    = GestureType(C.GESTURE_DIRECTION_UP)
@@ -43,6 +45,9 @@ var (
 	GestureDirectionIn    = GestureType(C.GESTURE_DIRECTION_IN)
 	GestureDirectionOut   = GestureType(C.GESTURE_DIRECTION_OUT)
 
+	GestureDirectionClockwise        = GestureType(C.GESTURE_DIRECTION_CLOCKWISE)
+	GestureDirectionCounterClockwise = GestureType(C.GESTURE_DIRECTION_COUNTERCLOCKWISE)
+
 	TouchTypeRightButton = TouchType(C.TOUCH_TYPE_RIGHT_BUTTON)
 
 	ButtonTypeDown = TouchType(C.BUTTON_TYPE_DOWN)
@@ -68,6 +73,10 @@ func (t GestureType) String() string {
 		return "pinch"
 	case GestureTypeTap:
 		return "tap"
+	case GestureTypeHold:
+		return "hold"
+	case GestureTypeRotate:
+		return "rotate"
 	case GestureDirectionNone:
 		return "none"
 	case GestureDirectionUp:
@@ -82,6 +91,10 @@ func (t GestureType) String() string {
 		return "in"
 	case GestureDirectionOut:
 		return "out"
+	case GestureDirectionClockwise:
+		return "clockwise"
+	case GestureDirectionCounterClockwise:
+		return "counterclockwise"
 	}
 	return "Unknown"
 }
@@ -160,6 +173,18 @@ type Manager struct {
 			fingers int32
 		}
 
+		// 三指及以上缩放、旋转的进度，scale 为相对开始时的缩放，angle 为累计旋转的角度，顺时针为正
+		PinchMoving struct {
+			fingers      int32
+			scale, angle float64
+		}
+
+		// 缩放、旋转结束或被取消
+		PinchStop struct {
+			fingers   int32
+			cancelled bool
+		}
+
 		TouchEdgeEvent struct {
 			direction      string
 			scaleX, scaleY float64
@@ -285,6 +310,23 @@ func handleSwipeStop(fingers C.int) {
 	}
 }
 
+//export handlePinchMoving
+func handlePinchMoving(fingers C.int, scale, angle C.double) {
+	err := _m.service.Emit(_m, "PinchMoving", int32(fingers), float64(scale), float64(angle))
+	if err != nil {
+		logger.Error("handlePinchMoving failed:", err)
+	}
+}
+
+//export handlePinchStop
+func handlePinchStop(fingers C.int, cancelled C.int) {
+	logger.Debug("emit PinchStop:", int32(fingers), cancelled != 0)
+	err := _m.service.Emit(_m, "PinchStop", int32(fingers), cancelled != 0)
+	if err != nil {
+		logger.Error("handlePinchStop failed:", err)
+	}
+}
+
 // touchscreen gesture
 //
 //export handleTouchEvent
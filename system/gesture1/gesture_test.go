@@ -11,20 +11,24 @@ import (
 )
 
 const (
-	GESTURE_DIRECTION_NONE  = 0
-	GESTURE_DIRECTION_UP    = 10
-	GESTURE_DIRECTION_DOWN  = 11
-	GESTURE_DIRECTION_LEFT  = 12
-	GESTURE_DIRECTION_RIGHT = 13
-	GESTURE_DIRECTION_IN    = 14
-	GESTURE_DIRECTION_OUT   = 15
-	TOUCH_TYPE_RIGHT_BUTTON = 50
-
-	GESTURE_TYPE_SWIPE = 100
-	GESTURE_TYPE_PINCH = 101
-	GESTURE_TYPE_TAP   = 102
-	BUTTON_TYPE_DOWN   = 501
-	BUTTON_TYPE_UP     = 502
+	GESTURE_DIRECTION_NONE             = 0
+	GESTURE_DIRECTION_UP               = 10
+	GESTURE_DIRECTION_DOWN             = 11
+	GESTURE_DIRECTION_LEFT             = 12
+	GESTURE_DIRECTION_RIGHT            = 13
+	GESTURE_DIRECTION_IN               = 14
+	GESTURE_DIRECTION_OUT              = 15
+	GESTURE_DIRECTION_CLOCKWISE        = 16
+	GESTURE_DIRECTION_COUNTERCLOCKWISE = 17
+	TOUCH_TYPE_RIGHT_BUTTON            = 50
+
+	GESTURE_TYPE_SWIPE  = 100
+	GESTURE_TYPE_PINCH  = 101
+	GESTURE_TYPE_TAP    = 102
+	GESTURE_TYPE_HOLD   = 103
+	GESTURE_TYPE_ROTATE = 104
+	BUTTON_TYPE_DOWN    = 501
+	BUTTON_TYPE_UP      = 502
 
 	DIR_NONE  = 0
 	DIR_TOP   = 1
@@ -52,7 +56,12 @@ func Test_String_GestureType(t *testing.T) {
 		GESTURE_TYPE_SWIPE:      "swipe",
 		GESTURE_TYPE_PINCH:      "pinch",
 		GESTURE_TYPE_TAP:        "tap",
-		UNKNOWN:                 "Unknown",
+		GESTURE_TYPE_HOLD:       "hold",
+		GESTURE_TYPE_ROTATE:     "rotate",
+
+		GESTURE_DIRECTION_CLOCKWISE:        "clockwise",
+		GESTURE_DIRECTION_COUNTERCLOCKWISE: "counterclockwise",
+		UNKNOWN:                            "Unknown",
 	}
 
 	g := GestureType(GESTURE_TYPE_SWIPE)
@@ -67,6 +76,22 @@ func Test_String_GestureType(t *testing.T) {
 	rtn = g.String()
 	assert.Equal(t, m1[GESTURE_TYPE_TAP], rtn)
 
+	g = GestureType(GESTURE_TYPE_HOLD)
+	rtn = g.String()
+	assert.Equal(t, m1[GESTURE_TYPE_HOLD], rtn)
+
+	g = GestureType(GESTURE_TYPE_ROTATE)
+	rtn = g.String()
+	assert.Equal(t, m1[GESTURE_TYPE_ROTATE], rtn)
+
+	g = GestureType(GESTURE_DIRECTION_CLOCKWISE)
+	rtn = g.String()
+	assert.Equal(t, m1[GESTURE_DIRECTION_CLOCKWISE], rtn)
+
+	g = GestureType(GESTURE_DIRECTION_COUNTERCLOCKWISE)
+	rtn = g.String()
+	assert.Equal(t, m1[GESTURE_DIRECTION_COUNTERCLOCKWISE], rtn)
+
 	g = GestureType(GESTURE_DIRECTION_NONE)
 	rtn = g.String()
 	assert.Equal(t, m1[GESTURE_DIRECTION_NONE], rtn)
@@ -179,3 +204,18 @@ func Test_String_TouchDirection(t *testing.T) {
 	rtn = g.String()
 	assert.Equal(t, m1[UNKNOWN], rtn)
 }
+
+func TestListSupportedEvents(t *testing.T) {
+	var m Manager
+	events, busErr := m.ListSupportedEvents("touchpad")
+	assert.Nil(t, busErr)
+	assert.Contains(t, events, `"Name":"hold"`)
+	assert.Contains(t, events, `"Name":"rotate"`)
+
+	events, busErr = m.ListSupportedEvents("touchscreen")
+	assert.Nil(t, busErr)
+	assert.Contains(t, events, `"Name":"edge"`)
+
+	_, busErr = m.ListSupportedEvents("mouse")
+	assert.NotNil(t, busErr)
+}
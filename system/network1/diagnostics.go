@@ -0,0 +1,466 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package network1
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	dbus "github.com/godbus/dbus/v5"
+	networkmanager "github.com/linuxdeepin/go-dbus-factory/system/org.freedesktop.networkmanager"
+	polkit "github.com/linuxdeepin/go-dbus-factory/system/org.freedesktop.policykit1"
+	"github.com/linuxdeepin/go-lib/dbusutil"
+)
+
+// 诊断的各个阶段，按顺序执行
+const (
+	diagStageLink   = "link"
+	diagStageDHCP   = "dhcp"
+	diagStageRoute  = "route"
+	diagStageDNS    = "dns"
+	diagStagePing   = "ping"
+	diagStageTCP    = "tcp"
+	diagStagePortal = "portal"
+)
+
+// 诊断阶段的结果
+const (
+	diagStatusOk      = "ok"
+	diagStatusWarning = "warning"
+	diagStatusFailed  = "failed"
+	diagStatusSkipped = "skipped"
+)
+
+const (
+	polkitActionDiagnose = "org.deepin.dde.network.diagnose"
+
+	diagDefaultTimeout  = 3000 // ms
+	diagMaxTimeout      = 10000
+	diagMaxTargets      = 8 // PingTargets、TCPTargets 和 DNSNames 各自的最大数量
+	diagDefaultDNSName  = "www.deepin.org"
+	diagPortalBodyLimit = 4096
+	rtfUp               = 0x1
+)
+
+var (
+	sysClassNetDir   = "/sys/class/net"
+	procNetRouteFile = "/proc/net/route"
+)
+
+// DiagnosticsOptions 诊断选项，均可为空
+type DiagnosticsOptions struct {
+	Interface   string   // 检查的网卡，为空时使用默认路由所在的网卡
+	PingTargets []string // 为空时 ping 默认网关
+	TCPTargets  []string // host:port
+	DNSNames    []string // 需要解析的域名
+	DNSServer   string   // host:port，为空时使用系统的 DNS 配置
+	// 网页认证探测地址，为空时使用 NetworkManager 的连通性检查地址
+	PortalURL string
+	// 探测地址返回 200 时期望包含的内容，为空时只要求返回 200 或 204
+	PortalContent string
+	Timeout       uint32 // 每项检查的超时时间，单位 ms，最大为 10000
+}
+
+// check 限制检查目标的数量，避免一次诊断占用过长时间
+func (opts *DiagnosticsOptions) check() error {
+	for name, targets := range map[string][]string{
+		"PingTargets": opts.PingTargets,
+		"TCPTargets":  opts.TCPTargets,
+		"DNSNames":    opts.DNSNames,
+	} {
+		if len(targets) > diagMaxTargets {
+			return fmt.Errorf("too many %s: %d, the maximum is %d", name, len(targets), diagMaxTargets)
+		}
+	}
+	return nil
+}
+
+type DiagnosticsStage struct {
+	Name       string
+	Status     string
+	Detail     string
+	DurationMs float64
+}
+
+type DiagnosticsReport struct {
+	Interface string
+	Passed    bool // 没有失败的阶段
+	Stages    []*DiagnosticsStage
+}
+
+// diagnoser 执行诊断，获取系统状态的方法可以在测试中替换
+type diagnoser struct {
+	opts       DiagnosticsOptions
+	timeout    time.Duration
+	resolver   *net.Resolver
+	httpClient *http.Client
+
+	getLinkState    func(iface string) (string, error)
+	getDHCPLease    func(iface string) (string, error)
+	getDefaultRoute func() (iface string, gateway string, err error)
+	ping            func(host string) error
+}
+
+func newDiagnoser(opts DiagnosticsOptions) *diagnoser {
+	if opts.Timeout == 0 {
+		opts.Timeout = diagDefaultTimeout
+	} else if opts.Timeout > diagMaxTimeout {
+		opts.Timeout = diagMaxTimeout
+	}
+	if len(opts.DNSNames) == 0 {
+		opts.DNSNames = []string{diagDefaultDNSName}
+	}
+	d := &diagnoser{
+		opts:            opts,
+		timeout:         time.Duration(opts.Timeout) * time.Millisecond,
+		resolver:        net.DefaultResolver,
+		getLinkState:    getLinkState,
+		getDefaultRoute: getDefaultRoute,
+		ping:            ping,
+		getDHCPLease: func(iface string) (string, error) {
+			return "", errors.New("DHCP lease is unknown")
+		},
+	}
+	if opts.DNSServer != "" {
+		d.resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, network, opts.DNSServer)
+			},
+		}
+	}
+	d.httpClient = &http.Client{
+		Timeout: d.timeout,
+		// 网页认证通过重定向实现，不能跟随重定向
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return d
+}
+
+// getLinkState 从 sysfs 读取网卡的状态，网卡未连接时返回错误
+func getLinkState(iface string) (string, error) {
+	dir := filepath.Join(sysClassNetDir, iface)
+	data, err := os.ReadFile(filepath.Join(dir, "operstate"))
+	if err != nil {
+		return "", err
+	}
+	operState := strings.TrimSpace(string(data))
+	// 网卡未启用时读取 carrier 会失败
+	data, _ = os.ReadFile(filepath.Join(dir, "carrier"))
+	carrier := strings.TrimSpace(string(data))
+	detail := fmt.Sprintf("operstate %s, carrier %s", operState, carrier)
+	if carrier != "1" || (operState != "up" && operState != "unknown") {
+		return "", fmt.Errorf("link of %s is down: %s", iface, detail)
+	}
+	return detail, nil
+}
+
+// getDefaultRoute 从 /proc/net/route 读取 IPv4 默认路由
+func getDefaultRoute() (iface string, gateway string, err error) {
+	f, err := os.Open(procNetRouteFile)
+	if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	// 跳过表头
+	scanner.Scan()
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 8 || fields[1] != "00000000" || fields[7] != "00000000" {
+			continue
+		}
+		flags, err := strconv.ParseUint(fields[3], 16, 32)
+		if err != nil || flags&rtfUp == 0 {
+			continue
+		}
+		// 网关地址为主机字节序
+		gw, err := hex.DecodeString(fields[2])
+		if err != nil || len(gw) != 4 {
+			continue
+		}
+		return fields[0], net.IPv4(gw[3], gw[2], gw[1], gw[0]).String(), nil
+	}
+	if err := scanner.Err(); err != nil {
+		return "", "", err
+	}
+	return "", "", errors.New("no default route")
+}
+
+func (d *diagnoser) run() *DiagnosticsReport {
+	report := &DiagnosticsReport{Passed: true}
+	routeIface, gateway, routeErr := d.getDefaultRoute()
+	iface := d.opts.Interface
+	if iface == "" {
+		iface = routeIface
+	}
+	report.Interface = iface
+
+	var linkFailed bool
+	add := func(name string, fn func() (string, string)) {
+		stage := &DiagnosticsStage{Name: name}
+		if linkFailed {
+			stage.Status = diagStatusSkipped
+			stage.Detail = "link is down"
+		} else {
+			start := time.Now()
+			stage.Status, stage.Detail = fn()
+			stage.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+		}
+		if stage.Status == diagStatusFailed {
+			report.Passed = false
+		}
+		report.Stages = append(report.Stages, stage)
+	}
+
+	add(diagStageLink, func() (string, string) {
+		if iface == "" {
+			linkFailed = true
+			return diagStatusFailed, "no network interface"
+		}
+		detail, err := d.getLinkState(iface)
+		if err != nil {
+			linkFailed = true
+			return diagStatusFailed, err.Error()
+		}
+		return diagStatusOk, detail
+	})
+	add(diagStageDHCP, func() (string, string) {
+		lease, err := d.getDHCPLease(iface)
+		if err != nil {
+			return diagStatusWarning, err.Error()
+		}
+		if lease == "" {
+			return diagStatusSkipped, "no DHCP lease, the address may be configured manually"
+		}
+		return diagStatusOk, lease
+	})
+	add(diagStageRoute, func() (string, string) {
+		if routeErr != nil {
+			return diagStatusFailed, routeErr.Error()
+		}
+		detail := fmt.Sprintf("default via %s dev %s", gateway, routeIface)
+		if routeIface != iface {
+			return diagStatusWarning, detail
+		}
+		return diagStatusOk, detail
+	})
+	add(diagStageDNS, d.checkDNS)
+	add(diagStagePing, func() (string, string) {
+		targets := d.opts.PingTargets
+		if len(targets) == 0 && gateway != "" && gateway != "0.0.0.0" {
+			targets = []string{gateway}
+		}
+		return d.checkTargets(targets, d.ping)
+	})
+	add(diagStageTCP, func() (string, string) {
+		return d.checkTargets(d.opts.TCPTargets, func(target string) error {
+			conn, err := net.DialTimeout("tcp", target, d.timeout)
+			if err != nil {
+				return err
+			}
+			return conn.Close()
+		})
+	})
+	add(diagStagePortal, d.checkPortal)
+	return report
+}
+
+func (d *diagnoser) checkDNS() (string, string) {
+	var details []string
+	status := diagStatusOk
+	for _, name := range d.opts.DNSNames {
+		ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+		addrs, err := d.resolver.LookupHost(ctx, name)
+		cancel()
+		if err != nil {
+			status = diagStatusFailed
+			details = append(details, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		sort.Strings(addrs)
+		details = append(details, fmt.Sprintf("%s: %s", name, strings.Join(addrs, ", ")))
+	}
+	return status, strings.Join(details, "; ")
+}
+
+func (d *diagnoser) checkTargets(targets []string, check func(target string) error) (string, string) {
+	if len(targets) == 0 {
+		return diagStatusSkipped, "no target"
+	}
+	var details []string
+	status := diagStatusOk
+	for _, target := range targets {
+		err := check(target)
+		if err != nil {
+			status = diagStatusFailed
+			details = append(details, fmt.Sprintf("%s: %v", target, err))
+			continue
+		}
+		details = append(details, target+": reachable")
+	}
+	return status, strings.Join(details, "; ")
+}
+
+func (d *diagnoser) checkPortal() (string, string) {
+	if d.opts.PortalURL == "" {
+		return diagStatusSkipped, "no portal probe url"
+	}
+	resp, err := d.httpClient.Get(d.opts.PortalURL)
+	if err != nil {
+		return diagStatusFailed, err.Error()
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNoContent:
+		return diagStatusOk, resp.Status
+	case resp.StatusCode >= 300 && resp.StatusCode < 400:
+		return diagStatusFailed, fmt.Sprintf("captive portal detected, redirected to %s", resp.Header.Get("Location"))
+	case resp.StatusCode == http.StatusOK:
+		if d.opts.PortalContent == "" {
+			return diagStatusOk, resp.Status
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, diagPortalBodyLimit))
+		if err != nil {
+			return diagStatusFailed, err.Error()
+		}
+		if !strings.Contains(string(body), d.opts.PortalContent) {
+			return diagStatusFailed, "captive portal detected, unexpected content"
+		}
+		return diagStatusOk, resp.Status
+	}
+	return diagStatusFailed, "unexpected response: " + resp.Status
+}
+
+// getDHCPLease 从 NetworkManager 获取网卡的 DHCP 租约，没有租约时返回空字符串
+func (n *Network) getDHCPLease(iface string) (string, error) {
+	devPath, err := n.nmManager.GetDeviceByIpIface(0, iface)
+	if err != nil {
+		return "", err
+	}
+	nmDevice, err := networkmanager.NewDevice(n.getSysBus(), devPath)
+	if err != nil {
+		return "", err
+	}
+
+	dhcp4Path, err := nmDevice.Device().Dhcp4Config().Get(0)
+	if err == nil && dhcp4Path != "/" {
+		dhcp4, err := networkmanager.NewDhcp4Config(n.getSysBus(), dhcp4Path)
+		if err != nil {
+			return "", err
+		}
+		options, err := dhcp4.Options().Get(0)
+		if err != nil {
+			return "", err
+		}
+		return formatDHCPOptions(options, "ip_address", "dhcp_server_identifier", "dhcp_lease_time"), nil
+	}
+
+	dhcp6Path, err := nmDevice.Device().Dhcp6Config().Get(0)
+	if err == nil && dhcp6Path != "/" {
+		dhcp6, err := networkmanager.NewDhcp6Config(n.getSysBus(), dhcp6Path)
+		if err != nil {
+			return "", err
+		}
+		options, err := dhcp6.Options().Get(0)
+		if err != nil {
+			return "", err
+		}
+		return formatDHCPOptions(options, "ip6_address", "dhcp6_server_id"), nil
+	}
+	return "", nil
+}
+
+func formatDHCPOptions(options map[string]dbus.Variant, keys ...string) string {
+	var items []string
+	for _, key := range keys {
+		if v, ok := options[key]; ok {
+			items = append(items, fmt.Sprintf("%s=%v", key, v.Value()))
+		}
+	}
+	return strings.Join(items, ", ")
+}
+
+func (n *Network) getConnectivityCheckUri() string {
+	obj := n.getSysBus().Object("org.freedesktop.NetworkManager", "/org/freedesktop/NetworkManager")
+	v, err := obj.GetProperty("org.freedesktop.NetworkManager.ConnectivityCheckUri")
+	if err != nil {
+		logger.Warning(err)
+		return ""
+	}
+	uri, _ := v.Value().(string)
+	return uri
+}
+
+func checkAuthorization(actionId string, sysBusName string) error {
+	systemBus, err := dbus.SystemBus()
+	if err != nil {
+		return err
+	}
+	authority := polkit.NewAuthority(systemBus)
+	subject := polkit.MakeSubject(polkit.SubjectKindSystemBusName)
+	subject.SetDetail("name", sysBusName)
+
+	ret, err := authority.CheckAuthorization(0, subject, actionId,
+		nil, polkit.CheckAuthorizationFlagsAllowUserInteraction, "")
+	if err != nil {
+		return err
+	}
+	if !ret.IsAuthorized {
+		return errors.New("not authorized")
+	}
+	return nil
+}
+
+// Diagnose 按顺序检查网卡、DHCP 租约、默认路由、DNS、ICMP/TCP 连通性和网页认证，
+// options 和返回的报告均为 JSON 格式
+func (n *Network) Diagnose(sender dbus.Sender, options string) (report string, busErr *dbus.Error) {
+	logger.Info("Diagnose", sender, options)
+	err := checkAuthorization(polkitActionDiagnose, string(sender))
+	if err != nil {
+		logger.Warningf("checkAuthorization failed, err: %v, actionId=%v", err, polkitActionDiagnose)
+		return "", dbusutil.ToError(err)
+	}
+	var opts DiagnosticsOptions
+	if options != "" {
+		err = json.Unmarshal([]byte(options), &opts)
+		if err != nil {
+			return "", dbusutil.ToError(err)
+		}
+	}
+	err = opts.check()
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	if opts.PortalURL == "" {
+		opts.PortalURL = n.getConnectivityCheckUri()
+	}
+
+	d := newDiagnoser(opts)
+	d.getDHCPLease = n.getDHCPLease
+	data, err := json.Marshal(d.run())
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	return string(data), nil
+}
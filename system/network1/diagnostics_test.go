@@ -0,0 +1,244 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package network1
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startTestDNSServer 启动一个只应答 A 记录的 DNS 服务，records 为域名到 IPv4 地址的映射
+func startTestDNSServer(t *testing.T, records map[string]net.IP) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			req := buf[:n]
+			if len(req) < 12 {
+				continue
+			}
+			// 解析问题部分的域名
+			var labels []byte
+			i := 12
+			for i < n && req[i] != 0 {
+				l := int(req[i])
+				if len(labels) > 0 {
+					labels = append(labels, '.')
+				}
+				labels = append(labels, req[i+1:i+1+l]...)
+				i += l + 1
+			}
+			qEnd := i + 5
+			if qEnd > n {
+				continue
+			}
+			qType := binary.BigEndian.Uint16(req[i+1:])
+			ip, ok := records[string(labels)]
+
+			resp := make([]byte, 12, 64)
+			copy(resp, req[:2])
+			binary.BigEndian.PutUint16(resp[2:], 0x8180)
+			binary.BigEndian.PutUint16(resp[4:], 1)
+			if !ok {
+				// NXDOMAIN
+				binary.BigEndian.PutUint16(resp[2:], 0x8183)
+			}
+			resp = append(resp, req[12:qEnd]...)
+			if ok && qType == 1 {
+				binary.BigEndian.PutUint16(resp[6:], 1)
+				resp = append(resp, 0xc0, 0x0c, 0, 1, 0, 1, 0, 0, 0, 60, 0, 4)
+				resp = append(resp, ip.To4()...)
+			}
+			_, _ = conn.WriteTo(resp, addr)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func newTestDiagnoser(opts DiagnosticsOptions) *diagnoser {
+	d := newDiagnoser(opts)
+	d.getDefaultRoute = func() (string, string, error) {
+		return "eth0", "192.168.1.1", nil
+	}
+	d.getLinkState = func(iface string) (string, error) {
+		return "operstate up, carrier 1", nil
+	}
+	d.getDHCPLease = func(iface string) (string, error) {
+		return "ip_address=192.168.1.100", nil
+	}
+	d.ping = func(host string) error {
+		return nil
+	}
+	return d
+}
+
+func getStage(report *DiagnosticsReport, name string) *DiagnosticsStage {
+	for _, stage := range report.Stages {
+		if stage.Name == name {
+			return stage
+		}
+	}
+	return nil
+}
+
+func TestDiagnoserRun(t *testing.T) {
+	dnsServer := startTestDNSServer(t, map[string]net.IP{
+		"portal.diag.test": net.IPv4(127, 0, 0, 1),
+	})
+	online := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer online.Close()
+	tcpTarget := online.Listener.Addr().String()
+
+	d := newTestDiagnoser(DiagnosticsOptions{
+		TCPTargets: []string{tcpTarget},
+		DNSNames:   []string{"portal.diag.test"},
+		DNSServer:  dnsServer,
+		PortalURL:  online.URL,
+	})
+	report := d.run()
+	assert.True(t, report.Passed)
+	assert.Equal(t, "eth0", report.Interface)
+	var names []string
+	for _, stage := range report.Stages {
+		names = append(names, stage.Name)
+		assert.Equal(t, diagStatusOk, stage.Status, "%+v", stage)
+	}
+	assert.Equal(t, []string{diagStageLink, diagStageDHCP, diagStageRoute, diagStageDNS,
+		diagStagePing, diagStageTCP, diagStagePortal}, names)
+	assert.Equal(t, "portal.diag.test: 127.0.0.1", getStage(report, diagStageDNS).Detail)
+	assert.Equal(t, "192.168.1.1: reachable", getStage(report, diagStagePing).Detail)
+}
+
+func TestDiagnoserFailures(t *testing.T) {
+	dnsServer := startTestDNSServer(t, nil)
+	portal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://login.portal.test/", http.StatusFound)
+	}))
+	defer portal.Close()
+
+	d := newTestDiagnoser(DiagnosticsOptions{
+		Interface:   "wlan0",
+		PingTargets: []string{"10.0.0.1"},
+		DNSNames:    []string{"missing.diag.test"},
+		DNSServer:   dnsServer,
+		PortalURL:   portal.URL,
+		Timeout:     1000,
+	})
+	d.getDHCPLease = func(iface string) (string, error) {
+		return "", nil
+	}
+	d.ping = func(host string) error {
+		return errors.New("timeout")
+	}
+	report := d.run()
+	assert.False(t, report.Passed)
+	assert.Equal(t, diagStatusSkipped, getStage(report, diagStageDHCP).Status)
+	assert.Equal(t, diagStatusWarning, getStage(report, diagStageRoute).Status)
+	assert.Equal(t, diagStatusFailed, getStage(report, diagStageDNS).Status)
+	assert.Equal(t, diagStatusFailed, getStage(report, diagStagePing).Status)
+	assert.Equal(t, diagStatusSkipped, getStage(report, diagStageTCP).Status)
+	portalStage := getStage(report, diagStagePortal)
+	assert.Equal(t, diagStatusFailed, portalStage.Status)
+	assert.Contains(t, portalStage.Detail, "http://login.portal.test/")
+
+	// 网卡未连接时跳过后面的检查
+	d.getLinkState = func(iface string) (string, error) {
+		return "", errors.New("link of wlan0 is down")
+	}
+	report = d.run()
+	assert.Equal(t, diagStatusFailed, report.Stages[0].Status)
+	for _, stage := range report.Stages[1:] {
+		assert.Equal(t, diagStatusSkipped, stage.Status)
+	}
+}
+
+func TestDiagnosticsOptionsLimits(t *testing.T) {
+	d := newDiagnoser(DiagnosticsOptions{Timeout: 60000})
+	assert.Equal(t, uint32(diagMaxTimeout), d.opts.Timeout)
+	assert.Equal(t, diagMaxTimeout*time.Millisecond, d.timeout)
+
+	opts := DiagnosticsOptions{PingTargets: make([]string, diagMaxTargets)}
+	assert.NoError(t, opts.check())
+	opts.TCPTargets = make([]string, diagMaxTargets+1)
+	assert.Error(t, opts.check())
+}
+
+func TestDiagnoserPortalContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("NetworkManager is online"))
+	}))
+	defer server.Close()
+
+	d := newTestDiagnoser(DiagnosticsOptions{PortalURL: server.URL, PortalContent: "NetworkManager is online"})
+	status, _ := d.checkPortal()
+	assert.Equal(t, diagStatusOk, status)
+
+	d = newTestDiagnoser(DiagnosticsOptions{PortalURL: server.URL, PortalContent: "success"})
+	status, _ = d.checkPortal()
+	assert.Equal(t, diagStatusFailed, status)
+}
+
+func TestGetDefaultRoute(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "route")
+	content := `Iface	Destination	Gateway 	Flags	RefCnt	Use	Metric	Mask		MTU	Window	IRTT
+eth0	0001A8C0	00000000	0001	0	0	100	00FFFFFF	0	0	0
+eth0	00000000	0101A8C0	0003	0	0	100	00000000	0	0	0
+`
+	require.NoError(t, os.WriteFile(file, []byte(content), 0644))
+	old := procNetRouteFile
+	procNetRouteFile = file
+	defer func() { procNetRouteFile = old }()
+
+	iface, gateway, err := getDefaultRoute()
+	require.NoError(t, err)
+	assert.Equal(t, "eth0", iface)
+	assert.Equal(t, "192.168.1.1", gateway)
+
+	require.NoError(t, os.WriteFile(file, []byte(content[:len(content)-47]), 0644))
+	_, _, err = getDefaultRoute()
+	assert.Error(t, err)
+}
+
+func TestGetLinkState(t *testing.T) {
+	dir := t.TempDir()
+	old := sysClassNetDir
+	sysClassNetDir = dir
+	defer func() { sysClassNetDir = old }()
+
+	writeLink := func(iface, operState, carrier string) {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, iface), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, iface, "operstate"), []byte(operState+"\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, iface, "carrier"), []byte(carrier+"\n"), 0644))
+	}
+	writeLink("eth0", "up", "1")
+	writeLink("eth1", "down", "0")
+
+	detail, err := getLinkState("eth0")
+	assert.NoError(t, err)
+	assert.Equal(t, "operstate up, carrier 1", detail)
+	_, err = getLinkState("eth1")
+	assert.Error(t, err)
+	_, err = getLinkState("eth2")
+	assert.Error(t, err)
+}
@@ -8,6 +8,12 @@ import (
 
 func (v *Network) GetExportedMethods() dbusutil.ExportedMethods {
 	return dbusutil.ExportedMethods{
+		{
+			Name:    "Diagnose",
+			Fn:      v.Diagnose,
+			InArgs:  []string{"options"},
+			OutArgs: []string{"report"},
+		},
 		{
 			Name:    "EnableDevice",
 			Fn:      v.EnableDevice,
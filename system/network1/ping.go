@@ -251,21 +251,26 @@ func handleICMPReply(icmp *ICMP) error {
 
 // Ping ping remote host, blocked operation.
 func (n *Network) Ping(host string) *dbus.Error {
+	return dbusutil.ToError(ping(host))
+}
+
+// ping 向 host 发送一次 ICMP 回显请求并等待回复
+func ping(host string) error {
 	conn, err := newICMPConn(host)
 	if err != nil {
-		return dbusutil.ToError(err)
+		return err
 	}
 	defer conn.Close()
 
 	err = sendEchoRequest(conn)
 	if err != nil {
-		return dbusutil.ToError(err)
+		return err
 	}
 
 	for {
 		icmp, err := recvEchoReply(conn)
 		if err != nil {
-			return dbusutil.ToError(err)
+			return err
 		}
 
 		switch icmp.Type {
@@ -275,12 +280,6 @@ func (n *Network) Ping(host string) *dbus.Error {
 		}
 
 		logger.Infof("Reply: %#v", icmp)
-		err = handleICMPReply(icmp)
-		if err != nil {
-			return dbusutil.ToError(err)
-		}
-		break
+		return handleICMPReply(icmp)
 	}
-
-	return nil
 }
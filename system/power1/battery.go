@@ -45,7 +45,17 @@ type Battery struct {
 	TimeToFull  uint64
 	UpdateTime  int64
 
+	// 是否支持设置充电阈值
+	ChargeThresholdSupported bool
+	// 电量低于该值时开始充电，驱动不支持时为 0
+	ChargeStartThreshold uint32
+	// 电量达到该值时停止充电
+	ChargeEndThreshold uint32
+
 	batteryHistory []float64
+	// 保存在磁盘上的历史记录
+	history    *batteryHistoryStore
+	isACOnline func() bool
 
 	refreshDone func()
 }
@@ -66,6 +76,13 @@ func newBattery(manager *Manager, device *gudev.Device) *Battery {
 		service:     manager.service,
 		gudevClient: manager.gudevClient,
 		SysfsPath:   sysfsPath,
+		history: newBatteryHistoryStore(filepath.Join(batteryHistoryDir,
+			getValidName(filepath.Base(sysfsPath))), batteryHistoryCapacity),
+		isACOnline: func() bool {
+			manager.PropsMu.RLock()
+			defer manager.PropsMu.RUnlock()
+			return !manager.OnBattery
+		},
 	}
 	ok := bat.refresh(device)
 	if !ok {
@@ -200,6 +217,10 @@ func (bat *Battery) _refresh(info *battery.BatteryInfo, setTimeToFull bool) {
 		time.Duration(info.TimeToFull)*time.Second,
 		info.TimeToFull)
 
+	if isPresent {
+		bat.appendToStore(info, updateTime)
+	}
+
 	/* lie to full */
 	bat.appendToHistory(info.Percentage)
 	if info.Percentage > 97.0 && bat.getHistoryLength() >= 10 && bat.calcHistoryVariance() < 0.3 {
@@ -238,6 +259,27 @@ func (bat *Battery) _refresh(info *battery.BatteryInfo, setTimeToFull bool) {
 	}
 }
 
+func (bat *Battery) appendToStore(info *battery.BatteryInfo, updateTime int64) {
+	if bat.history == nil {
+		return
+	}
+	record := BatteryRecord{
+		Time:             updateTime,
+		Percentage:       info.Percentage,
+		EnergyRate:       info.EnergyRate,
+		EnergyFull:       info.EnergyFull,
+		EnergyFullDesign: info.EnergyFullDesign,
+		Status:           info.Status,
+	}
+	if bat.isACOnline != nil {
+		record.ACOnline = bat.isACOnline()
+	}
+	err := bat.history.append(record)
+	if err != nil {
+		logger.Warning("save battery history failed:", err)
+	}
+}
+
 func (bat *Battery) Refresh() {
 	dev := bat.newDevice()
 	if dev != nil {
@@ -4,7 +4,19 @@
 
 package power
 
-import "math"
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/linuxdeepin/dde-api/powersupply/battery"
+)
 
 /* 记录数量 */
 func (bat *Battery) getHistoryLength() int {
@@ -35,3 +47,336 @@ func (bat *Battery) calcHistoryVariance() float64 {
 
 	return variance
 }
+
+const (
+	batteryHistoryDir = "/var/lib/dde-daemon/power/battery_history"
+	// 电池每 60 秒刷新一次，保存约 14 天的记录
+	batteryHistoryCapacity = 20160
+	// 状态不变时两条记录的最小间隔
+	batteryHistoryMinInterval = 30 * time.Second
+)
+
+var batteryHistoryMagic = [4]byte{'D', 'B', 'H', '1'}
+
+// BatteryRecord 是一条电池历史记录，GetHistory 以 JSON 数组返回
+type BatteryRecord struct {
+	// unix 时间戳（秒）
+	Time             int64
+	Percentage       float64
+	EnergyRate       float64
+	EnergyFull       float64
+	EnergyFullDesign float64
+	Status           battery.Status
+	ACOnline         bool
+}
+
+// 文件头，之后是 Capacity 条定长的 diskRecord
+type historyHeader struct {
+	Magic    [4]byte
+	Capacity uint32
+	// 下一条记录写入的位置
+	Head  uint32
+	Count uint32
+}
+
+type diskRecord struct {
+	Time             int64
+	Percentage       float64
+	EnergyRate       float64
+	EnergyFull       float64
+	EnergyFullDesign float64
+	Status           uint32
+	ACOnline         uint8
+	_                [3]byte
+}
+
+var (
+	historyHeaderSize = int64(binary.Size(historyHeader{}))
+	diskRecordSize    = int64(binary.Size(diskRecord{}))
+)
+
+// batteryHistoryStore 是保存在磁盘上的定长环形记录，写满后覆盖最旧的记录
+type batteryHistoryStore struct {
+	mu       sync.Mutex
+	filename string
+	header   historyHeader
+	// 按写入顺序保存的记录，最旧的在前
+	records []BatteryRecord
+}
+
+func newBatteryHistoryStore(filename string, capacity int) *batteryHistoryStore {
+	s := &batteryHistoryStore{
+		filename: filename,
+		header: historyHeader{
+			Magic:    batteryHistoryMagic,
+			Capacity: uint32(capacity),
+		},
+	}
+	err := s.load()
+	if err != nil && !os.IsNotExist(err) {
+		logger.Warningf("load battery history %q failed: %v", filename, err)
+	}
+	return s
+}
+
+func (s *batteryHistoryStore) load() error {
+	content, err := os.ReadFile(s.filename)
+	if err != nil {
+		return err
+	}
+	r := bytes.NewReader(content)
+	var header historyHeader
+	err = binary.Read(r, binary.LittleEndian, &header)
+	if err != nil {
+		return err
+	}
+	// 容量变化或者文件损坏时丢弃旧的记录
+	if header.Magic != batteryHistoryMagic || header.Capacity != s.header.Capacity ||
+		header.Head >= header.Capacity || header.Count > header.Capacity {
+		return errors.New("invalid battery history file")
+	}
+	available := uint32((int64(len(content)) - historyHeaderSize) / diskRecordSize)
+	if header.Count > available {
+		return s.loadTruncated(r, header, available)
+	}
+
+	slots := make([]diskRecord, header.Count)
+	err = binary.Read(r, binary.LittleEndian, slots)
+	if err != nil {
+		return err
+	}
+	// 未写满时记录从 0 开始，写满后最旧的记录位于 Head
+	start := 0
+	if header.Count == header.Capacity {
+		start = int(header.Head)
+	}
+	records := make([]BatteryRecord, 0, header.Count)
+	for i := 0; i < int(header.Count); i++ {
+		records = append(records, slots[(start+i)%int(header.Count)].toRecord())
+	}
+
+	s.header = header
+	s.records = records
+	return nil
+}
+
+// loadTruncated 加载被截断的文件，只保留完整的 available 条记录。
+// 写满后被截断时，按原来的 Head 恢复记录的顺序，并重新写入文件，使记录从 0 开始。
+func (s *batteryHistoryStore) loadTruncated(r io.Reader, header historyHeader, available uint32) error {
+	logger.Warningf("battery history %q has %d records, expect %d", s.filename, available, header.Count)
+	slots := make([]diskRecord, available)
+	err := binary.Read(r, binary.LittleEndian, slots)
+	if err != nil {
+		return err
+	}
+
+	if header.Count < header.Capacity {
+		// 未写满时记录从 0 开始，之后的记录接着写在截断的位置
+		header.Count = available
+		header.Head = available % header.Capacity
+		s.header = header
+		s.records = make([]BatteryRecord, 0, available)
+		for _, slot := range slots {
+			s.records = append(s.records, slot.toRecord())
+		}
+		return nil
+	}
+
+	// 写满时 [Head, Capacity) 是较旧的记录，[0, Head) 是较新的记录
+	head := header.Head
+	if head > available {
+		head = available
+	}
+	records := make([]BatteryRecord, 0, available)
+	for _, slot := range slots[head:] {
+		records = append(records, slot.toRecord())
+	}
+	for _, slot := range slots[:head] {
+		records = append(records, slot.toRecord())
+	}
+	s.header.Head = available % s.header.Capacity
+	s.header.Count = available
+	s.records = records
+	return s.rewrite()
+}
+
+// rewrite 按顺序重新写入所有记录，记录从 0 开始
+func (s *batteryHistoryStore) rewrite() error {
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.LittleEndian, s.header)
+	for _, record := range s.records {
+		_ = binary.Write(&buf, binary.LittleEndian, newDiskRecord(record))
+	}
+	tmpFile := s.filename + ".tmp"
+	err := os.WriteFile(tmpFile, buf.Bytes(), 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, s.filename)
+}
+
+func (s *batteryHistoryStore) append(record BatteryRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if n := len(s.records); n > 0 {
+		last := s.records[n-1]
+		if record.Status == last.Status && record.ACOnline == last.ACOnline &&
+			record.Time-last.Time < int64(batteryHistoryMinInterval/time.Second) {
+			return nil
+		}
+	}
+
+	capacity := int(s.header.Capacity)
+	s.records = append(s.records, record)
+	if len(s.records) > capacity {
+		s.records = s.records[len(s.records)-capacity:]
+	}
+	slot := s.header.Head
+	s.header.Head = (s.header.Head + 1) % s.header.Capacity
+	if s.header.Count < s.header.Capacity {
+		s.header.Count++
+	}
+	return s.write(slot, record)
+}
+
+// write 只写入一条记录和文件头，先写记录，避免中断后文件头指向未写入的记录
+func (s *batteryHistoryStore) write(slot uint32, record BatteryRecord) error {
+	err := os.MkdirAll(filepath.Dir(s.filename), 0755)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.filename, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.LittleEndian, newDiskRecord(record))
+	_, err = f.WriteAt(buf.Bytes(), historyHeaderSize+int64(slot)*diskRecordSize)
+	if err != nil {
+		return err
+	}
+
+	buf.Reset()
+	_ = binary.Write(&buf, binary.LittleEndian, s.header)
+	_, err = f.WriteAt(buf.Bytes(), 0)
+	return err
+}
+
+// query 返回时间在 [start, end] 内的记录
+func (s *batteryHistoryStore) query(start, end int64) []BatteryRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]BatteryRecord, 0)
+	for _, record := range s.records {
+		if record.Time >= start && record.Time <= end {
+			result = append(result, record)
+		}
+	}
+	return result
+}
+
+func (s *batteryHistoryStore) all() []BatteryRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]BatteryRecord(nil), s.records...)
+}
+
+func newDiskRecord(record BatteryRecord) diskRecord {
+	d := diskRecord{
+		Time:             record.Time,
+		Percentage:       record.Percentage,
+		EnergyRate:       record.EnergyRate,
+		EnergyFull:       record.EnergyFull,
+		EnergyFullDesign: record.EnergyFullDesign,
+		Status:           uint32(record.Status),
+	}
+	if record.ACOnline {
+		d.ACOnline = 1
+	}
+	return d
+}
+
+func (d diskRecord) toRecord() BatteryRecord {
+	return BatteryRecord{
+		Time:             d.Time,
+		Percentage:       d.Percentage,
+		EnergyRate:       d.EnergyRate,
+		EnergyFull:       d.EnergyFull,
+		EnergyFullDesign: d.EnergyFullDesign,
+		Status:           battery.Status(d.Status),
+		ACOnline:         d.ACOnline != 0,
+	}
+}
+
+// CapacitySample 是某一天最后一条记录中的电池健康度
+type CapacitySample struct {
+	Time int64
+	// EnergyFull / EnergyFullDesign 的百分比
+	Capacity float64
+}
+
+// BatteryStatistics 是 GetStatistics 返回的统计信息
+type BatteryStatistics struct {
+	// 最早一条记录的时间
+	Since int64
+	// 根据历史记录中充入的电量估算的充电循环次数
+	EstimatedCycles float64
+	// 内核提供的充电循环次数，不支持时为 -1
+	CycleCount int64
+	// 每天的电池健康度
+	CapacityTrend []CapacitySample
+}
+
+// estimateCycles 累加充电过程中电量的增加，每充入 100% 计为一次循环
+func estimateCycles(records []BatteryRecord) float64 {
+	var charged float64
+	for i := 1; i < len(records); i++ {
+		prev, cur := records[i-1], records[i]
+		if cur.Status != battery.StatusCharging && prev.Status != battery.StatusCharging {
+			continue
+		}
+		if cur.Percentage > prev.Percentage {
+			charged += cur.Percentage - prev.Percentage
+		}
+	}
+	return charged / 100
+}
+
+// calcCapacityTrend 取每天最后一条有效记录计算电池健康度
+func calcCapacityTrend(records []BatteryRecord, loc *time.Location) []CapacitySample {
+	result := make([]CapacitySample, 0)
+	lastDay := ""
+	for _, record := range records {
+		if record.EnergyFullDesign <= 0 || record.EnergyFull <= 0 {
+			continue
+		}
+		sample := CapacitySample{
+			Time:     record.Time,
+			Capacity: record.EnergyFull / record.EnergyFullDesign * 100,
+		}
+		day := time.Unix(record.Time, 0).In(loc).Format("2006-01-02")
+		if day == lastDay {
+			result[len(result)-1] = sample
+		} else {
+			result = append(result, sample)
+			lastDay = day
+		}
+	}
+	return result
+}
+
+func newBatteryStatistics(records []BatteryRecord, cycleCount int64) *BatteryStatistics {
+	stat := &BatteryStatistics{
+		EstimatedCycles: estimateCycles(records),
+		CycleCount:      cycleCount,
+		CapacityTrend:   calcCapacityTrend(records, time.Local),
+	}
+	if len(records) > 0 {
+		stat.Since = records[0].Time
+	}
+	return stat
+}
@@ -5,8 +5,14 @@
 package power
 
 import (
-	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/linuxdeepin/dde-api/powersupply/battery"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_getHistoryLength(t *testing.T) {
@@ -41,3 +47,108 @@ func Test_calcHistoryVariance(t *testing.T) {
 	variance := bat.calcHistoryVariance()
 	assert.Equal(t, 0.25, variance)
 }
+
+func Test_batteryHistoryStore(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "BAT0")
+	s := newBatteryHistoryStore(filename, 3)
+
+	for i := int64(1); i <= 4; i++ {
+		err := s.append(BatteryRecord{
+			Time:       i * 60,
+			Percentage: float64(i * 10),
+			Status:     battery.StatusCharging,
+			ACOnline:   true,
+		})
+		require.NoError(t, err)
+	}
+	// 间隔过短且状态不变的记录被忽略
+	require.NoError(t, s.append(BatteryRecord{Time: 250, Status: battery.StatusCharging, ACOnline: true}))
+
+	records := s.all()
+	require.Len(t, records, 3)
+	assert.Equal(t, int64(120), records[0].Time)
+	assert.Equal(t, int64(240), records[2].Time)
+
+	// 重新加载后按写入顺序恢复
+	loaded := newBatteryHistoryStore(filename, 3)
+	assert.Equal(t, records, loaded.all())
+	assert.Equal(t, records[1:], loaded.query(150, 300))
+
+	// 容量变化后丢弃旧的记录
+	assert.Empty(t, newBatteryHistoryStore(filename, 5).all())
+}
+
+func Test_batteryHistoryStoreTruncated(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "BAT0")
+	s := newBatteryHistoryStore(filename, 5)
+	for i := int64(1); i <= 3; i++ {
+		require.NoError(t, s.append(BatteryRecord{Time: i * 60, Status: battery.StatusDischarging}))
+	}
+
+	// 模拟写入最后一条记录时断电，文件头中的数量多于文件中的记录
+	require.NoError(t, os.Truncate(filename, historyHeaderSize+2*diskRecordSize+diskRecordSize/2))
+	loaded := newBatteryHistoryStore(filename, 5)
+	records := loaded.all()
+	require.Len(t, records, 2)
+	assert.Equal(t, int64(120), records[1].Time)
+
+	// 之后的记录接着写在截断的位置
+	require.NoError(t, loaded.append(BatteryRecord{Time: 240, Status: battery.StatusDischarging}))
+	records = newBatteryHistoryStore(filename, 5).all()
+	require.Len(t, records, 3)
+	assert.Equal(t, int64(240), records[2].Time)
+}
+
+func Test_batteryHistoryStoreTruncatedFull(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "BAT0")
+	s := newBatteryHistoryStore(filename, 5)
+	// 写满后再写两条，文件中的记录为 6 7 3 4 5，Head 为 2
+	for i := int64(1); i <= 7; i++ {
+		require.NoError(t, s.append(BatteryRecord{Time: i * 60, Status: battery.StatusDischarging}))
+	}
+
+	// 截断后只剩 6 7 3 4
+	require.NoError(t, os.Truncate(filename, historyHeaderSize+4*diskRecordSize))
+	loaded := newBatteryHistoryStore(filename, 5)
+	var times []int64
+	for _, record := range loaded.all() {
+		times = append(times, record.Time)
+	}
+	assert.Equal(t, []int64{180, 240, 360, 420}, times)
+
+	// 重新写入后记录从 0 开始，之后的记录接在最后
+	require.NoError(t, loaded.append(BatteryRecord{Time: 480, Status: battery.StatusDischarging}))
+	require.NoError(t, loaded.append(BatteryRecord{Time: 540, Status: battery.StatusDischarging}))
+	times = nil
+	for _, record := range newBatteryHistoryStore(filename, 5).all() {
+		times = append(times, record.Time)
+	}
+	assert.Equal(t, []int64{240, 360, 420, 480, 540}, times)
+}
+
+func Test_estimateCycles(t *testing.T) {
+	records := []BatteryRecord{
+		{Percentage: 80, Status: battery.StatusDischarging},
+		{Percentage: 20, Status: battery.StatusDischarging},
+		{Percentage: 70, Status: battery.StatusCharging},
+		{Percentage: 100, Status: battery.StatusFull},
+		{Percentage: 50, Status: battery.StatusDischarging},
+		{Percentage: 70, Status: battery.StatusCharging},
+	}
+	assert.InDelta(t, 1.0, estimateCycles(records), 0.0001)
+}
+
+func Test_calcCapacityTrend(t *testing.T) {
+	day := int64(24 * 3600)
+	records := []BatteryRecord{
+		{Time: 0, EnergyFull: 50, EnergyFullDesign: 50},
+		{Time: 3600, EnergyFull: 49, EnergyFullDesign: 50},
+		{Time: day, EnergyFull: 0, EnergyFullDesign: 50},
+		{Time: day + 3600, EnergyFull: 45, EnergyFullDesign: 50},
+	}
+	trend := calcCapacityTrend(records, time.UTC)
+	assert.Equal(t, []CapacitySample{
+		{Time: 3600, Capacity: 98},
+		{Time: day + 3600, Capacity: 90},
+	}, trend)
+}
@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package power
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	dbus "github.com/godbus/dbus/v5"
+	"github.com/linuxdeepin/go-lib/dbusutil"
+)
+
+// GetHistory 以 JSON 数组返回时间在 [start, end] 内的历史记录，时间为 unix 时间戳（秒），end 为 0 表示到当前时间
+func (bat *Battery) GetHistory(start, end int64) (history string, busErr *dbus.Error) {
+	if bat.history == nil {
+		return "", dbusutil.ToError(errors.New("battery history is not available"))
+	}
+	if end == 0 {
+		end = time.Now().Unix()
+	}
+	if start > end {
+		return "", dbusutil.ToError(errors.New("invalid time range"))
+	}
+
+	data, err := json.Marshal(bat.history.query(start, end))
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	return string(data), nil
+}
+
+// GetStatistics 以 JSON 对象返回充电循环次数和电池健康度的变化
+func (bat *Battery) GetStatistics() (statistics string, busErr *dbus.Error) {
+	if bat.history == nil {
+		return "", dbusutil.ToError(errors.New("battery history is not available"))
+	}
+
+	stat := newBatteryStatistics(bat.history.all(), bat.getCycleCount())
+	data, err := json.Marshal(stat)
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	return string(data), nil
+}
+
+// 读取内核提供的充电循环次数，不支持时返回 -1
+func (bat *Battery) getCycleCount() int64 {
+	content, err := os.ReadFile(filepath.Join(bat.SysfsPath, "cycle_count"))
+	if err != nil {
+		return -1
+	}
+	count, err := strconv.ParseInt(strings.TrimSpace(string(content)), 10, 64)
+	if err != nil || count < 0 {
+		return -1
+	}
+	return count
+}
@@ -0,0 +1,232 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package power
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	dbus "github.com/godbus/dbus/v5"
+	polkit "github.com/linuxdeepin/go-dbus-factory/system/org.freedesktop.policykit1"
+)
+
+const (
+	chargeThresholdFile = "/var/lib/dde-daemon/power/charge_threshold.json"
+
+	chargeStartThresholdName = "charge_control_start_threshold"
+	chargeEndThresholdName   = "charge_control_end_threshold"
+
+	polkitActionSetChargeThreshold = "org.deepin.dde.power.set-charge-threshold"
+)
+
+// ChargeThreshold 是电池的充电阈值，电量低于 Start 时开始充电，达到 End 时停止充电
+type ChargeThreshold struct {
+	Start uint32
+	End   uint32
+}
+
+// chargeThresholdConfig 保存用户设置的充电阈值，电池重新出现或者重启后重新应用，
+// key 为电池在 sysfs 中的名称，例如 BAT0。
+type chargeThresholdConfig struct {
+	filename   string
+	mu         sync.Mutex
+	thresholds map[string]ChargeThreshold
+}
+
+func newChargeThresholdConfig(filename string) *chargeThresholdConfig {
+	cfg := &chargeThresholdConfig{
+		filename:   filename,
+		thresholds: make(map[string]ChargeThreshold),
+	}
+	err := cfg.load()
+	if err != nil && !os.IsNotExist(err) {
+		logger.Warning("load charge threshold config failed:", err)
+	}
+	return cfg
+}
+
+func (cfg *chargeThresholdConfig) load() error {
+	content, err := os.ReadFile(cfg.filename)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, &cfg.thresholds)
+}
+
+func (cfg *chargeThresholdConfig) get(name string) (ChargeThreshold, bool) {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	threshold, ok := cfg.thresholds[name]
+	return threshold, ok
+}
+
+func (cfg *chargeThresholdConfig) set(name string, threshold ChargeThreshold) error {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	cfg.thresholds[name] = threshold
+
+	content, err := json.Marshal(cfg.thresholds)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(cfg.filename), 0755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(cfg.filename, content, 0644)
+}
+
+func readThreshold(filename string) (uint32, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return 0, err
+	}
+	value, err := strconv.ParseUint(strings.TrimSpace(string(content)), 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(value), nil
+}
+
+func writeThreshold(filename string, value uint32) error {
+	return os.WriteFile(filename, []byte(strconv.FormatUint(uint64(value), 10)), 0644)
+}
+
+// readChargeThreshold 读取 sysfsPath 下的充电阈值，supported 表示是否支持设置结束阈值，
+// 部分驱动只支持结束阈值，此时 Start 为 0。
+func readChargeThreshold(sysfsPath string) (threshold ChargeThreshold, supported bool) {
+	end, err := readThreshold(filepath.Join(sysfsPath, chargeEndThresholdName))
+	if err != nil {
+		return
+	}
+	supported = true
+	threshold.End = end
+	start, err := readThreshold(filepath.Join(sysfsPath, chargeStartThresholdName))
+	if err == nil {
+		threshold.Start = start
+	}
+	return
+}
+
+// writeChargeThreshold 将充电阈值写入 sysfsPath，
+// 内核要求 start 小于 end，所以调高时先写 end，调低时先写 start。
+func writeChargeThreshold(sysfsPath string, threshold ChargeThreshold) error {
+	startFile := filepath.Join(sysfsPath, chargeStartThresholdName)
+	endFile := filepath.Join(sysfsPath, chargeEndThresholdName)
+	_, err := os.Stat(startFile)
+	hasStart := err == nil
+
+	err = checkChargeThreshold(threshold, hasStart)
+	if err != nil {
+		return err
+	}
+	if !hasStart {
+		return writeThreshold(endFile, threshold.End)
+	}
+
+	current, _ := readChargeThreshold(sysfsPath)
+	if threshold.Start >= current.End {
+		err = writeThreshold(endFile, threshold.End)
+		if err == nil {
+			err = writeThreshold(startFile, threshold.Start)
+		}
+	} else {
+		err = writeThreshold(startFile, threshold.Start)
+		if err == nil {
+			err = writeThreshold(endFile, threshold.End)
+		}
+	}
+	return err
+}
+
+func checkChargeThreshold(threshold ChargeThreshold, hasStart bool) error {
+	if threshold.End == 0 || threshold.End > 100 {
+		return fmt.Errorf("invalid end threshold %d", threshold.End)
+	}
+	if !hasStart && threshold.Start != 0 {
+		return errors.New("start threshold is not supported")
+	}
+	if hasStart && threshold.Start >= threshold.End {
+		return fmt.Errorf("start threshold %d must be less than end threshold %d", threshold.Start, threshold.End)
+	}
+	return nil
+}
+
+func (bat *Battery) getName() string {
+	return filepath.Base(bat.SysfsPath)
+}
+
+// refreshChargeThreshold 从 sysfs 更新充电阈值属性
+func (bat *Battery) refreshChargeThreshold() {
+	threshold, supported := readChargeThreshold(bat.SysfsPath)
+	bat.PropsMu.Lock()
+	bat.setPropChargeThresholdSupported(supported)
+	bat.setPropChargeStartThreshold(threshold.Start)
+	bat.setPropChargeEndThreshold(threshold.End)
+	bat.PropsMu.Unlock()
+}
+
+// applyChargeThreshold 在电池出现时重新应用保存的充电阈值
+func (m *Manager) applyChargeThreshold(bat *Battery) {
+	if m.chargeThresholds == nil {
+		return
+	}
+	threshold, ok := m.chargeThresholds.get(bat.getName())
+	if ok {
+		err := writeChargeThreshold(bat.SysfsPath, threshold)
+		if err != nil {
+			logger.Warningf("apply charge threshold of %s failed: %v", bat.getName(), err)
+		}
+	}
+	bat.refreshChargeThreshold()
+}
+
+func (m *Manager) setChargeThreshold(batPath dbus.ObjectPath, threshold ChargeThreshold) error {
+	var bat *Battery
+	m.batteriesMu.Lock()
+	for _, b := range m.batteries {
+		if b.getObjPath() == batPath {
+			bat = b
+			break
+		}
+	}
+	m.batteriesMu.Unlock()
+	if bat == nil {
+		return fmt.Errorf("battery %q not found", batPath)
+	}
+
+	err := writeChargeThreshold(bat.SysfsPath, threshold)
+	if err != nil {
+		return err
+	}
+	bat.refreshChargeThreshold()
+	return m.chargeThresholds.set(bat.getName(), threshold)
+}
+
+func checkAuthorization(actionId string, sysBusName string) error {
+	systemBus, err := dbus.SystemBus()
+	if err != nil {
+		return err
+	}
+	authority := polkit.NewAuthority(systemBus)
+	subject := polkit.MakeSubject(polkit.SubjectKindSystemBusName)
+	subject.SetDetail("name", sysBusName)
+
+	ret, err := authority.CheckAuthorization(0, subject, actionId,
+		nil, polkit.CheckAuthorizationFlagsAllowUserInteraction, "")
+	if err != nil {
+		return err
+	}
+	if !ret.IsAuthorized {
+		return errors.New("not authorized")
+	}
+	return nil
+}
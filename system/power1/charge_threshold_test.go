@@ -0,0 +1,49 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package power
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_writeChargeThreshold(t *testing.T) {
+	dir := t.TempDir()
+	_, supported := readChargeThreshold(dir)
+	assert.False(t, supported)
+
+	// 只支持结束阈值
+	require.NoError(t, os.WriteFile(filepath.Join(dir, chargeEndThresholdName), []byte("100\n"), 0644))
+	assert.Error(t, writeChargeThreshold(dir, ChargeThreshold{Start: 40, End: 80}))
+	require.NoError(t, writeChargeThreshold(dir, ChargeThreshold{End: 80}))
+	threshold, supported := readChargeThreshold(dir)
+	assert.True(t, supported)
+	assert.Equal(t, ChargeThreshold{End: 80}, threshold)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, chargeStartThresholdName), []byte("0\n"), 0644))
+	require.NoError(t, writeChargeThreshold(dir, ChargeThreshold{Start: 85, End: 95}))
+	threshold, _ = readChargeThreshold(dir)
+	assert.Equal(t, ChargeThreshold{Start: 85, End: 95}, threshold)
+
+	assert.Error(t, writeChargeThreshold(dir, ChargeThreshold{Start: 60, End: 60}))
+	assert.Error(t, writeChargeThreshold(dir, ChargeThreshold{Start: 0, End: 101}))
+}
+
+func Test_chargeThresholdConfig(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "charge_threshold.json")
+	cfg := newChargeThresholdConfig(filename)
+	_, ok := cfg.get("BAT0")
+	assert.False(t, ok)
+
+	require.NoError(t, cfg.set("BAT0", ChargeThreshold{Start: 40, End: 80}))
+
+	threshold, ok := newChargeThresholdConfig(filename).get("BAT0")
+	assert.True(t, ok)
+	assert.Equal(t, ChargeThreshold{Start: 40, End: 80}, threshold)
+}
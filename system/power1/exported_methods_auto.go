@@ -7,7 +7,19 @@ import (
 )
 
 func (v *Battery) GetExportedMethods() dbusutil.ExportedMethods {
-	return nil
+	return dbusutil.ExportedMethods{
+		{
+			Name:    "GetHistory",
+			Fn:      v.GetHistory,
+			InArgs:  []string{"start", "end"},
+			OutArgs: []string{"history"},
+		},
+		{
+			Name:    "GetStatistics",
+			Fn:      v.GetStatistics,
+			OutArgs: []string{"statistics"},
+		},
+	}
 }
 func (v *Manager) GetExportedMethods() dbusutil.ExportedMethods {
 	return dbusutil.ExportedMethods{
@@ -33,6 +45,11 @@ func (v *Manager) GetExportedMethods() dbusutil.ExportedMethods {
 			Name: "RefreshMains",
 			Fn:   v.RefreshMains,
 		},
+		{
+			Name:   "SetChargeThreshold",
+			Fn:     v.SetChargeThreshold,
+			InArgs: []string{"battery", "start", "end"},
+		},
 		{
 			Name:   "SetCpuBoost",
 			Fn:     v.SetCpuBoost,
@@ -52,6 +52,8 @@ type Manager struct {
 	ac            *AC
 	gudevClient   *gudev.Client
 	dsgPower      ConfigManager.Manager
+	// 用户设置的电池充电阈值
+	chargeThresholds *chargeThresholdConfig
 	// 电池是否电量低
 	batteryLow bool
 	// 初始化是否完成
@@ -239,6 +241,7 @@ func (m *Manager) init() error {
 	m.initLidSwitch()
 	devices := powersupply.GetDevices(m.gudevClient)
 
+	m.chargeThresholds = newChargeThresholdConfig(chargeThresholdFile)
 	m.initAC(devices)
 	m.initBatteries(devices)
 	for _, dev := range devices {
@@ -572,6 +575,8 @@ func (m *Manager) addBattery(dev *gudev.Device) (*Battery, bool) {
 		return nil, false
 	}
 
+	m.applyChargeThreshold(bat)
+
 	m.batteriesMu.Lock()
 	m.batteries[sysfsPath] = bat
 	m.refreshBatteryDisplay()
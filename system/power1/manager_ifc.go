@@ -130,3 +130,16 @@ func (m *Manager) LockCpuFreq(governor string, lockTime int32) *dbus.Error {
 
 	return nil
 }
+
+// SetChargeThreshold 设置电池的充电阈值，电量低于 start 时开始充电，达到 end 时停止充电，
+// 驱动只支持结束阈值时 start 须为 0，设置会被保存并在电池重新出现后重新应用。
+func (m *Manager) SetChargeThreshold(sender dbus.Sender, battery dbus.ObjectPath, start, end uint32) *dbus.Error {
+	err := checkAuthorization(polkitActionSetChargeThreshold, string(sender))
+	if err != nil {
+		logger.Warningf("checkAuthorization failed, err: %v, actionId=%v", err, polkitActionSetChargeThreshold)
+		return dbusutil.ToError(err)
+	}
+	logger.Infof("set charge threshold of %s: %d-%d", battery, start, end)
+	err = m.setChargeThreshold(battery, ChargeThreshold{Start: start, End: end})
+	return dbusutil.ToError(err)
+}
@@ -525,3 +525,42 @@ func (v *Battery) setPropUpdateTime(value int64) (changed bool) {
 func (v *Battery) emitPropChangedUpdateTime(value int64) error {
 	return v.service.EmitPropertyChanged(v, "UpdateTime", value)
 }
+
+func (v *Battery) setPropChargeThresholdSupported(value bool) (changed bool) {
+	if v.ChargeThresholdSupported != value {
+		v.ChargeThresholdSupported = value
+		v.emitPropChangedChargeThresholdSupported(value)
+		return true
+	}
+	return false
+}
+
+func (v *Battery) emitPropChangedChargeThresholdSupported(value bool) error {
+	return v.service.EmitPropertyChanged(v, "ChargeThresholdSupported", value)
+}
+
+func (v *Battery) setPropChargeStartThreshold(value uint32) (changed bool) {
+	if v.ChargeStartThreshold != value {
+		v.ChargeStartThreshold = value
+		v.emitPropChangedChargeStartThreshold(value)
+		return true
+	}
+	return false
+}
+
+func (v *Battery) emitPropChangedChargeStartThreshold(value uint32) error {
+	return v.service.EmitPropertyChanged(v, "ChargeStartThreshold", value)
+}
+
+func (v *Battery) setPropChargeEndThreshold(value uint32) (changed bool) {
+	if v.ChargeEndThreshold != value {
+		v.ChargeEndThreshold = value
+		v.emitPropChangedChargeEndThreshold(value)
+		return true
+	}
+	return false
+}
+
+func (v *Battery) emitPropChangedChargeEndThreshold(value uint32) error {
+	return v.service.EmitPropertyChanged(v, "ChargeEndThreshold", value)
+}
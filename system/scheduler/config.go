@@ -6,28 +6,164 @@ package scheduler
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+)
+
+const (
+	userConfigFile   = "/etc/deepin/scheduler/config.json"       // 用户
+	systemConfigFile = "/usr/share/deepin/scheduler/config.json" // 软件包
 )
 
 type config struct {
 	filename           string
 	Processes          map[string]*priorityCfg `json:"processes"`
+	Rules              []*ruleCfg              `json:"rules"`
 	Enabled            bool                    `json:"enabled"`
 	ProcMonitorEnabled bool                    `json:"procMonitorEnabled"`
+	ForegroundBoost    *foregroundBoostCfg     `json:"foregroundBoost"`
+
+	rules []*rule
+}
+
+// foregroundBoostCfg 配置会话上报的前台应用及后台应用的优先级，
+// 同一个 cgroup 内的进程被视为同一个应用。
+type foregroundBoostCfg struct {
+	Enabled bool `json:"enabled"`
+	// 前台应用的 nice 值及 io 优先级
+	CPU int            `json:"cpu"`
+	IO  *ioPriorityCfg `json:"io"`
+	// 后台应用的 nice 值及 io 优先级
+	BackgroundCPU int            `json:"backgroundCpu"`
+	BackgroundIO  *ioPriorityCfg `json:"backgroundIo"`
+	// 识别应用进程的 cgroup glob，为空时使用 defaultAppCgroup
+	AppCgroup string `json:"appCgroup"`
+
+	ioPrio   int
+	bgIOPrio int
 }
 
+const defaultAppCgroup = "/user.slice/*/*/app.slice/*"
+
 type priorityCfg struct {
 	CPU int `json:"cpu"`
 }
 
-func (c *config) getPriority(exe string) *priorityCfg {
-	if pCfg, ok := c.Processes[exe]; ok {
-		return pCfg
+// ruleCfg 是配置文件中的一条规则，match 中的所有条件都满足时才会应用规则中的策略，
+// 未配置的策略保持进程原有的值。
+type ruleCfg struct {
+	Name  string   `json:"name"`
+	Match matchCfg `json:"match"`
+
+	// cpu 优先级 nice 值，范围是 19（低） ～ -20（高）
+	CPU *int `json:"cpu"`
+	// io 优先级
+	IO *ioPriorityCfg `json:"io"`
+	// 允许进程运行的 cpu 编号
+	CPUAffinity []int `json:"cpuAffinity"`
+	// 写入 /proc/<pid>/oom_score_adj 的值，范围是 -1000 ～ 1000
+	OOMScoreAdj *int `json:"oomScoreAdj"`
+}
+
+type matchCfg struct {
+	// 可执行文件的 glob，含有 / 时匹配完整路径，否则匹配文件名
+	Exe string `json:"exe"`
+	// 以空格连接的命令行参数的正则表达式
+	Cmdline string  `json:"cmdline"`
+	UID     *uint32 `json:"uid"`
+	// 进程所在 cgroup 路径的 glob，例如 /user.slice/*/*/app.slice/*
+	Cgroup string `json:"cgroup"`
+}
+
+type ioPriorityCfg struct {
+	// realtime, best-effort 或 idle
+	Class string `json:"class"`
+	// 0（高） ～ 7（低），idle 时忽略
+	Level int `json:"level"`
+}
+
+// compile 检查并编译配置中的规则，processes 中的旧配置转换为只匹配可执行文件的规则，
+// 排在显式配置的规则之后。
+func (c *config) compile() error {
+	c.rules = nil
+	for i, rc := range c.Rules {
+		r, err := newRule(rc)
+		if err != nil {
+			return fmt.Errorf("rule %d %q: %w", i, rc.Name, err)
+		}
+		c.rules = append(c.rules, r)
+	}
+
+	exes := make([]string, 0, len(c.Processes))
+	for exe := range c.Processes {
+		exes = append(exes, exe)
+	}
+	// 完整路径优先于文件名匹配
+	sort.Slice(exes, func(i, j int) bool {
+		iAbs, jAbs := filepath.IsAbs(exes[i]), filepath.IsAbs(exes[j])
+		if iAbs != jAbs {
+			return iAbs
+		}
+		return exes[i] < exes[j]
+	})
+	for _, exe := range exes {
+		cpu := c.Processes[exe].CPU
+		r, err := newRule(&ruleCfg{
+			Name:  exe,
+			Match: matchCfg{Exe: exe},
+			CPU:   &cpu,
+		})
+		if err != nil {
+			return fmt.Errorf("process %q: %w", exe, err)
+		}
+		c.rules = append(c.rules, r)
+	}
+
+	if c.ForegroundBoost != nil {
+		err := c.ForegroundBoost.check()
+		if err != nil {
+			return fmt.Errorf("foregroundBoost: %w", err)
+		}
+	}
+	return nil
+}
+
+// matchRule 返回第一条匹配进程的规则
+func (c *config) matchRule(info *procInfo) *rule {
+	for _, r := range c.rules {
+		if r.match(info) {
+			return r
+		}
 	}
-	name := filepath.Base(exe)
-	if pCfg, ok := c.Processes[name]; ok {
-		return pCfg
+	return nil
+}
+
+func (fb *foregroundBoostCfg) check() error {
+	for _, cpu := range []int{fb.CPU, fb.BackgroundCPU} {
+		if cpu < -20 || cpu > 19 {
+			return fmt.Errorf("invalid cpu priority %d", cpu)
+		}
+	}
+	var err error
+	if fb.IO != nil {
+		fb.ioPrio, err = fb.IO.value()
+		if err != nil {
+			return err
+		}
+	}
+	if fb.BackgroundIO != nil {
+		fb.bgIOPrio, err = fb.BackgroundIO.value()
+		if err != nil {
+			return err
+		}
+	}
+	if fb.AppCgroup == "" {
+		fb.AppCgroup = defaultAppCgroup
+	}
+	if _, err := filepath.Match(fb.AppCgroup, ""); err != nil {
+		return fmt.Errorf("invalid appCgroup pattern: %w", err)
 	}
 	return nil
 }
@@ -42,14 +178,18 @@ func loadConfigAux(filename string) (*config, error) {
 	if err != nil {
 		return nil, err
 	}
+	err = cfg.compile()
+	if err != nil {
+		return nil, err
+	}
 	cfg.filename = filename
 	return &cfg, nil
 }
 
 func loadConfig() (*config, error) {
 	paths := []string{
-		"/etc/deepin/scheduler/config.json",       // 用户
-		"/usr/share/deepin/scheduler/config.json", // 软件包
+		userConfigFile,
+		systemConfigFile,
 	}
 	var lastErr error
 	for _, p := range paths {
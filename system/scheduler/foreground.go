@@ -0,0 +1,447 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package scheduler
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	polkit "github.com/linuxdeepin/go-dbus-factory/system/org.freedesktop.policykit1"
+)
+
+const (
+	polkitActionSetForegroundProcess = "org.deepin.dde.scheduler.set-foreground-process"
+	// 同一个会话两次调整之间的最小间隔，期间的上报只处理最后一次
+	foregroundMinInterval = 500 * time.Millisecond
+)
+
+// foregroundApp 记录会话上报的前台应用
+type foregroundApp struct {
+	sender string
+	uid    uint32
+	pid    int
+	// 前台进程所在的应用 cgroup，为空时只提升前台进程本身
+	cgroup string
+}
+
+// foregroundRequest 记录会话尚未处理的前台进程上报
+type foregroundRequest struct {
+	uid     uint32
+	pid     int
+	pending bool
+}
+
+// savedPriority 记录线程被调整前的优先级，用于前台切换后恢复
+type savedPriority struct {
+	uid uint32
+	// 线程所属的进程，进程退出后删除记录
+	tgid   int
+	nice   int
+	ioPrio int
+}
+
+// boostClass 表示进程在前台提升中所处的类别
+type boostClass int
+
+const (
+	boostNone boostClass = iota
+	boostForeground
+	boostBackground
+)
+
+// getAppCgroup 返回进程匹配 pattern 的应用 cgroup
+func getAppCgroup(cgroups []string, pattern string) string {
+	for _, cgroup := range cgroups {
+		if ok, _ := filepath.Match(pattern, cgroup); ok {
+			return cgroup
+		}
+	}
+	return ""
+}
+
+// classify 判断进程属于前台应用、后台应用还是不需要调整，
+// 与前台进程处于同一个应用 cgroup 的进程都属于前台应用。
+func (app *foregroundApp) classify(fb *foregroundBoostCfg, info *procInfo) boostClass {
+	if info.uid != app.uid {
+		return boostNone
+	}
+	if info.pid == app.pid {
+		return boostForeground
+	}
+	cgroup := getAppCgroup(info.cgroups, fb.AppCgroup)
+	if cgroup == "" {
+		return boostNone
+	}
+	if cgroup == app.cgroup {
+		return boostForeground
+	}
+	return boostBackground
+}
+
+// checkForegroundProcess 检查前台提升是否开启，以及 uid 用户是否可以将 pid 设置为前台进程
+func (s *Scheduler) checkForegroundProcess(uid uint32, pid int) (*config, *procInfo, error) {
+	cfg := s.getConfig()
+	if cfg == nil || !cfg.Enabled || cfg.ForegroundBoost == nil || !cfg.ForegroundBoost.Enabled {
+		return nil, nil, errors.New("foreground boost is disabled")
+	}
+
+	info, err := getProcInfo(pid)
+	if err != nil {
+		return nil, nil, err
+	}
+	// root 可以为任意用户设置前台进程
+	if uid != 0 && info.uid != uid {
+		return nil, nil, errors.New("permission denied")
+	}
+	return cfg, info, nil
+}
+
+// requestForegroundProcess 检查会话的上报并放入该会话的队列，
+// 每个会话由一个 goroutine 依次处理，处理期间的多次上报只保留最后一次。
+func (s *Scheduler) requestForegroundProcess(sender string, uid uint32, pid int) error {
+	_, _, err := s.checkForegroundProcess(uid, pid)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	req, running := s.requests[sender]
+	if !running {
+		req = &foregroundRequest{}
+		s.requests[sender] = req
+	}
+	req.uid = uid
+	req.pid = pid
+	req.pending = true
+	s.mu.Unlock()
+
+	if !running {
+		go s.runForegroundRequests(sender, req)
+	}
+	return nil
+}
+
+// runForegroundRequests 处理 sender 的上报，两次调整之间至少间隔 foregroundMinInterval，
+// 没有新的上报或者会话已经清除时退出。
+func (s *Scheduler) runForegroundRequests(sender string, req *foregroundRequest) {
+	for {
+		s.mu.Lock()
+		if s.requests[sender] != req {
+			s.mu.Unlock()
+			return
+		}
+		if !req.pending {
+			delete(s.requests, sender)
+			s.mu.Unlock()
+			return
+		}
+		uid, pid := req.uid, req.pid
+		req.pending = false
+		s.mu.Unlock()
+
+		err := s.setForegroundProcess(sender, req, uid, pid)
+		if err != nil {
+			logger.Debugf("set foreground process %d for %s failed: %v", pid, sender, err)
+		}
+		time.Sleep(foregroundMinInterval)
+	}
+}
+
+// setForegroundProcess 提升 pid 所在应用的优先级，并降低同一用户的其他应用
+func (s *Scheduler) setForegroundProcess(sender string, req *foregroundRequest, uid uint32, pid int) error {
+	cfg, info, err := s.checkForegroundProcess(uid, pid)
+	if err != nil {
+		return err
+	}
+
+	app := &foregroundApp{
+		sender: sender,
+		uid:    info.uid,
+		pid:    pid,
+		cgroup: getAppCgroup(info.cgroups, cfg.ForegroundBoost.AppCgroup),
+	}
+
+	s.mu.Lock()
+	// 会话在上报之后已经清除了前台进程
+	if s.requests[sender] != req {
+		s.mu.Unlock()
+		return nil
+	}
+	// 同一个用户只有一个前台应用，以最后一次上报为准
+	for key, old := range s.foreground {
+		if old.uid == app.uid && key != sender {
+			delete(s.foreground, key)
+		}
+	}
+	s.foreground[sender] = app
+	s.mu.Unlock()
+
+	logger.Debugf("foreground process of uid %d: %d, cgroup: %q", app.uid, pid, app.cgroup)
+	return s.updateForegroundBoost(cfg, app)
+}
+
+// clearForegroundProcess 恢复 sender 上报的前台应用对应用户所有被调整的线程
+func (s *Scheduler) clearForegroundProcess(sender string) {
+	s.mu.Lock()
+	delete(s.requests, sender)
+	app, ok := s.foreground[sender]
+	if ok {
+		delete(s.foreground, sender)
+	}
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+	s.restorePriorities(app.uid, nil)
+}
+
+// updateForegroundBoost 遍历 app 所属用户的进程，按类别设置优先级，
+// 不再属于前台或者后台应用的线程恢复原有的优先级。
+func (s *Scheduler) updateForegroundBoost(cfg *config, app *foregroundApp) error {
+	fileInfos, err := readDir("/proc")
+	if err != nil {
+		return err
+	}
+
+	fb := cfg.ForegroundBoost
+	touched := make(map[int]struct{})
+	for _, fileInfo := range fileInfos {
+		pid, err := strconv.Atoi(fileInfo.Name())
+		if err != nil {
+			continue
+		}
+		s.boostProcess(fb, app, pid, touched)
+	}
+	s.restorePriorities(app.uid, touched)
+	return nil
+}
+
+// boostProcess 按进程在 app 中的类别设置进程所有线程的优先级，设置过的线程记录到 touched
+func (s *Scheduler) boostProcess(fb *foregroundBoostCfg, app *foregroundApp, pid int, touched map[int]struct{}) {
+	s.mu.Lock()
+	// 被规则匹配的进程以规则为准
+	_, hasRule := s.applied[pid]
+	s.mu.Unlock()
+	if hasRule {
+		return
+	}
+
+	info, err := getProcInfo(pid)
+	if err != nil {
+		return
+	}
+	class := app.classify(fb, info)
+	if class == boostNone {
+		return
+	}
+
+	nice, ioPrio, hasIO := fb.CPU, fb.ioPrio, fb.IO != nil
+	if class == boostBackground {
+		nice, ioPrio, hasIO = fb.BackgroundCPU, fb.bgIOPrio, fb.BackgroundIO != nil
+	}
+
+	tasks, err := getProcessTasks(pid)
+	if err != nil {
+		return
+	}
+	for _, taskId := range tasks {
+		err = s.savePriority(app.uid, pid, taskId)
+		if err != nil {
+			continue
+		}
+		if touched != nil {
+			touched[taskId] = struct{}{}
+		}
+		err = setCpuPriority(taskId, nice)
+		if err == nil && hasIO {
+			err = setIOPriority(taskId, ioPrio)
+		}
+		if err != nil {
+			logger.Debugf("set priority of task %d (process %d) failed: %v", taskId, pid, err)
+		}
+	}
+}
+
+// savePriority 在第一次调整进程 tgid 的线程前记录其原有的优先级
+func (s *Scheduler) savePriority(uid uint32, tgid, taskId int) error {
+	s.mu.Lock()
+	_, ok := s.saved[taskId]
+	s.mu.Unlock()
+	if ok {
+		return nil
+	}
+
+	nice, err := getCpuPriority(taskId)
+	if err != nil {
+		return err
+	}
+	ioPrio, err := getIOPriority(taskId)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.saved[taskId] = &savedPriority{
+		uid:    uid,
+		tgid:   tgid,
+		nice:   nice,
+		ioPrio: ioPrio,
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// restorePriorities 恢复 uid 用户中不在 keep 里的线程的优先级
+func (s *Scheduler) restorePriorities(uid uint32, keep map[int]struct{}) {
+	s.mu.Lock()
+	var restore = make(map[int]*savedPriority)
+	for taskId, saved := range s.saved {
+		if saved.uid != uid {
+			continue
+		}
+		if _, ok := keep[taskId]; ok {
+			continue
+		}
+		restore[taskId] = saved
+		delete(s.saved, taskId)
+	}
+	s.mu.Unlock()
+
+	for taskId, saved := range restore {
+		// 线程已经退出，tid 可能被其他进程复用
+		if !isProcessTask(saved.tgid, taskId) {
+			continue
+		}
+		err := setCpuPriority(taskId, saved.nice)
+		if err == nil {
+			err = setIOPriority(taskId, saved.ioPrio)
+		}
+		// 线程可能已经退出
+		if err != nil && err != syscall.ESRCH {
+			logger.Debugf("restore priority of task %d failed: %v", taskId, err)
+		}
+	}
+}
+
+// forgetSavedPriorities 删除已退出的进程 tgid 所有线程的记录，调用时需持有 s.mu
+func (s *Scheduler) forgetSavedPriorities(tgid int) {
+	for taskId, saved := range s.saved {
+		if saved.tgid == tgid {
+			delete(s.saved, taskId)
+		}
+	}
+}
+
+// isProcessTask 判断 taskId 是否仍是进程 tgid 的线程
+func isProcessTask(tgid, taskId int) bool {
+	_, err := os.Stat(filepath.Join("/proc", strconv.Itoa(tgid), "task", strconv.Itoa(taskId)))
+	return err == nil
+}
+
+// handleNewProcessForeground 调整前台提升生效期间新启动的进程
+func (s *Scheduler) handleNewProcessForeground(cfg *config, pid int) {
+	fb := cfg.ForegroundBoost
+	if fb == nil || !fb.Enabled {
+		return
+	}
+	s.mu.Lock()
+	apps := make([]*foregroundApp, 0, len(s.foreground))
+	for _, app := range s.foreground {
+		apps = append(apps, app)
+	}
+	s.mu.Unlock()
+
+	for _, app := range apps {
+		s.boostProcess(fb, app, pid, nil)
+	}
+}
+
+// reloadForegroundBoost 在配置重新加载后按新的配置重新调整，前台提升被关闭时恢复所有线程
+func (s *Scheduler) reloadForegroundBoost(cfg *config) {
+	s.mu.Lock()
+	apps := make([]*foregroundApp, 0, len(s.foreground))
+	for _, app := range s.foreground {
+		apps = append(apps, app)
+	}
+	disabled := !cfg.Enabled || cfg.ForegroundBoost == nil || !cfg.ForegroundBoost.Enabled
+	if disabled {
+		s.foreground = make(map[string]*foregroundApp)
+		s.requests = make(map[string]*foregroundRequest)
+	}
+	s.mu.Unlock()
+
+	for _, app := range apps {
+		if disabled {
+			s.restorePriorities(app.uid, nil)
+			continue
+		}
+		updated := *app
+		updated.cgroup = ""
+		info, err := getProcInfo(app.pid)
+		if err == nil {
+			updated.cgroup = getAppCgroup(info.cgroups, cfg.ForegroundBoost.AppCgroup)
+		}
+		s.mu.Lock()
+		if s.foreground[app.sender] == app {
+			s.foreground[app.sender] = &updated
+		}
+		s.mu.Unlock()
+		err = s.updateForegroundBoost(cfg, &updated)
+		if err != nil {
+			logger.Warning(err)
+		}
+	}
+}
+
+// handleNameOwnerChanged 在上报前台进程的会话断开后恢复该用户的优先级
+func (s *Scheduler) handleNameOwnerChanged(name, oldOwner, newOwner string) {
+	if strings.HasPrefix(name, ":") && oldOwner != "" && newOwner == "" {
+		s.clearForegroundProcess(name)
+	}
+}
+
+// 获取线程的 nice 值，系统调用返回的是 20 - nice
+func getCpuPriority(taskId int) (int, error) {
+	prio, err := syscall.Getpriority(syscall.PRIO_PROCESS, taskId)
+	if err != nil {
+		return 0, err
+	}
+	return 20 - prio, nil
+}
+
+// 获取线程的 io 优先级，返回 class 与 level 组合后的值
+func getIOPriority(taskId int) (int, error) {
+	r, _, errno := syscall.Syscall(syscall.SYS_IOPRIO_GET, ioPrioWhoProcess, uintptr(taskId), 0)
+	if errno != 0 {
+		return 0, errno
+	}
+	return int(r), nil
+}
+
+// checkAuthorization 检查调用者是否处于活动会话，不弹出认证对话框
+func checkAuthorization(actionId string, sysBusName string) error {
+	systemBus, err := dbus.SystemBus()
+	if err != nil {
+		return err
+	}
+	authority := polkit.NewAuthority(systemBus)
+	subject := polkit.MakeSubject(polkit.SubjectKindSystemBusName)
+	subject.SetDetail("name", sysBusName)
+
+	ret, err := authority.CheckAuthorization(0, subject, actionId,
+		nil, polkit.CheckAuthorizationFlagsNone, "")
+	if err != nil {
+		return err
+	}
+	if !ret.IsAuthorized {
+		return errors.New("not authorized")
+	}
+	return nil
+}
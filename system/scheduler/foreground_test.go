@@ -0,0 +1,89 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package scheduler
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForegroundBoostConfig(t *testing.T) {
+	var cfg config
+	require.NoError(t, json.Unmarshal([]byte(`{
+  "enabled": true,
+  "foregroundBoost": {
+    "enabled": true,
+    "cpu": -5,
+    "io": {"class": "best-effort", "level": 2},
+    "backgroundCpu": 5,
+    "backgroundIo": {"class": "idle"}
+  }
+}`), &cfg))
+	require.NoError(t, cfg.compile())
+
+	fb := cfg.ForegroundBoost
+	assert.Equal(t, defaultAppCgroup, fb.AppCgroup)
+	assert.Equal(t, ioPrioClassBestEffort<<ioPrioClassShift|2, fb.ioPrio)
+	assert.Equal(t, ioPrioClassIdle<<ioPrioClassShift, fb.bgIOPrio)
+
+	for _, invalid := range []string{
+		`{"foregroundBoost": {"cpu": -21}}`,
+		`{"foregroundBoost": {"backgroundCpu": 20}}`,
+		`{"foregroundBoost": {"backgroundIo": {"level": 8}}}`,
+		`{"foregroundBoost": {"appCgroup": "["}}`,
+	} {
+		var cfg config
+		require.NoError(t, json.Unmarshal([]byte(invalid), &cfg))
+		assert.Error(t, cfg.compile(), invalid)
+	}
+}
+
+func TestForegroundAppClassify(t *testing.T) {
+	fb := &foregroundBoostCfg{AppCgroup: defaultAppCgroup}
+	app := &foregroundApp{
+		uid:    1000,
+		pid:    100,
+		cgroup: "/user.slice/user-1000.slice/user@1000.service/app.slice/app-dde-editor.scope",
+	}
+
+	assert.Equal(t, boostForeground, app.classify(fb, &procInfo{pid: 100, uid: 1000}))
+	assert.Equal(t, boostForeground, app.classify(fb, &procInfo{
+		pid:     101,
+		uid:     1000,
+		cgroups: []string{"/user.slice/user-1000.slice/user@1000.service/app.slice/app-dde-editor.scope"},
+	}))
+	assert.Equal(t, boostBackground, app.classify(fb, &procInfo{
+		pid:     200,
+		uid:     1000,
+		cgroups: []string{"/user.slice/user-1000.slice/user@1000.service/app.slice/app-browser.scope"},
+	}))
+	// 会话服务等不在应用 cgroup 中的进程不调整
+	assert.Equal(t, boostNone, app.classify(fb, &procInfo{
+		pid:     300,
+		uid:     1000,
+		cgroups: []string{"/user.slice/user-1000.slice/user@1000.service/dde-session-daemon.service"},
+	}))
+	// 其他用户的进程不调整
+	assert.Equal(t, boostNone, app.classify(fb, &procInfo{
+		pid:     400,
+		uid:     1001,
+		cgroups: []string{"/user.slice/user-1001.slice/user@1001.service/app.slice/app-browser.scope"},
+	}))
+}
+
+func TestForgetSavedPriorities(t *testing.T) {
+	s := newScheduler(nil)
+	s.saved[100] = &savedPriority{uid: 1000, tgid: 100}
+	s.saved[101] = &savedPriority{uid: 1000, tgid: 100}
+	s.saved[200] = &savedPriority{uid: 1000, tgid: 200}
+
+	// 进程退出后删除其所有线程的记录
+	s.forgetSavedPriorities(100)
+	assert.Len(t, s.saved, 1)
+	assert.Contains(t, s.saved, 200)
+}
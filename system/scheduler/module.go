@@ -17,6 +17,7 @@ func init() {
 
 type Module struct {
 	*loader.ModuleBase
+	scheduler *Scheduler
 }
 
 func (m *Module) GetDependencies() []string {
@@ -24,11 +25,40 @@ func (m *Module) GetDependencies() []string {
 }
 
 func (m *Module) Start() error {
-	err := start()
-	return err
+	if m.scheduler != nil {
+		return nil
+	}
+	service := loader.GetService()
+	m.scheduler = newScheduler(service)
+	m.scheduler.start()
+
+	err := service.Export(dbusPath, m.scheduler)
+	if err != nil {
+		return err
+	}
+
+	err = service.RequestName(dbusServiceName)
+	if err != nil {
+		return err
+	}
+	return nil
 }
 
 func (m *Module) Stop() error {
+	if m.scheduler == nil {
+		return nil
+	}
+	m.scheduler.stop()
+	service := loader.GetService()
+	err := service.ReleaseName(dbusServiceName)
+	if err != nil {
+		logger.Warning(err)
+	}
+	err = service.StopExport(m.scheduler)
+	if err != nil {
+		logger.Warning(err)
+	}
+	m.scheduler = nil
 	return nil
 }
 
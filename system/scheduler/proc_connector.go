@@ -185,8 +185,8 @@ func (pm *procMonitor) handleExitEvent(ev *ExitProcEvent) {
 	pm.mu.Unlock()
 }
 
-// 获取活着的进程列表
-func (pm *procMonitor) getAlivePids() []uint32 {
+// 获取活着的进程列表，以及期间退出的进程列表
+func (pm *procMonitor) getAlivePids() (pids []uint32, exitPids []uint32) {
 	logger.Debug("proc monitor handle events")
 
 	pm.mu.Lock()
@@ -195,7 +195,6 @@ func (pm *procMonitor) getAlivePids() []uint32 {
 	// logger.Debug("proc monitor execPids:", pm.execPids)
 	// logger.Debug("proc monitor exitPids:", pm.exitPids)
 
-	var pids []uint32
 	for _, execPid := range pm.execPids {
 		// 取出掉已经退出的
 		exited := false
@@ -210,11 +209,13 @@ func (pm *procMonitor) getAlivePids() []uint32 {
 		}
 	}
 	logger.Debug("proc monitor need handle pids:", pids)
+	exitPids = make([]uint32, len(pm.exitPids))
+	copy(exitPids, pm.exitPids)
 
 	// 清空，但保持容量
 	pm.execPids = pm.execPids[0:0:cap(pm.execPids)]
 	pm.exitPids = pm.exitPids[0:0:cap(pm.exitPids)]
-	return pids
+	return pids, exitPids
 }
 
 // 解析含有进程事件的消息
@@ -0,0 +1,269 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package scheduler
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"github.com/linuxdeepin/go-lib/procfs"
+)
+
+const (
+	ioPrioClassRealtime   = 1
+	ioPrioClassBestEffort = 2
+	ioPrioClassIdle       = 3
+
+	ioPrioClassShift = 13
+	ioPrioWhoProcess = 1
+)
+
+type rule struct {
+	cfg       *ruleCfg
+	cmdlineRe *regexp.Regexp
+	ioPrio    int
+}
+
+// procInfo 是匹配规则时用到的进程信息
+type procInfo struct {
+	pid     int
+	exe     string
+	cmdline string
+	uid     uint32
+	cgroups []string
+}
+
+func newRule(cfg *ruleCfg) (*rule, error) {
+	r := &rule{cfg: cfg}
+	m := cfg.Match
+	if m.Exe == "" && m.Cmdline == "" && m.UID == nil && m.Cgroup == "" {
+		return nil, errors.New("empty match")
+	}
+	if m.Exe != "" {
+		if _, err := filepath.Match(m.Exe, ""); err != nil {
+			return nil, fmt.Errorf("invalid exe pattern: %w", err)
+		}
+	}
+	if m.Cgroup != "" {
+		if _, err := filepath.Match(m.Cgroup, ""); err != nil {
+			return nil, fmt.Errorf("invalid cgroup pattern: %w", err)
+		}
+	}
+	if m.Cmdline != "" {
+		re, err := regexp.Compile(m.Cmdline)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cmdline regexp: %w", err)
+		}
+		r.cmdlineRe = re
+	}
+
+	if cfg.CPU != nil && (*cfg.CPU < -20 || *cfg.CPU > 19) {
+		return nil, fmt.Errorf("invalid cpu priority %d", *cfg.CPU)
+	}
+	if cfg.IO != nil {
+		ioPrio, err := cfg.IO.value()
+		if err != nil {
+			return nil, err
+		}
+		r.ioPrio = ioPrio
+	}
+	for _, cpu := range cfg.CPUAffinity {
+		if cpu < 0 || cpu >= cpuSetSize {
+			return nil, fmt.Errorf("invalid cpu %d in cpuAffinity", cpu)
+		}
+	}
+	if cfg.OOMScoreAdj != nil && (*cfg.OOMScoreAdj < -1000 || *cfg.OOMScoreAdj > 1000) {
+		return nil, fmt.Errorf("invalid oomScoreAdj %d", *cfg.OOMScoreAdj)
+	}
+	return r, nil
+}
+
+func (r *rule) name() string {
+	if r.cfg.Name != "" {
+		return r.cfg.Name
+	}
+	return r.cfg.Match.Exe
+}
+
+func (r *rule) match(info *procInfo) bool {
+	m := r.cfg.Match
+	if m.Exe != "" {
+		target := info.exe
+		if !strings.Contains(m.Exe, "/") {
+			target = filepath.Base(info.exe)
+		}
+		if ok, _ := filepath.Match(m.Exe, target); !ok {
+			return false
+		}
+	}
+	if m.UID != nil && *m.UID != info.uid {
+		return false
+	}
+	if r.cmdlineRe != nil && !r.cmdlineRe.MatchString(info.cmdline) {
+		return false
+	}
+	if m.Cgroup != "" {
+		matched := false
+		for _, cgroup := range info.cgroups {
+			if ok, _ := filepath.Match(m.Cgroup, cgroup); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// apply 将规则中的策略应用到进程，nice、io 优先级和 cpu 亲和性作用于进程的所有线程
+func (r *rule) apply(pid int) error {
+	if r.cfg.OOMScoreAdj != nil {
+		err := setOOMScoreAdj(pid, *r.cfg.OOMScoreAdj)
+		if err != nil {
+			return err
+		}
+	}
+
+	if r.cfg.CPU == nil && r.cfg.IO == nil && len(r.cfg.CPUAffinity) == 0 {
+		return nil
+	}
+	tasks, err := getProcessTasks(pid)
+	if err != nil {
+		return err
+	}
+	for _, taskId := range tasks {
+		if r.cfg.CPU != nil {
+			err = setCpuPriority(taskId, *r.cfg.CPU)
+			if err != nil {
+				return err
+			}
+		}
+		if r.cfg.IO != nil {
+			err = setIOPriority(taskId, r.ioPrio)
+			if err != nil {
+				return err
+			}
+		}
+		if len(r.cfg.CPUAffinity) > 0 {
+			err = setCPUAffinity(taskId, r.cfg.CPUAffinity)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *ioPriorityCfg) value() (int, error) {
+	if c.Level < 0 || c.Level > 7 {
+		return 0, fmt.Errorf("invalid io priority level %d", c.Level)
+	}
+	switch c.Class {
+	case "realtime":
+		return ioPrioClassRealtime<<ioPrioClassShift | c.Level, nil
+	case "best-effort", "":
+		return ioPrioClassBestEffort<<ioPrioClassShift | c.Level, nil
+	case "idle":
+		return ioPrioClassIdle << ioPrioClassShift, nil
+	}
+	return 0, fmt.Errorf("invalid io priority class %q", c.Class)
+}
+
+// 获取匹配规则所需的进程信息
+func getProcInfo(pid int) (*procInfo, error) {
+	p := procfs.Process(pid)
+	exe, err := p.Exe()
+	if err != nil {
+		// 有些无法获取 exe, 比如内核线程 kworker/2:1-events
+		return nil, err
+	}
+	info := &procInfo{
+		pid: pid,
+		exe: exe,
+	}
+
+	cmdline, err := p.Cmdline()
+	if err == nil {
+		info.cmdline = strings.Join(cmdline, " ")
+	}
+
+	status, err := p.Status()
+	if err != nil {
+		return nil, err
+	}
+	uids, err := status.Uids()
+	if err != nil {
+		return nil, err
+	}
+	if len(uids) > 0 {
+		info.uid = uint32(uids[0])
+	}
+
+	info.cgroups, err = getProcessCgroups(pid)
+	if err != nil {
+		logger.Debugf("get cgroups of process %d failed: %v", pid, err)
+	}
+	return info, nil
+}
+
+// 读取 /proc/<pid>/cgroup 中的 cgroup 路径
+func getProcessCgroups(pid int) ([]string, error) {
+	f, err := os.Open(filepath.Join("/proc", strconv.Itoa(pid), "cgroup"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var result []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// 格式为 hierarchy-ID:controller-list:cgroup-path
+		fields := strings.SplitN(scanner.Text(), ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		result = append(result, fields[2])
+	}
+	return result, scanner.Err()
+}
+
+func setOOMScoreAdj(pid int, value int) error {
+	filename := filepath.Join("/proc", strconv.Itoa(pid), "oom_score_adj")
+	return os.WriteFile(filename, []byte(strconv.Itoa(value)), 0644)
+}
+
+// 设置线程的 io 优先级，ioPrio 为 class 与 level 组合后的值
+func setIOPriority(taskId int, ioPrio int) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOPRIO_SET, ioPrioWhoProcess, uintptr(taskId), uintptr(ioPrio))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+const cpuSetSize = 1024
+
+func setCPUAffinity(taskId int, cpus []int) error {
+	var mask [cpuSetSize / 64]uint64
+	for _, cpu := range cpus {
+		mask[cpu/64] |= 1 << (uint(cpu) % 64)
+	}
+	_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_SETAFFINITY, uintptr(taskId),
+		unsafe.Sizeof(mask), uintptr(unsafe.Pointer(&mask)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
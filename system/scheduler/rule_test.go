@@ -0,0 +1,94 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package scheduler
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `{
+  "enabled": true,
+  "processes": {
+    "/usr/bin/deepin-anything-tool": {"cpu": 19},
+    "dde-file-manager-daemon": {"cpu": 10}
+  },
+  "rules": [
+    {
+      "name": "root-backup",
+      "match": {"exe": "rsync", "uid": 0},
+      "io": {"class": "idle"}
+    },
+    {
+      "name": "browser-renderer",
+      "match": {"exe": "/usr/lib/*/chrome", "cmdline": "--type=renderer"},
+      "cpu": 5,
+      "oomScoreAdj": 300
+    },
+    {
+      "name": "apps",
+      "match": {"cgroup": "/user.slice/*/app.slice/*"},
+      "cpuAffinity": [0, 1]
+    }
+  ]
+}`
+
+func TestConfigMatchRule(t *testing.T) {
+	var cfg config
+	require.NoError(t, json.Unmarshal([]byte(testConfig), &cfg))
+	require.NoError(t, cfg.compile())
+
+	ruleName := func(info *procInfo) string {
+		r := cfg.matchRule(info)
+		if r == nil {
+			return ""
+		}
+		return r.name()
+	}
+
+	assert.Equal(t, "root-backup", ruleName(&procInfo{exe: "/usr/bin/rsync", uid: 0}))
+	assert.Equal(t, "", ruleName(&procInfo{exe: "/usr/bin/rsync", uid: 1000}))
+	assert.Equal(t, "browser-renderer", ruleName(&procInfo{
+		exe:     "/usr/lib/chromium/chrome",
+		cmdline: "/usr/lib/chromium/chrome --type=renderer --lang=en",
+	}))
+	assert.Equal(t, "", ruleName(&procInfo{
+		exe:     "/usr/lib/chromium/chrome",
+		cmdline: "/usr/lib/chromium/chrome --type=gpu-process",
+	}))
+	assert.Equal(t, "apps", ruleName(&procInfo{
+		exe:     "/usr/bin/deepin-editor",
+		cgroups: []string{"/user.slice/user-1000.slice/app.slice/deepin-editor.scope"},
+	}))
+	assert.Equal(t, "/usr/bin/deepin-anything-tool", ruleName(&procInfo{exe: "/usr/bin/deepin-anything-tool"}))
+	assert.Equal(t, "dde-file-manager-daemon", ruleName(&procInfo{exe: "/usr/libexec/dde-file-manager-daemon"}))
+}
+
+func TestConfigCompileInvalid(t *testing.T) {
+	for _, content := range []string{
+		`{"rules": [{"match": {}}]}`,
+		`{"rules": [{"match": {"cmdline": "("}}]}`,
+		`{"rules": [{"match": {"exe": "a"}, "cpu": 20}]}`,
+		`{"rules": [{"match": {"exe": "a"}, "io": {"class": "fast"}}]}`,
+		`{"rules": [{"match": {"exe": "a"}, "oomScoreAdj": 2000}]}`,
+	} {
+		var cfg config
+		require.NoError(t, json.Unmarshal([]byte(content), &cfg))
+		assert.Error(t, cfg.compile(), content)
+	}
+}
+
+func TestIOPriorityValue(t *testing.T) {
+	v, err := (&ioPriorityCfg{Class: "best-effort", Level: 7}).value()
+	assert.NoError(t, err)
+	assert.Equal(t, 2<<13|7, v)
+
+	v, err = (&ioPriorityCfg{Class: "idle", Level: 3}).value()
+	assert.NoError(t, err)
+	assert.Equal(t, 3<<13, v)
+}
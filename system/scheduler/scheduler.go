@@ -8,19 +8,117 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"sync"
 	"syscall"
 	"time"
 
-	"github.com/linuxdeepin/go-lib/procfs"
+	"github.com/fsnotify/fsnotify"
+	ofdbus "github.com/linuxdeepin/go-dbus-factory/system/org.freedesktop.dbus"
+	"github.com/linuxdeepin/go-lib/dbusutil"
 )
 
+//go:generate dbusutil-gen em -type Scheduler
+
+const (
+	dbusServiceName = "org.deepin.dde.Scheduler1"
+	dbusPath        = "/org/deepin/dde/Scheduler1"
+	dbusInterface   = dbusServiceName
+)
+
+type Scheduler struct {
+	service *dbusutil.Service
+
+	mu  sync.Mutex
+	cfg *config
+	// pid -> 应用到该进程的规则
+	applied map[int]*appliedRule
+	// 会话的 dbus 连接名 -> 该会话上报的前台应用
+	foreground map[string]*foregroundApp
+	// 会话的 dbus 连接名 -> 该会话尚未处理的前台进程上报
+	requests map[string]*foregroundRequest
+	// tid -> 前台提升调整之前的优先级
+	saved map[int]*savedPriority
+
+	sigLoop    *dbusutil.SignalLoop
+	dbusDaemon ofdbus.DBus
+
+	pm            *procMonitor
+	pmStartOnce   sync.Once
+	watcher       *fsnotify.Watcher
+	reloadTimer   *time.Timer
+	updateAllStop chan struct{}
+}
+
+// appliedRule 记录某条规则被应用到了哪个进程
+type appliedRule struct {
+	Pid  uint32
+	Exe  string
+	Rule string
+	// 应用规则的时间，unix 时间戳（秒）
+	Time  int64
+	Error string
+}
+
+func newScheduler(service *dbusutil.Service) *Scheduler {
+	s := &Scheduler{
+		service:    service,
+		applied:    make(map[int]*appliedRule),
+		foreground: make(map[string]*foregroundApp),
+		requests:   make(map[string]*foregroundRequest),
+		saved:      make(map[int]*savedPriority),
+	}
+	s.pm = newProcMonitor(s.handleProcEvents)
+	if service != nil {
+		s.sigLoop = dbusutil.NewSignalLoop(service.Conn(), 10)
+		s.dbusDaemon = ofdbus.NewDBus(service.Conn())
+	}
+	return s
+}
+
+func (*Scheduler) GetInterfaceName() string {
+	return dbusInterface
+}
+
+func (s *Scheduler) getConfig() *config {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cfg
+}
+
+// 处理 proc connector 收集到的进程事件
+func (s *Scheduler) handleProcEvents() {
+	pids, exitPids := s.pm.getAlivePids()
+
+	s.mu.Lock()
+	for _, pid := range exitPids {
+		delete(s.applied, int(pid))
+		s.forgetSavedPriorities(int(pid))
+	}
+	s.mu.Unlock()
+
+	cfg := s.getConfig()
+	if cfg == nil || !cfg.Enabled {
+		return
+	}
+	for _, pid := range pids {
+		s.setProcessPriority(cfg, int(pid))
+		s.handleNewProcessForeground(cfg, int(pid))
+	}
+}
+
 // 遍历所有进程, 设置优先级
-func updateProcessesPriority(cfg *config) error {
+func (s *Scheduler) updateProcessesPriority() error {
+	cfg := s.getConfig()
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+
 	fileInfos, err := readDir("/proc")
 	if err != nil {
 		return err
 	}
 
+	alive := make(map[int]struct{}, len(fileInfos))
 	for _, fileInfo := range fileInfos {
 		name := fileInfo.Name()
 		pid, err := strconv.Atoi(name)
@@ -28,51 +126,196 @@ func updateProcessesPriority(cfg *config) error {
 			continue
 		}
 
-		setProcessPriority(cfg, pid)
+		alive[pid] = struct{}{}
+		s.setProcessPriority(cfg, pid)
 	}
+
+	s.mu.Lock()
+	for pid := range s.applied {
+		if _, ok := alive[pid]; !ok {
+			delete(s.applied, pid)
+		}
+	}
+	s.mu.Unlock()
 	return nil
 }
 
 // 设置进程优先级
-func setProcessPriority(cfg *config, pid int) {
-	exe, err := getProcessExe(pid)
+func (s *Scheduler) setProcessPriority(cfg *config, pid int) {
+	info, err := getProcInfo(pid)
 	if err != nil {
-		// 有些无法获取 exe, 比如内核线程 kworker/2:1-events
 		return
 	}
-	pCfg := cfg.getPriority(exe)
-	if pCfg == nil {
+	r := cfg.matchRule(info)
+	if r == nil {
 		// 无配置
+		s.mu.Lock()
+		delete(s.applied, pid)
+		s.mu.Unlock()
 		return
 	}
 	// 仅在有配置时设置优先级
-	err = setProcessCpuPriority(pid, pCfg.CPU)
+	record := &appliedRule{
+		Pid:  uint32(pid),
+		Exe:  info.exe,
+		Rule: r.name(),
+		Time: time.Now().Unix(),
+	}
+	err = r.apply(pid)
 	if err != nil {
-		logger.Warningf("set priority for process %d (exe: %v) failed: %v", pid, exe, err)
+		logger.Warningf("apply rule %q for process %d (exe: %v) failed: %v", r.name(), pid, info.exe, err)
+		record.Error = err.Error()
 	}
+
+	s.mu.Lock()
+	s.applied[pid] = record
+	s.mu.Unlock()
 }
 
-// 获取进程可执行文件路径
-func getProcessExe(pid int) (string, error) {
-	exe, err := procfs.Process(pid).Exe()
-	return exe, err
+// 加载配置，并在配置发生变化后重新设置所有进程的优先级
+func (s *Scheduler) reloadConfig() {
+	cfg, err := loadConfig()
+	if err != nil {
+		logger.Warning("load config failed:", err)
+		return
+	}
+	logger.Debug("load config file:", cfg.filename)
+
+	s.mu.Lock()
+	s.cfg = cfg
+	s.applied = make(map[int]*appliedRule)
+	s.mu.Unlock()
+
+	if !cfg.Enabled {
+		logger.Info("scheduler module is disabled")
+		s.reloadForegroundBoost(cfg)
+		return
+	}
+
+	if cfg.ProcMonitorEnabled {
+		s.pmStartOnce.Do(func() {
+			go func() {
+				err := s.pm.listenProcEvents()
+				if err != nil {
+					logger.Warning(err)
+				}
+			}()
+		})
+	}
+
+	err = s.updateProcessesPriority()
+	if err != nil {
+		logger.Warning("updateProcessesPriority err:", err)
+	}
+	s.reloadForegroundBoost(cfg)
 }
 
-// 设置进程的 cpu 优先级（nice），包括所有线程
-func setProcessCpuPriority(pid int, priority int) error {
-	tasks, err := getProcessTasks(pid)
+// 监控配置文件所在的目录，配置文件变化后延迟重新加载
+func (s *Scheduler) watchConfig() error {
+	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		return err
 	}
-	for _, taskId := range tasks {
-		err = setCpuPriority(taskId, priority)
+	s.watcher = watcher
+
+	for _, file := range []string{userConfigFile, systemConfigFile} {
+		dir := filepath.Dir(file)
+		err = watcher.Add(dir)
 		if err != nil {
-			return err
+			logger.Debugf("watch %s failed: %v", dir, err)
 		}
 	}
+
+	go func() {
+		for {
+			select {
+			case ev, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if ev.Name != userConfigFile && ev.Name != systemConfigFile {
+					continue
+				}
+				logger.Debug("config file changed:", ev)
+				s.mu.Lock()
+				if s.reloadTimer == nil {
+					s.reloadTimer = time.AfterFunc(configReloadDelay, s.reloadConfig)
+				} else {
+					s.reloadTimer.Reset(configReloadDelay)
+				}
+				s.mu.Unlock()
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logger.Warning(err)
+			}
+		}
+	}()
 	return nil
 }
 
+func (s *Scheduler) start() {
+	s.sigLoop.Start()
+	s.dbusDaemon.InitSignalExt(s.sigLoop, true)
+	_, err := s.dbusDaemon.ConnectNameOwnerChanged(s.handleNameOwnerChanged)
+	if err != nil {
+		logger.Warning(err)
+	}
+
+	s.reloadConfig()
+
+	err = s.watchConfig()
+	if err != nil {
+		logger.Warning("watch config failed:", err)
+	}
+
+	ticker := time.NewTicker(time.Second * updateAllIntervalSec)
+	s.updateAllStop = make(chan struct{})
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				err := s.updateProcessesPriority()
+				if err != nil {
+					logger.Warning("updateProcessesPriority err:", err)
+				}
+			case <-s.updateAllStop:
+				return
+			}
+		}
+	}()
+}
+
+func (s *Scheduler) stop() {
+	if s.updateAllStop != nil {
+		close(s.updateAllStop)
+		s.updateAllStop = nil
+	}
+	if s.watcher != nil {
+		_ = s.watcher.Close()
+		s.watcher = nil
+	}
+	s.mu.Lock()
+	if s.reloadTimer != nil {
+		s.reloadTimer.Stop()
+	}
+	uids := make(map[uint32]struct{})
+	for _, saved := range s.saved {
+		uids[saved.uid] = struct{}{}
+	}
+	s.foreground = make(map[string]*foregroundApp)
+	s.requests = make(map[string]*foregroundRequest)
+	s.mu.Unlock()
+
+	for uid := range uids {
+		s.restorePriorities(uid, nil)
+	}
+	s.dbusDaemon.RemoveAllHandlers()
+	s.sigLoop.Stop()
+}
+
 // 获取进程的所有线程 id, 包括自身。
 func getProcessTasks(pid int) ([]int, error) {
 	fileInfos, err := readDir(filepath.Join("/proc", strconv.Itoa(pid), "task"))
@@ -117,50 +360,5 @@ const handleProcEventsIntervalSec = 5
 // 更新所有进程优先级的周期间隔
 const updateAllIntervalSec = 90
 
-// 模块入口
-func start() error {
-	cfg, err := loadConfig()
-	if err != nil {
-		logger.Warning("load config failed:", err)
-		return nil
-	}
-	logger.Debug("load config file:", cfg.filename)
-
-	if !cfg.Enabled {
-		logger.Info("scheduler module is disabled")
-		return nil
-	}
-
-	var pm *procMonitor
-	if cfg.ProcMonitorEnabled {
-		pm = newProcMonitor(func() {
-			// 定时器回调函数
-			pids := pm.getAlivePids()
-			for _, pid := range pids {
-				setProcessPriority(cfg, int(pid))
-			}
-		})
-		go func() {
-			err := pm.listenProcEvents()
-			if err != nil {
-				logger.Warning(err)
-			}
-		}()
-	}
-
-	err = updateProcessesPriority(cfg)
-	if err != nil {
-		logger.Warning("updateProcessesPriority err:", err)
-	}
-	ticker := time.NewTicker(time.Second * updateAllIntervalSec)
-	go func() {
-		for range ticker.C {
-			err := updateProcessesPriority(cfg)
-			if err != nil {
-				logger.Warning("updateProcessesPriority err:", err)
-			}
-		}
-	}()
-
-	return nil
-}
+// 配置文件变化后重新加载的延迟
+const configReloadDelay = time.Second
@@ -0,0 +1,70 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/linuxdeepin/go-lib/dbusutil"
+)
+
+// ListAppliedRules 以 JSON 数组返回所有被规则匹配的进程及应用的规则
+func (s *Scheduler) ListAppliedRules() (rules string, busErr *dbus.Error) {
+	s.mu.Lock()
+	list := make([]*appliedRule, 0, len(s.applied))
+	for _, record := range s.applied {
+		list = append(list, record)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Pid < list[j].Pid
+	})
+	data, err := json.Marshal(list)
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	return string(data), nil
+}
+
+// GetProcessRule 以 JSON 对象返回应用到进程 pid 的规则
+func (s *Scheduler) GetProcessRule(pid uint32) (rule string, busErr *dbus.Error) {
+	s.mu.Lock()
+	record, ok := s.applied[int(pid)]
+	s.mu.Unlock()
+	if !ok {
+		return "", dbusutil.ToError(fmt.Errorf("no rule applied to process %d", pid))
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", dbusutil.ToError(err)
+	}
+	return string(data), nil
+}
+
+// SetForegroundProcess 由会话在焦点窗口变化时调用，提升 pid 所在应用的优先级，
+// 降低同一用户的其他应用，之前调整过的进程恢复原有的优先级。只允许活动会话调用，调整是异步进行的。
+func (s *Scheduler) SetForegroundProcess(sender dbus.Sender, pid uint32) *dbus.Error {
+	uid, err := s.service.GetConnUID(string(sender))
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+	err = checkAuthorization(polkitActionSetForegroundProcess, string(sender))
+	if err != nil {
+		return dbusutil.ToError(err)
+	}
+	err = s.requestForegroundProcess(string(sender), uid, int(pid))
+	return dbusutil.ToError(err)
+}
+
+// ClearForegroundProcess 恢复调用者通过 SetForegroundProcess 调整过的所有进程的优先级
+func (s *Scheduler) ClearForegroundProcess(sender dbus.Sender) *dbus.Error {
+	s.clearForegroundProcess(string(sender))
+	return nil
+}
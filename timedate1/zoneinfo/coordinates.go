@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package zoneinfo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Coordinates is the location of the principal city of a timezone, in degrees
+type Coordinates struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// GetZoneCoordinates query the coordinates of timezone from zone1970.tab
+func GetZoneCoordinates(zone string) (*Coordinates, error) {
+	return getZoneCoordinatesFromFile(deepinDefaultZoneTab, zone)
+}
+
+func getZoneCoordinatesFromFile(file, zone string) (*Coordinates, error) {
+	lines, err := getUncommentedZoneLines(file)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range lines {
+		strv := strings.Split(line, "\t")
+		if len(strv) < 3 || strv[2] != zone {
+			continue
+		}
+		return parseISO6709(strv[1])
+	}
+	return nil, ErrZoneInvalid
+}
+
+// parseISO6709 parse coordinates in the form ±DDMM±DDDMM or ±DDMMSS±DDDMMSS
+func parseISO6709(s string) (*Coordinates, error) {
+	if len(s) == 0 {
+		return nil, fmt.Errorf("invalid coordinates %q", s)
+	}
+	idx := strings.IndexAny(s[1:], "+-")
+	if idx == -1 {
+		return nil, fmt.Errorf("invalid coordinates %q", s)
+	}
+	idx++
+
+	latitude, err := parseISO6709Part(s[:idx], 2)
+	if err != nil {
+		return nil, err
+	}
+	longitude, err := parseISO6709Part(s[idx:], 3)
+	if err != nil {
+		return nil, err
+	}
+	return &Coordinates{Latitude: latitude, Longitude: longitude}, nil
+}
+
+func parseISO6709Part(s string, degreeDigits int) (float64, error) {
+	if len(s) != 1+degreeDigits+2 && len(s) != 1+degreeDigits+4 {
+		return 0, fmt.Errorf("invalid coordinate %q", s)
+	}
+	sign := 1.0
+	switch s[0] {
+	case '-':
+		sign = -1.0
+	case '+':
+	default:
+		return 0, fmt.Errorf("invalid coordinate %q", s)
+	}
+
+	digits := s[1:]
+	var values []float64
+	for _, part := range []string{digits[:degreeDigits], digits[degreeDigits : degreeDigits+2], digits[degreeDigits+2:]} {
+		if part == "" {
+			values = append(values, 0)
+			continue
+		}
+		v, err := strconv.Atoi(part)
+		if err != nil {
+			return 0, fmt.Errorf("invalid coordinate %q", s)
+		}
+		values = append(values, float64(v))
+	}
+	// 分和秒必须小于 60，避免把十进制度数当作度分解析
+	if values[1] >= 60 || values[2] >= 60 {
+		return 0, fmt.Errorf("invalid coordinate %q", s)
+	}
+	return sign * (values[0] + values[1]/60 + values[2]/3600), nil
+}
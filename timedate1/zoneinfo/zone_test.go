@@ -7,6 +7,7 @@ package zoneinfo
 import (
 	"os"
 	"path"
+	"strings"
 	"testing"
 
 	dutils "github.com/linuxdeepin/go-lib/utils"
@@ -176,3 +177,43 @@ func (*testWrapper) TestGetZoneInfo(c *C.C) {
 		c.Check(*zoneInfo, C.Equals, info)
 	}
 }
+
+func (*testWrapper) TestGetZoneCoordinates(c *C.C) {
+	coord, err := getZoneCoordinatesFromFile("testdata/zone1970.tab", "Asia/Dubai")
+	c.Check(err, C.Equals, nil)
+	c.Check(*coord, C.Equals, Coordinates{Latitude: 25.3, Longitude: 55.3})
+
+	_, err = getZoneCoordinatesFromFile("testdata/zone1970.tab", "Asia/Shanghai")
+	c.Check(err, C.Equals, ErrZoneInvalid)
+
+	coord, err = parseISO6709("-332830-0703845")
+	c.Check(err, C.Equals, nil)
+	c.Check(coord.Latitude < -33.47 && coord.Latitude > -33.48, C.Equals, true)
+	c.Check(coord.Longitude < -70.64 && coord.Longitude > -70.65, C.Equals, true)
+
+	_, err = parseISO6709("+4230")
+	c.Check(err, C.NotNil)
+
+	// 分大于等于 60 的十进制度数不是合法的坐标
+	_, err = parseISO6709("+3992+11646")
+	c.Check(err, C.NotNil)
+	_, err = parseISO6709("+395560+1162800")
+	c.Check(err, C.NotNil)
+
+	coord, err = getZoneCoordinatesFromFile("../../misc/zoneinfo/zone1970.tab", "Asia/Beijing")
+	c.Check(err, C.Equals, nil)
+	c.Check(coord.Latitude > 39.9 && coord.Latitude < 39.95, C.Equals, true)
+	c.Check(coord.Longitude > 116.4 && coord.Longitude < 116.5, C.Equals, true)
+
+	// 系统使用的 zone1970.tab 中所有的坐标都必须能够解析
+	lines, err := getUncommentedZoneLines("../../misc/zoneinfo/zone1970.tab")
+	c.Check(err, C.Equals, nil)
+	for _, line := range lines {
+		strv := strings.Split(line, "\t")
+		if len(strv) < 3 {
+			continue
+		}
+		_, err = parseISO6709(strv[1])
+		c.Check(err, C.Equals, nil, C.Commentf("zone %s", strv[2]))
+	}
+}
@@ -29,6 +29,7 @@ func init() {
 
 type Daemon struct {
 	*loader.ModuleBase
+	manager *Manager
 }
 
 func NewDaemon(logger *log.Logger) *Daemon {
@@ -53,6 +54,7 @@ func (d *Daemon) Start() error {
 		return err
 	}
 	m.initXExtensions()
+	d.manager = m
 
 	sessionType := os.Getenv("XDG_SESSION_TYPE")
 	if strings.Contains(sessionType, "wayland") {
@@ -60,7 +62,11 @@ func (d *Daemon) Start() error {
 		go m.listenGlobalCursorRelease()
 		go m.listenGlobalCursorMove()
 		go m.listenGlobalAxisChanged()
+		if m.foreground != nil {
+			go m.listenActiveWindowWayland()
+		}
 	} else {
+		m.listenActiveWindowX()
 		go m.handleXEvent()
 	}
 
@@ -83,5 +89,8 @@ func (d *Daemon) Start() error {
 }
 
 func (d *Daemon) Stop() error {
+	if d.manager != nil && d.manager.foreground != nil {
+		d.manager.foreground.destroy()
+	}
 	return nil
 }
@@ -0,0 +1,219 @@
+// SPDX-FileCopyrightText: 2026 UnionTech Software Technology Co., Ltd.
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+
+package x_event_monitor
+
+import (
+	"fmt"
+	"sync"
+
+	dbus "github.com/godbus/dbus/v5"
+	kwayland "github.com/linuxdeepin/go-dbus-factory/session/org.deepin.dde.kwayland1"
+	ofdbus "github.com/linuxdeepin/go-dbus-factory/system/org.freedesktop.dbus"
+	"github.com/linuxdeepin/go-lib/dbusutil"
+	x "github.com/linuxdeepin/go-x11-client"
+	"github.com/linuxdeepin/go-x11-client/util/wm/ewmh"
+)
+
+const (
+	schedulerDBusServiceName = "org.deepin.dde.Scheduler1"
+	schedulerDBusPath        = "/org/deepin/dde/Scheduler1"
+	schedulerDBusInterface   = schedulerDBusServiceName
+)
+
+// foregroundReporter 在焦点窗口变化时将前台应用的 pid 上报给系统的 scheduler，
+// 由 scheduler 提升前台应用的优先级。
+type foregroundReporter struct {
+	sysBus  *dbus.Conn
+	sysLoop *dbusutil.SignalLoop
+	dbusObj ofdbus.DBus
+
+	// 待上报的 pid，只保留最新的一个，由 loop 依次上报
+	pending chan uint32
+	quit    chan struct{}
+
+	mu         sync.Mutex
+	currentPid uint32 // 当前前台应用
+	lastPid    uint32 // 最后一次上报成功的前台应用
+	stopped    bool
+}
+
+func newForegroundReporter() (*foregroundReporter, error) {
+	sysBus, err := dbus.SystemBus()
+	if err != nil {
+		return nil, err
+	}
+	r := &foregroundReporter{
+		sysBus:  sysBus,
+		sysLoop: dbusutil.NewSignalLoop(sysBus, 10),
+		dbusObj: ofdbus.NewDBus(sysBus),
+		pending: make(chan uint32, 1),
+		quit:    make(chan struct{}),
+	}
+	go r.loop()
+	r.sysLoop.Start()
+	r.dbusObj.InitSignalExt(r.sysLoop, true)
+	// scheduler 重启后之前的提升已失效，需要重新上报当前的前台应用
+	_, err = r.dbusObj.ConnectNameOwnerChanged(func(name string, oldOwner string, newOwner string) {
+		if name != schedulerDBusServiceName || newOwner == "" {
+			return
+		}
+		r.mu.Lock()
+		r.lastPid = 0
+		pid := r.currentPid
+		r.mu.Unlock()
+		r.report(pid)
+	})
+	if err != nil {
+		logger.Warning(err)
+	}
+	return r, nil
+}
+
+// report 将 pid 放入待上报队列，覆盖还未上报的 pid，不会阻塞
+func (r *foregroundReporter) report(pid uint32) {
+	if pid == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stopped {
+		return
+	}
+	select {
+	case <-r.pending:
+	default:
+	}
+	r.pending <- pid
+}
+
+// loop 按顺序上报前台应用，保证 scheduler 收到的最后一次上报是最新的前台应用
+func (r *foregroundReporter) loop() {
+	for {
+		select {
+		case pid := <-r.pending:
+			r.setForeground(pid)
+		case <-r.quit:
+			return
+		}
+	}
+}
+
+func (r *foregroundReporter) setForeground(pid uint32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stopped || pid == 0 {
+		return
+	}
+	r.currentPid = pid
+	if pid == r.lastPid {
+		return
+	}
+
+	obj := r.sysBus.Object(schedulerDBusServiceName, schedulerDBusPath)
+	err := obj.Call(schedulerDBusInterface+".SetForegroundProcess", 0, pid).Err
+	if err != nil {
+		// scheduler 未启用前台提升时也会返回错误
+		logger.Debug("set foreground process failed:", err)
+		return
+	}
+	r.lastPid = pid
+}
+
+// destroy 停止上报，并恢复被提升过优先级的进程
+func (r *foregroundReporter) destroy() {
+	r.dbusObj.RemoveAllHandlers()
+	r.sysLoop.Stop()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stopped {
+		return
+	}
+	r.stopped = true
+	close(r.quit)
+	if r.lastPid == 0 {
+		return
+	}
+	r.lastPid = 0
+	obj := r.sysBus.Object(schedulerDBusServiceName, schedulerDBusPath)
+	err := obj.Call(schedulerDBusInterface+".ClearForegroundProcess", 0).Err
+	if err != nil {
+		logger.Debug("clear foreground process failed:", err)
+	}
+}
+
+// listenActiveWindowX 监听根窗口的 _NET_ACTIVE_WINDOW 属性变化，事件在 handleXEvent 中处理
+func (m *Manager) listenActiveWindowX() {
+	atom, err := m.xConn.GetAtom("_NET_ACTIVE_WINDOW")
+	if err != nil {
+		logger.Warning(err)
+		return
+	}
+	m.activeWindowAtom = atom
+
+	rootWin := m.xConn.GetDefaultScreen().Root
+	err = x.ChangeWindowAttributesChecked(m.xConn, rootWin, x.CWEventMask,
+		[]uint32{x.EventMaskPropertyChange}).Check(m.xConn)
+	if err != nil {
+		logger.Warning(err)
+		return
+	}
+	m.handleActiveWindowChangedX()
+}
+
+func (m *Manager) handlePropertyNotifyEvent(ev *x.PropertyNotifyEvent) {
+	if ev.Window == m.xConn.GetDefaultScreen().Root && ev.Atom == m.activeWindowAtom {
+		m.handleActiveWindowChangedX()
+	}
+}
+
+func (m *Manager) handleActiveWindowChangedX() {
+	if m.foreground == nil {
+		return
+	}
+	activeWin, err := ewmh.GetActiveWindow(m.xConn).Reply(m.xConn)
+	if err != nil || activeWin == 0 {
+		return
+	}
+	pid, err := ewmh.GetWMPid(m.xConn, activeWin).Reply(m.xConn)
+	if err != nil {
+		logger.Debugf("get pid of window %d failed: %v", activeWin, err)
+		return
+	}
+	m.foreground.report(uint32(pid))
+}
+
+// listenActiveWindowWayland 通过 kwayland 监听焦点窗口变化
+func (m *Manager) listenActiveWindowWayland() {
+	sessionBus := m.service.Conn()
+	wm := kwayland.NewWindowManager(sessionBus)
+	wm.InitSignalExt(m.sessionSigLoop, true)
+
+	handle := func() {
+		winId, err := wm.ActiveWindow(0)
+		if err != nil || winId == 0 {
+			return
+		}
+		win, err := kwayland.NewWindow(sessionBus,
+			dbus.ObjectPath(fmt.Sprintf("/org/deepin/dde/KWayland1/PlasmaWindow_%v", winId)))
+		if err != nil {
+			logger.Warning(err)
+			return
+		}
+		pid, err := win.Pid(0)
+		if err != nil {
+			logger.Debugf("get pid of window %d failed: %v", winId, err)
+			return
+		}
+		m.foreground.report(pid)
+	}
+
+	_, err := wm.ConnectActiveWindowChanged(handle)
+	if err != nil {
+		logger.Warning(err)
+		return
+	}
+	handle()
+}
@@ -50,6 +50,8 @@ type Manager struct {
 	keySymbols          *keysyms.KeySymbols
 	service             *dbusutil.Service
 	sessionSigLoop      *dbusutil.SignalLoop
+	foreground          *foregroundReporter
+	activeWindowAtom    x.Atom
 	//nolint
 	signals *struct {
 		CancelAllArea                     struct{}
@@ -112,6 +114,10 @@ func newManager(service *dbusutil.Service) (*Manager, error) {
 	m.sessionSigLoop = dbusutil.NewSignalLoop(sessionBus, 10)
 	m.sessionSigLoop.Start()
 	m.cursorMask = 0
+	m.foreground, err = newForegroundReporter()
+	if err != nil {
+		logger.Warning(err)
+	}
 	return m, nil
 }
 
@@ -346,6 +352,10 @@ func (m *Manager) handleXEvent() {
 			event, _ := x.NewMappingNotifyEvent(ev)
 			m.keySymbols.RefreshKeyboardMapping(event)
 
+		case x.PropertyNotifyEventCode:
+			event, _ := x.NewPropertyNotifyEvent(ev)
+			m.handlePropertyNotifyEvent(event)
+
 		case x.GeGenericEventCode:
 			geEvent, _ := x.NewGeGenericEvent(ev)
 			if geEvent.Extension == inputExtData.MajorOpcode {